@@ -5,9 +5,12 @@ import (
 	"os"
 
 	"github.com/andranikuz/aiwf/cmd/aiwf/generate"
+	"github.com/andranikuz/aiwf/cmd/aiwf/lsp"
+	"github.com/andranikuz/aiwf/cmd/aiwf/schema"
 	"github.com/andranikuz/aiwf/cmd/aiwf/sdk"
 	"github.com/andranikuz/aiwf/cmd/aiwf/serve"
 	"github.com/andranikuz/aiwf/cmd/aiwf/validate"
+	_ "github.com/andranikuz/aiwf/providers/all" // встроенные провайдеры для use:
 	"github.com/spf13/cobra"
 )
 
@@ -44,6 +47,8 @@ func newRootCmd() *cobra.Command {
 	cmd.AddCommand(sdk.NewCommand())
 	cmd.AddCommand(serve.NewCommand())
 	cmd.AddCommand(generate.NewCommand())
+	cmd.AddCommand(schema.NewCommand())
+	cmd.AddCommand(lsp.NewCommand())
 
 	return cmd
 }
@@ -4,15 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
 	"github.com/andranikuz/aiwf/internal/metasdk"
-	"github.com/andranikuz/aiwf/providers/anthropic"
-	"github.com/andranikuz/aiwf/providers/grok"
-	"github.com/andranikuz/aiwf/providers/openai"
+	_ "github.com/andranikuz/aiwf/providers/all"
 	"github.com/andranikuz/aiwf/runtime/go/aiwf"
 	"github.com/spf13/cobra"
 )
@@ -60,7 +59,7 @@ Examples:
 	cmd.Flags().StringVar(&opts.TaskFile, "task-file", "", "File containing task description")
 	cmd.Flags().StringVarP(&opts.Output, "output", "o", "generated-config.yaml", "Output YAML file")
 	cmd.Flags().BoolVarP(&opts.Interactive, "interactive", "i", false, "Interactive mode with prompts and confirmations")
-	cmd.Flags().StringVar(&opts.Provider, "provider", "openai", "LLM provider (openai, grok, anthropic)")
+	cmd.Flags().StringVar(&opts.Provider, "provider", "openai", "LLM provider ("+strings.Join(core.ProviderNames(), ", ")+")")
 	cmd.Flags().StringVar(&opts.APIKey, "api-key", "", "API key (or use environment variable)")
 
 	return cmd
@@ -89,36 +88,30 @@ func runGenerate(opts *GenerateOptions) error {
 	return runQuickGeneration(service, taskDesc, opts.Output)
 }
 
-// createProvider создает провайдера на основе конфигурации
+// createProvider создает провайдера через реестр aiwf
 func createProvider(providerName, apiKey string) (aiwf.ModelClient, error) {
-	// Get API key from environment if not provided
-	if apiKey == "" {
-		switch providerName {
-		case "openai":
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		case "grok":
-			apiKey = os.Getenv("GROK_API_KEY")
-		case "anthropic":
-			apiKey = os.Getenv("ANTHROPIC_API_KEY")
-		}
+	info, ok := aiwf.LookupProvider(providerName)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider: %s (supported: %s)", providerName, strings.Join(core.ProviderNames(), ", "))
 	}
 
-	if apiKey == "" {
-		return nil, fmt.Errorf("API key required: set %s_API_KEY environment variable or use --api-key flag",
-			strings.ToUpper(providerName))
+	// --api-key подменяет первую переменную окружения провайдера
+	getenv := os.Getenv
+	if apiKey != "" && len(info.EnvKeys) > 0 {
+		getenv = func(key string) string {
+			if key == info.EnvKeys[0] {
+				return apiKey
+			}
+			return os.Getenv(key)
+		}
 	}
 
-	// Create provider
-	switch providerName {
-	case "openai":
-		return openai.NewClient(openai.ClientConfig{APIKey: apiKey})
-	case "grok":
-		return grok.NewClient(grok.ClientConfig{APIKey: apiKey})
-	case "anthropic":
-		return anthropic.NewClient(anthropic.ClientConfig{APIKey: apiKey})
-	default:
-		return nil, fmt.Errorf("unknown provider: %s (supported: openai, grok, anthropic)", providerName)
+	client, err := info.New(aiwf.ProviderConfig{Getenv: getenv})
+	if errors.Is(err, aiwf.ErrProviderNotConfigured) {
+		return nil, fmt.Errorf("API key required: set %s environment variable or use --api-key flag",
+			strings.Join(info.EnvKeys, ", "))
 	}
+	return client, err
 }
 
 // getTaskDescription получает описание задачи из различных источников
@@ -0,0 +1,31 @@
+package lsp
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCommand возвращает подкоманду `aiwf lsp`.
+func NewCommand() *cobra.Command {
+	var stdio bool
+
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Запускает языковой сервер (LSP) для YAML-спецификаций",
+		Long: `Запускает языковой сервер по протоколу LSP (JSON-RPC через stdin/stdout).
+
+Сервер проверяет спецификации теми же правилами, что и aiwf validate, и поддерживает
+автодополнение ключей, провайдеров, типов и выражений типов, переход к определению
+и поиск ссылок для $Type и depends_on, а также подсказки с TypeDef и JSON Schema.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return NewServer(os.Stdin, os.Stdout).Run()
+		},
+	}
+
+	// Редакторы часто передают --stdio; другой транспорт сервер не поддерживает
+	cmd.Flags().BoolVar(&stdio, "stdio", true, "Обмен сообщениями через stdin/stdout")
+
+	return cmd
+}
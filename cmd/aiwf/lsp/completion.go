@@ -0,0 +1,258 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// completionContext описывает место курсора. Контекст определяется по тексту строк
+// и отступам, а не по дереву YAML: во время набора документ обычно некорректен.
+type completionContext struct {
+	path  []string // ключи родительских отображений от корня
+	key   string   // ключ, значение которого набирается; пустой — набирается сам ключ
+	value bool
+}
+
+// typeSnippets — выражения типов с плейсхолдерами.
+var typeSnippets = []CompletionItem{
+	{Label: "string(…)", InsertText: "string(${1:1..100})", Detail: "string with length range or format (email, url, phone, uuid, date, datetime or from formats:)"},
+	{Label: "string(/…/)", InsertText: "string(/${1:^[a-z]+}/)", Detail: "string matching a regular expression"},
+	{Label: "int(…)", InsertText: "int(${1:0..100})", Detail: "integer with range"},
+	{Label: "number(…)", InsertText: "number(${1:0..1})", Detail: "number with range"},
+	{Label: "enum(…)", InsertText: "enum(${1:a}, ${2:b})", Detail: "one of the listed values"},
+	{Label: "map(…)", InsertText: "map(string, ${1:string})", Detail: "object with arbitrary string keys"},
+	{Label: "oneOf(…)", InsertText: "oneOf(\\$${1:A}, \\$${2:B}${3:, discriminator:kind})", Detail: "one of the object types, optionally tagged by a field"},
+}
+
+var primitiveNames = []string{"string", "int", "number", "bool", "datetime", "date", "uuid", "any"}
+
+func (s *Server) completion(params TextDocumentPositionParams) *CompletionList {
+	list := &CompletionList{Items: []CompletionItem{}}
+	doc, ok := s.docs[uriToPath(params.TextDocument.URI)]
+	if !ok {
+		return list
+	}
+	lines := strings.Split(doc.text, "\n")
+	ctx, ok := completionAt(lines, params.Position)
+	if !ok {
+		return list
+	}
+	if !ctx.value {
+		list.Items = keyItems(ctx.path)
+		return list
+	}
+
+	// Недописанная строка под курсором часто ломает YAML: без неё документ
+	// обычно разбирается, иначе используется последний корректный индекс
+	idx := doc.index
+	rest := append(append([]string(nil), lines[:params.Position.Line]...), lines[params.Position.Line+1:]...)
+	if current := buildIndex(doc.path, strings.Join(rest, "\n")); current != nil {
+		idx = current
+	}
+	list.Items = s.valueItems(idx, ctx)
+	return list
+}
+
+// completionAt определяет контекст по строкам документа и позиции курсора.
+func completionAt(lines []string, pos Position) (completionContext, bool) {
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return completionContext{}, false
+	}
+	line := prefixUTF16(lines[pos.Line], pos.Character)
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return completionContext{}, false
+	}
+
+	indent, content, item := splitLine(line)
+	var ctx completionContext
+	if key, _, ok := strings.Cut(content, ":"); ok {
+		ctx.key, ctx.value = strings.TrimSpace(key), true
+	} else if item {
+		ctx.value = true // элемент списка скаляров: значение ключа-родителя
+	}
+
+	// Родители — ближайшие строки выше с меньшим отступом
+	for i := pos.Line - 1; i >= 0 && indent > 0; i-- {
+		ind, text, isItem := splitLine(lines[i])
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || ind >= indent {
+			continue
+		}
+		key, _, hasKey := strings.Cut(text, ":")
+		if !hasKey {
+			if isItem {
+				continue // соседний элемент списка скаляров
+			}
+			break
+		}
+		ctx.path = append([]string{strings.TrimSpace(key)}, ctx.path...)
+		indent = ind
+	}
+
+	// Элемент списка отображений (imports:) начинается с ключа
+	if item && ctx.key == "" && core.SpecKeys(ctx.path) != nil {
+		ctx.value = false
+		return ctx, true
+	}
+	if item && ctx.key == "" && len(ctx.path) > 0 {
+		ctx.key = ctx.path[len(ctx.path)-1]
+		ctx.path = ctx.path[:len(ctx.path)-1]
+	}
+	return ctx, true
+}
+
+// splitLine возвращает отступ содержимого строки (после «- » у элементов списка),
+// само содержимое и признак элемента списка.
+func splitLine(line string) (indent int, content string, item bool) {
+	content = strings.TrimLeft(line, " ")
+	indent = len(line) - len(content)
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		item = true
+		rest := strings.TrimLeft(content[1:], " ")
+		indent += len(content) - len(rest)
+		content = rest
+	}
+	return indent, content, item
+}
+
+// prefixUTF16 возвращает начало строки до столбца в кодовых единицах UTF-16.
+func prefixUTF16(line string, character int) string {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return line[:i]
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return line
+}
+
+func keyItems(path []string) []CompletionItem {
+	keys := core.SpecKeys(path)
+	items := make([]CompletionItem, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, CompletionItem{
+			Label:      k.Key,
+			Kind:       CompletionKindProperty,
+			Detail:     k.Description,
+			InsertText: k.Key + ": ",
+		})
+	}
+	return items
+}
+
+func (s *Server) valueItems(idx *fileIndex, ctx completionContext) []CompletionItem {
+	section := ""
+	if len(ctx.path) > 0 {
+		section = ctx.path[0]
+	}
+
+	switch {
+	case section == "types":
+		return s.typeItems(idx, true)
+	case section == "assistants" && (ctx.key == "input_type" || ctx.key == "output_type"):
+		return s.typeItems(idx, false)
+	case section == "assistants" && len(ctx.path) == 2 && ctx.key == "use",
+		section == "providers" && ctx.key == "type":
+		return providerItems(nil)
+	case ctx.key == "provider":
+		return providerItems(idx.section("providers"))
+	case section == "assistants" && ctx.key == "model":
+		return nameItems(idx.section("models"), CompletionKindValue, "model alias")
+	case section == "assistants" && ctx.key == "depends_on":
+		var names []string
+		for _, name := range idx.assistantNames() {
+			if len(ctx.path) < 2 || name != ctx.path[1] {
+				names = append(names, name)
+			}
+		}
+		return nameItems(names, CompletionKindValue, "assistant")
+	case section == "assistants" && ctx.key == "knowledge":
+		return nameItems(idx.section("knowledge"), CompletionKindValue, "knowledge source")
+	case section == "knowledge" && ctx.key == "embedding":
+		return nameItems(idx.assistantNames(), CompletionKindValue, "assistant")
+	case section == "assistants" && len(ctx.path) == 3 && ctx.path[2] == "thread" && ctx.key == "use":
+		return nameItems(idx.section("threads"), CompletionKindValue, "thread policy")
+	}
+
+	for _, k := range core.SpecKeys(ctx.path) {
+		if k.Key == ctx.key && len(k.Values) > 0 {
+			return nameItems(k.Values, CompletionKindEnum, k.Description)
+		}
+	}
+	return []CompletionItem{}
+}
+
+// typeItems предлагает типы файла, типы импортированных модулей, примитивы и
+// выражения; в разделе types: ссылки пишутся с $.
+func (s *Server) typeItems(idx *fileIndex, dollar bool) []CompletionItem {
+	prefix := ""
+	if dollar {
+		prefix = "$"
+	}
+	var items []CompletionItem
+	for _, name := range idx.typeNames() {
+		items = append(items, CompletionItem{Label: prefix + name, Kind: CompletionKindClass, Detail: "type"})
+	}
+	if idx != nil {
+		for _, alias := range idx.importKeys {
+			module := s.fileIndex(idx.imports[alias])
+			for _, name := range module.typeNames() {
+				items = append(items, CompletionItem{
+					Label:  "$" + alias + "." + name,
+					Kind:   CompletionKindClass,
+					Detail: "type from " + alias,
+				})
+			}
+		}
+	}
+	for _, name := range primitiveNames {
+		items = append(items, CompletionItem{Label: name, Kind: CompletionKindValue, Detail: "primitive"})
+	}
+	for _, snippet := range typeSnippets {
+		snippet.Kind = CompletionKindSnippet
+		snippet.InsertTextFormat = insertTextFormatSnippet
+		items = append(items, snippet)
+	}
+	return items
+}
+
+// providerItems предлагает записи providers: и зарегистрированные провайдеры.
+func providerItems(declared []string) []CompletionItem {
+	items := nameItems(declared, CompletionKindValue, "providers: entry")
+	registered := core.ProviderNames()
+	sort.Strings(registered)
+	return append(items, nameItems(registered, CompletionKindModule, "registered provider")...)
+}
+
+func nameItems(names []string, kind int, detail string) []CompletionItem {
+	items := make([]CompletionItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, CompletionItem{Label: name, Kind: kind, Detail: detail})
+	}
+	return items
+}
+
+func (idx *fileIndex) typeNames() []string {
+	if idx == nil {
+		return nil
+	}
+	return idx.types
+}
+
+func (idx *fileIndex) assistantNames() []string {
+	if idx == nil {
+		return nil
+	}
+	return idx.assistants
+}
+
+func (idx *fileIndex) section(name string) []string {
+	if idx == nil {
+		return nil
+	}
+	return idx.sections[name]
+}
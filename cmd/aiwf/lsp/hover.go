@@ -0,0 +1,131 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// hover показывает для типа разобранный TypeDef и JSON Schema, для ассистента —
+// модель, входной и выходной типы.
+func (s *Server) hover(params TextDocumentPositionParams) *Hover {
+	occ, ok := s.symbolAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return nil
+	}
+	spec := s.parsedSpec(occ.id.file)
+	if spec == nil {
+		return nil
+	}
+
+	var text string
+	switch occ.id.kind {
+	case symbolType:
+		_ = core.ResolveSpec(spec)
+		registry := spec.Resolved.TypeRegistry
+		if registry == nil || registry.Types[occ.id.name] == nil {
+			return nil
+		}
+		text = typeHover(occ.id.name, registry.Types[occ.id.name], registry)
+	case symbolAssistant:
+		// Спецификация до разрешения: input_type показываем в том виде, как он записан
+		as, ok := spec.Assistants[occ.id.name]
+		if !ok {
+			return nil
+		}
+		text = assistantHover(occ.id.name, as)
+	}
+	if occ.id.file != uriToPath(params.TextDocument.URI) {
+		text += fmt.Sprintf("\n\n_%s_", filepath.Base(occ.id.file))
+	}
+	rng := occ.rng
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: text}, Range: &rng}
+}
+
+// parsedSpec разбирает файл; ошибки разрешения типов не мешают показать то,
+// что удалось разобрать.
+func (s *Server) parsedSpec(path string) *core.Spec {
+	text, ok := s.fileText(path)
+	if !ok {
+		return nil
+	}
+	spec, err := core.ParseSpec(path, []byte(text))
+	if err != nil {
+		return nil
+	}
+	return spec
+}
+
+func typeHover(name string, td *core.TypeDef, registry *core.TypeRegistry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** `%s`", name, core.FormatTypeExpression(td))
+	if td.Description != "" {
+		b.WriteString("\n\n" + td.Description)
+	}
+	if td.Kind == core.KindObject && len(td.Properties) > 0 {
+		b.WriteString("\n")
+		names := make([]string, 0, len(td.Properties))
+		for field := range td.Properties {
+			names = append(names, field)
+		}
+		sort.Strings(names)
+		for _, field := range names {
+			prop := td.Properties[field]
+			if prop.Optional {
+				field += "?"
+			}
+			fmt.Fprintf(&b, "\n- `%s`: `%s`", field, core.FormatTypeExpression(prop))
+			if prop.Description != "" {
+				b.WriteString(" — " + prop.Description)
+			}
+		}
+	}
+
+	schema, err := core.NewSchemaCompiler(registry, core.DialectDraft2020).CompileJSON(td)
+	if err != nil {
+		fmt.Fprintf(&b, "\n\nJSON Schema: %v", err)
+		return b.String()
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, schema, "", "  ") == nil {
+		schema = indented.Bytes()
+	}
+	fmt.Fprintf(&b, "\n\nJSON Schema:\n```json\n%s\n```", schema)
+	return b.String()
+}
+
+func assistantHover(name string, as core.AssistantSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** — assistant", name)
+	if as.Kind != "" {
+		fmt.Fprintf(&b, " (%s)", as.Kind)
+	}
+	b.WriteString("\n")
+	if as.Model != "" {
+		fmt.Fprintf(&b, "\n- model: `%s`", as.Model)
+	}
+	switch {
+	case as.Provider != "":
+		fmt.Fprintf(&b, "\n- provider: `%s`", as.Provider)
+	case as.Use != "":
+		fmt.Fprintf(&b, "\n- use: `%s`", as.Use)
+	}
+	input, output := as.InputType, as.OutputType
+	if output == "" {
+		output = "string"
+	}
+	if input != "" {
+		fmt.Fprintf(&b, "\n- `%s` → `%s`", input, output)
+	} else {
+		fmt.Fprintf(&b, "\n- output: `%s`", output)
+	}
+	if len(as.DependsOn) > 0 {
+		fmt.Fprintf(&b, "\n- depends_on: %s", strings.Join(as.DependsOn, ", "))
+	}
+	return b.String()
+}
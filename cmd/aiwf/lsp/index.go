@@ -0,0 +1,282 @@
+package lsp
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/andranikuz/aiwf/generator/core"
+	"gopkg.in/yaml.v3"
+)
+
+// symbolKind — вид объявления, на которое можно сослаться.
+type symbolKind int
+
+const (
+	symbolType      symbolKind = iota + 1 // types.<Name>
+	symbolAssistant                       // assistants.<Name>
+)
+
+// symbolID однозначно определяет объявление: тип или ассистент в конкретном файле.
+type symbolID struct {
+	kind symbolKind
+	file string // абсолютный путь; пустой — ссылка не разрешена
+	name string
+}
+
+// occurrence — объявление или ссылка в тексте файла.
+type occurrence struct {
+	id    symbolID
+	text  string // как записано в файле: User, $User, $common.Address
+	rng   Range
+	isDef bool
+}
+
+// fileIndex — объявления и ссылки одного YAML-файла спецификации.
+type fileIndex struct {
+	path       string
+	imports    map[string]string // псевдоним → абсолютный путь модуля
+	importKeys []string          // псевдонимы в порядке объявления
+	occs       []occurrence
+	types      []string // имена объявленных типов
+	assistants []string
+	sections   map[string][]string // имена записей разделов providers:, models:, threads:, knowledge:
+}
+
+// buildIndex разбирает текст файла; при синтаксической ошибке YAML возвращает nil.
+func buildIndex(path, text string) *fileIndex {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+		return nil
+	}
+	idx := &fileIndex{
+		path:     path,
+		imports:  make(map[string]string),
+		sections: make(map[string][]string),
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return idx
+	}
+	lines := strings.Split(text, "\n")
+	root := doc.Content[0]
+
+	// Импорты разбираются первыми: от них зависит разрешение $alias.Type
+	if imports := mappingValue(root, "imports"); imports != nil && imports.Kind == yaml.SequenceNode {
+		for _, item := range imports.Content {
+			as, p := mappingValue(item, "as"), mappingValue(item, "path")
+			if as == nil || p == nil || as.Value == "" || p.Value == "" {
+				continue
+			}
+			target := p.Value
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			idx.imports[as.Value] = filepath.Clean(target)
+			idx.importKeys = append(idx.importKeys, as.Value)
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "types":
+			idx.indexTypes(value, lines)
+		case "assistants":
+			idx.indexAssistants(value, lines)
+		case "providers", "models", "threads", "knowledge":
+			for _, name := range mappingKeys(value) {
+				idx.sections[key.Value] = append(idx.sections[key.Value], name.Value)
+			}
+		}
+	}
+	return idx
+}
+
+func (idx *fileIndex) indexTypes(node *yaml.Node, lines []string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		idx.types = append(idx.types, key.Value)
+		idx.occs = append(idx.occs, occurrence{
+			id:    symbolID{kind: symbolType, file: idx.path, name: key.Value},
+			text:  key.Value,
+			rng:   nodeRange(key, lines),
+			isDef: true,
+		})
+		idx.indexTypeBody(node.Content[i+1], lines)
+	}
+}
+
+// indexTypeBody собирает ссылки в теле типа: выражениях, полях объекта и массивах [T].
+func (idx *fileIndex) indexTypeBody(node *yaml.Node, lines []string) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		idx.indexExpression(node, lines)
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			idx.indexTypeBody(node.Content[i], lines)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			idx.indexTypeBody(item, lines)
+		}
+	}
+}
+
+func (idx *fileIndex) indexAssistants(node *yaml.Node, lines []string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, body := node.Content[i], node.Content[i+1]
+		idx.assistants = append(idx.assistants, key.Value)
+		idx.occs = append(idx.occs, occurrence{
+			id:    symbolID{kind: symbolAssistant, file: idx.path, name: key.Value},
+			text:  key.Value,
+			rng:   nodeRange(key, lines),
+			isDef: true,
+		})
+		for _, field := range []string{"input_type", "output_type"} {
+			if value := mappingValue(body, field); value != nil && value.Kind == yaml.ScalarNode {
+				idx.indexExpression(value, lines)
+			}
+		}
+		if deps := mappingValue(body, "depends_on"); deps != nil && deps.Kind == yaml.SequenceNode {
+			for _, item := range deps.Content {
+				if item.Kind != yaml.ScalarNode || item.Value == "" {
+					continue
+				}
+				idx.occs = append(idx.occs, occurrence{
+					id:   symbolID{kind: symbolAssistant, file: idx.path, name: item.Value},
+					text: item.Value,
+					rng:  nodeRange(item, lines),
+				})
+			}
+		}
+	}
+}
+
+// indexExpression добавляет ссылку на тип из скалярного выражения типа.
+func (idx *fileIndex) indexExpression(node *yaml.Node, lines []string) {
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || node.Line < 1 || node.Line > len(lines) {
+		return
+	}
+	start := nodeStart(node, lines)
+	for _, ref := range expressionRefs(node.Value) {
+		from := start.Character + utf16Len(node.Value[:ref.start])
+		idx.occs = append(idx.occs, occurrence{
+			id:   idx.resolveTypeRef(ref.name),
+			text: node.Value[ref.start:ref.end],
+			rng: Range{
+				Start: Position{Line: start.Line, Character: from},
+				End:   Position{Line: start.Line, Character: from + utf16Len(node.Value[ref.start:ref.end])},
+			},
+		})
+	}
+}
+
+// resolveTypeRef сопоставляет имени из выражения объявление: Name — тип этого файла,
+// alias.Name — тип импортированного модуля.
+func (idx *fileIndex) resolveTypeRef(name string) symbolID {
+	if alias, typeName, ok := strings.Cut(name, "."); ok {
+		return symbolID{kind: symbolType, file: idx.imports[alias], name: typeName}
+	}
+	return symbolID{kind: symbolType, file: idx.path, name: name}
+}
+
+// at возвращает объявление или ссылку под курсором.
+func (idx *fileIndex) at(pos Position) (occurrence, bool) {
+	if idx == nil {
+		return occurrence{}, false
+	}
+	for _, occ := range idx.occs {
+		if occ.rng.contains(pos) {
+			return occ, true
+		}
+	}
+	return occurrence{}, false
+}
+
+// definition возвращает место объявления символа в этом файле.
+func (idx *fileIndex) definition(id symbolID) (occurrence, bool) {
+	if idx == nil {
+		return occurrence{}, false
+	}
+	for _, occ := range idx.occs {
+		if occ.isDef && occ.id == id {
+			return occ, true
+		}
+	}
+	return occurrence{}, false
+}
+
+// exprRef — ссылка на тип внутри выражения; start и end — смещения в байтах.
+type exprRef struct {
+	start, end int
+	name       string // без префикса $
+}
+
+// expressionRefs находит ссылки на типы в выражении парсером core; у
+// некорректного выражения возвращаются ссылки до места ошибки.
+func expressionRefs(expr string) []exprRef {
+	found, _ := core.TypeExpressionRefs(expr)
+	refs := make([]exprRef, 0, len(found))
+	for _, ref := range found {
+		refs = append(refs, exprRef{start: ref.Offset, end: ref.Offset + ref.Len, name: ref.Name})
+	}
+	return refs
+}
+
+// mappingValue возвращает значение ключа отображения.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func mappingKeys(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i])
+	}
+	return keys
+}
+
+// nodeStart переводит позицию узла yaml.v3 (с 1, в символах) в позицию LSP начала
+// значения; у строк в кавычках значение начинается после кавычки.
+func nodeStart(node *yaml.Node, lines []string) Position {
+	line := node.Line - 1
+	char := 0
+	if line >= 0 && line < len(lines) {
+		runes := []rune(lines[line])
+		col := min(max(node.Column-1, 0), len(runes))
+		char = len(utf16.Encode(runes[:col]))
+	}
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		char++
+	}
+	return Position{Line: max(line, 0), Character: char}
+}
+
+func nodeRange(node *yaml.Node, lines []string) Range {
+	start := nodeStart(node, lines)
+	return Range{Start: start, End: Position{Line: start.Line, Character: start.Character + utf16Len(node.Value)}}
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,104 @@
+package lsp
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// symbolAt возвращает символ под курсором в открытом документе.
+func (s *Server) symbolAt(uri string, pos Position) (occurrence, bool) {
+	doc, ok := s.docs[uriToPath(uri)]
+	if !ok {
+		return occurrence{}, false
+	}
+	occ, ok := doc.index.at(pos)
+	if !ok || occ.id.file == "" {
+		return occurrence{}, false
+	}
+	return occ, true
+}
+
+// definition переходит от $Type, input_type/output_type или depends_on к объявлению,
+// в том числе в импортированном файле.
+func (s *Server) definition(params TextDocumentPositionParams) []Location {
+	occ, ok := s.symbolAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return nil
+	}
+	def, ok := s.fileIndex(occ.id.file).definition(occ.id)
+	if !ok {
+		return nil
+	}
+	return []Location{{URI: pathToURI(occ.id.file), Range: def.rng}}
+}
+
+// references ищет ссылки на символ во всех известных файлах спецификаций.
+func (s *Server) references(params ReferenceParams) []Location {
+	occ, ok := s.symbolAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return nil
+	}
+
+	var locations []Location
+	for _, idx := range s.searchFiles(occ.id.file) {
+		for _, o := range idx.occs {
+			if o.id != occ.id || (o.isDef && !params.Context.IncludeDeclaration) {
+				continue
+			}
+			locations = append(locations, Location{URI: pathToURI(idx.path), Range: o.rng})
+		}
+	}
+	return locations
+}
+
+// searchFiles возвращает индексы файлов, где могут быть ссылки: открытых документов,
+// их импортов и YAML-файлов рабочей области.
+func (s *Server) searchFiles(extra ...string) []*fileIndex {
+	seen := make(map[string]bool)
+	var indexes []*fileIndex
+	var add func(path string)
+	add = func(path string) {
+		if path == "" || seen[path] {
+			return
+		}
+		seen[path] = true
+		idx := s.fileIndex(path)
+		if idx == nil {
+			return
+		}
+		indexes = append(indexes, idx)
+		for _, alias := range idx.importKeys {
+			add(idx.imports[alias])
+		}
+	}
+
+	for path := range s.docs {
+		add(path)
+	}
+	for _, path := range extra {
+		add(path)
+	}
+	if s.root != "" {
+		filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			name := d.Name()
+			if d.IsDir() {
+				if path != s.root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ext := filepath.Ext(name); ext == ".yaml" || ext == ".yml" {
+				add(path)
+			}
+			return nil
+		})
+	}
+
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].path < indexes[j].path })
+	return indexes
+}
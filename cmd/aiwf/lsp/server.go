@@ -0,0 +1,363 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// document — открытый в редакторе файл спецификации.
+type document struct {
+	uri   string
+	path  string // абсолютный путь
+	text  string
+	index *fileIndex // последний успешно разобранный вариант текста
+}
+
+// Server — языковой сервер спецификаций aiwf. Сообщения обрабатываются
+// последовательно, поэтому состояние не требует блокировок.
+type Server struct {
+	conn      *conn
+	root      string               // каталог рабочей области для поиска ссылок
+	docs      map[string]*document // абсолютный путь → документ
+	published map[string][]string  // путь спецификации → файлы, в которые отправлена её диагностика
+	shutdown  bool
+}
+
+// NewServer создаёт сервер, читающий запросы из r и пишущий ответы в w.
+func NewServer(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		conn:      newConn(r, w),
+		docs:      make(map[string]*document),
+		published: make(map[string][]string),
+	}
+}
+
+// Run обрабатывает сообщения до уведомления exit или закрытия входного потока.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rerr *rpcError
+		if errors.As(err, &rerr) {
+			if err := s.conn.reply(nil, nil, rerr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			continue // уведомление: ответ не нужен
+		}
+		if err := s.conn.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		var params struct {
+			RootURI string `json:"rootUri"`
+		}
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		if params.RootURI != "" {
+			s.root = uriToPath(params.RootURI)
+		}
+		return s.initialize(), nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "textDocument/didSave", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.close(params.TextDocument.URI)
+		return nil, nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params), nil
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.references(params), nil
+	}
+
+	if msg.ID == nil {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}
+}
+
+func unmarshalParams(data json.RawMessage, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) initialize() InitializeResult {
+	var result InitializeResult
+	result.Capabilities = ServerCapabilities{
+		TextDocumentSync:   1,
+		CompletionProvider: &CompletionOptions{TriggerCharacters: []string{"$", ".", ":", " "}},
+		HoverProvider:      true,
+		DefinitionProvider: true,
+		ReferencesProvider: true,
+	}
+	result.ServerInfo.Name = "aiwf"
+	result.ServerInfo.Version = core.SpecVersion
+	return result
+}
+
+// update сохраняет новый текст документа и публикует диагностику.
+func (s *Server) update(uri, text string) {
+	path := uriToPath(uri)
+	doc, ok := s.docs[path]
+	if !ok {
+		doc = &document{uri: uri, path: path}
+		s.docs[path] = doc
+	}
+	doc.text = text
+	// При синтаксической ошибке навигация работает по последнему корректному тексту
+	if idx := buildIndex(path, text); idx != nil {
+		doc.index = idx
+	}
+	s.publish(doc)
+}
+
+func (s *Server) close(uri string) {
+	path := uriToPath(uri)
+	delete(s.docs, path)
+	for _, file := range s.published[path] {
+		s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: pathToURI(file), Diagnostics: []Diagnostic{}})
+	}
+	delete(s.published, path)
+}
+
+// publish проверяет спецификацию через core.ParseSpec и core.BuildIR и отправляет
+// диагностику по файлам: ошибки в импортированных модулях попадают в их файлы.
+func (s *Server) publish(doc *document) {
+	byFile := map[string][]Diagnostic{doc.path: {}}
+	for _, d := range s.diagnose(doc) {
+		byFile[d.file] = append(byFile[d.file], d.diag)
+	}
+
+	// Файлы, где диагностика исчезла, получают пустой список
+	for _, file := range s.published[doc.path] {
+		if _, ok := byFile[file]; !ok {
+			byFile[file] = []Diagnostic{}
+		}
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var published []string
+	for _, file := range files {
+		diags := byFile[file]
+		s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: pathToURI(file), Diagnostics: diags})
+		if len(diags) > 0 {
+			published = append(published, file)
+		}
+	}
+	s.published[doc.path] = published
+}
+
+type fileDiagnostic struct {
+	file string
+	diag Diagnostic
+}
+
+func (s *Server) diagnose(doc *document) []fileDiagnostic {
+	spec, err := core.ParseSpec(doc.path, []byte(doc.text))
+	if err == nil {
+		_, err = core.BuildIR(spec)
+	}
+	if err == nil {
+		return nil
+	}
+
+	var errs []*core.ValidationError
+	var warns []*core.ValidationWarning
+	var me *core.MultiError
+	var ve *core.ValidationError
+	switch {
+	case errors.As(err, &me):
+		errs, warns = me.Errors, me.Warnings
+	case errors.As(err, &ve):
+		errs = []*core.ValidationError{ve}
+	default:
+		errs = []*core.ValidationError{{Code: core.CodeUnknown, Msg: err.Error()}}
+	}
+
+	out := make([]fileDiagnostic, 0, len(errs)+len(warns))
+	for _, e := range errs {
+		out = append(out, s.diagnostic(doc, SeverityError, e.Code, e.Field, e.Msg, e.Pos))
+	}
+	for _, w := range warns {
+		out = append(out, s.diagnostic(doc, SeverityWarning, w.Code, w.Field, w.Msg, w.Pos))
+	}
+	return out
+}
+
+func (s *Server) diagnostic(doc *document, severity int, code core.Code, field, msg string, pos core.Position) fileDiagnostic {
+	file := doc.path
+	if pos.File != "" {
+		if abs, err := filepath.Abs(pos.File); err == nil {
+			file = abs
+		}
+	}
+	if field != "" {
+		msg = field + ": " + msg
+	}
+	d := Diagnostic{Severity: severity, Code: string(code), Source: "aiwf", Message: msg}
+	if pos.IsValid() {
+		d.Range = tokenRange(s.fileLines(file), pos.Line-1, pos.Column-1)
+	}
+	return fileDiagnostic{file: file, diag: d}
+}
+
+// tokenRange возвращает диапазон слова, начинающегося в позиции (строка и столбец
+// в символах с 0); при неизвестном столбце — всю строку без отступа.
+func tokenRange(lines []string, line, column int) Range {
+	if line < 0 || line >= len(lines) {
+		return Range{Start: Position{Line: max(line, 0)}, End: Position{Line: max(line, 0)}}
+	}
+	runes := []rune(lines[line])
+	start := column
+	if start < 0 {
+		start = 0
+		for start < len(runes) && unicode.IsSpace(runes[start]) {
+			start++
+		}
+	}
+	start = min(start, len(runes))
+	end := start
+	for end < len(runes) && !unicode.IsSpace(runes[end]) && (column < 0 || runes[end] != ':') {
+		end++
+	}
+	if end == start {
+		end = len(runes)
+	}
+	return Range{
+		Start: Position{Line: line, Character: len(utf16.Encode(runes[:start]))},
+		End:   Position{Line: line, Character: len(utf16.Encode(runes[:end]))},
+	}
+}
+
+// fileText возвращает текст открытого документа или содержимое файла на диске.
+func (s *Server) fileText(path string) (string, bool) {
+	if doc, ok := s.docs[path]; ok {
+		return doc.text, true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (s *Server) fileLines(path string) []string {
+	text, _ := s.fileText(path)
+	return strings.Split(text, "\n")
+}
+
+// fileIndex возвращает индекс файла: открытого документа или прочитанного с диска.
+func (s *Server) fileIndex(path string) *fileIndex {
+	if doc, ok := s.docs[path]; ok {
+		return doc.index
+	}
+	text, ok := s.fileText(path)
+	if !ok {
+		return nil
+	}
+	return buildIndex(path, text)
+}
+
+// uriToPath переводит file:// URI в абсолютный путь.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	// file:///C:/dir → C:/dir
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
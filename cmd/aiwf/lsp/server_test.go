@@ -0,0 +1,380 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const commonYAML = `types:
+  Address:
+    city: string
+    zip?: string
+`
+
+const specYAML = `version: 0.3
+imports:
+  - as: common
+    path: common.yaml
+types:
+  User:
+    name: string
+    address: $common.Address
+  Team:
+    members: $User[]
+assistants:
+  writer:
+    model: gpt-4o
+    input_type: User
+    output_type: Team
+  editor:
+    model: gpt-4o
+    depends_on:
+      - writer
+`
+
+// testClient общается с сервером через каналы в памяти, как редактор через stdio.
+type testClient struct {
+	t        *testing.T
+	conn     *conn
+	messages chan *message
+	nextID   int
+	pending  []*message // уведомления, прочитанные при ожидании ответа
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	server := NewServer(inR, outW)
+	go func() {
+		server.Run()
+		outW.Close()
+	}()
+
+	c := &testClient{t: t, conn: newConn(outR, inW), messages: make(chan *message, 64)}
+	go func() {
+		defer close(c.messages)
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	t.Cleanup(func() {
+		c.notify("exit", nil)
+		inW.Close()
+	})
+
+	var result InitializeResult
+	c.call("initialize", map[string]any{"rootUri": ""}, &result)
+	if !result.Capabilities.DefinitionProvider || result.Capabilities.TextDocumentSync != 1 {
+		t.Fatalf("unexpected capabilities: %+v", result.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+	return c
+}
+
+func (c *testClient) notify(method string, params any) {
+	data, _ := json.Marshal(params)
+	if err := c.conn.write(&message{Method: method, Params: data}); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+}
+
+func (c *testClient) call(method string, params, result any) {
+	c.t.Helper()
+	c.nextID++
+	id := json.RawMessage(strings.TrimSpace(string(mustJSON(c.nextID))))
+	if err := c.conn.write(&message{ID: &id, Method: method, Params: mustJSON(params)}); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+	for {
+		msg := c.next()
+		if msg.ID == nil {
+			c.pending = append(c.pending, msg)
+			continue
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s: %v", method, msg.Error)
+		}
+		data, _ := json.Marshal(msg.Result)
+		if err := json.Unmarshal(data, result); err != nil {
+			c.t.Fatalf("%s: decode result %s: %v", method, data, err)
+		}
+		return
+	}
+}
+
+// diagnostics ждёт публикации диагностики для файла.
+func (c *testClient) diagnostics(uri string) []Diagnostic {
+	c.t.Helper()
+	for {
+		var msg *message
+		if len(c.pending) > 0 {
+			msg, c.pending = c.pending[0], c.pending[1:]
+		} else {
+			msg = c.next()
+		}
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			c.t.Fatal(err)
+		}
+		if params.URI == uri {
+			return params.Diagnostics
+		}
+	}
+}
+
+func (c *testClient) next() *message {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timeout waiting for server message")
+	}
+	return nil
+}
+
+func (c *testClient) open(path, text string) string {
+	uri := pathToURI(path)
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "yaml", Version: 1, Text: text},
+	})
+	return uri
+}
+
+func mustJSON(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func writeWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "common.yaml"), []byte(commonYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "spec.yaml")
+}
+
+func positionOf(t *testing.T, text, substr string) Position {
+	t.Helper()
+	i := strings.Index(text, substr)
+	if i < 0 {
+		t.Fatalf("%q not found", substr)
+	}
+	line := strings.Count(text[:i], "\n")
+	return Position{Line: line, Character: i - strings.LastIndex(text[:i], "\n") - 1}
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	c := newTestClient(t)
+	path := writeWorkspace(t)
+
+	uri := c.open(path, specYAML)
+	if diags := c.diagnostics(uri); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", diags)
+	}
+
+	broken := strings.Replace(specYAML, "$User[]", "$Usr[]", 1)
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: broken}},
+	})
+	diags := c.diagnostics(uri)
+	if len(diags) != 1 {
+		t.Fatalf("expected one diagnostic, got %+v", diags)
+	}
+	d := diags[0]
+	want := positionOf(t, broken, "$Usr[]")
+	if d.Code != "AIWF103" || d.Severity != SeverityError || d.Range.Start != want {
+		t.Fatalf("unexpected diagnostic: %+v (want start %+v)", d, want)
+	}
+	if d.Range.End.Character != want.Character+len("$Usr[]") {
+		t.Fatalf("unexpected range end: %+v", d.Range)
+	}
+}
+
+func TestServerDefinitionAndReferences(t *testing.T) {
+	c := newTestClient(t)
+	path := writeWorkspace(t)
+	uri := c.open(path, specYAML)
+	c.diagnostics(uri)
+
+	// $User[] → types.User
+	var locs []Location
+	c.call("textDocument/definition", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, specYAML, "User[]"),
+	}, &locs)
+	if len(locs) != 1 || locs[0].URI != uri || locs[0].Range.Start != positionOf(t, specYAML, "User:") {
+		t.Fatalf("unexpected definition: %+v", locs)
+	}
+
+	// $common.Address → common.yaml
+	c.call("textDocument/definition", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, specYAML, "common.Address"),
+	}, &locs)
+	commonURI := pathToURI(filepath.Join(filepath.Dir(path), "common.yaml"))
+	if len(locs) != 1 || locs[0].URI != commonURI || locs[0].Range.Start != positionOf(t, commonYAML, "Address") {
+		t.Fatalf("unexpected imported definition: %+v", locs)
+	}
+
+	// depends_on → assistants.writer
+	deps := positionOf(t, specYAML, "- writer")
+	deps.Character += 3
+	c.call("textDocument/definition", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     deps,
+	}, &locs)
+	if len(locs) != 1 || locs[0].Range.Start != positionOf(t, specYAML, "writer:") {
+		t.Fatalf("unexpected depends_on definition: %+v", locs)
+	}
+
+	// Ссылки на User: input_type и $User[], с объявлением — ещё types.User
+	params := ReferenceParams{TextDocumentPositionParams: TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, specYAML, "User:"),
+	}}
+	c.call("textDocument/references", params, &locs)
+	if len(locs) != 2 {
+		t.Fatalf("expected 2 references, got %+v", locs)
+	}
+	params.Context.IncludeDeclaration = true
+	c.call("textDocument/references", params, &locs)
+	if len(locs) != 3 {
+		t.Fatalf("expected 3 references with declaration, got %+v", locs)
+	}
+}
+
+func TestServerHover(t *testing.T) {
+	c := newTestClient(t)
+	path := writeWorkspace(t)
+	uri := c.open(path, specYAML)
+	c.diagnostics(uri)
+
+	var hover Hover
+	c.call("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, specYAML, "User\n    output_type"),
+	}, &hover)
+	for _, want := range []string{"**User** `object`", "- `address`: `$CommonAddress`", "JSON Schema", `"required"`} {
+		if !strings.Contains(hover.Contents.Value, want) {
+			t.Fatalf("hover does not contain %q:\n%s", want, hover.Contents.Value)
+		}
+	}
+
+	c.call("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, specYAML, "common.Address"),
+	}, &hover)
+	if !strings.Contains(hover.Contents.Value, "- `zip?`: `string`") || !strings.Contains(hover.Contents.Value, "common.yaml") {
+		t.Fatalf("unexpected imported type hover:\n%s", hover.Contents.Value)
+	}
+}
+
+func TestServerCompletion(t *testing.T) {
+	c := newTestClient(t)
+	path := writeWorkspace(t)
+	text := specYAML + "  reviewer:\n    mod\n"
+	uri := c.open(path, text)
+	c.diagnostics(uri) // «mod» — синтаксическая ошибка YAML, как при наборе
+
+	complete := func(line, character int) map[string]CompletionItem {
+		t.Helper()
+		var list CompletionList
+		c.call("textDocument/completion", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: line, Character: character},
+		}, &list)
+		items := make(map[string]CompletionItem, len(list.Items))
+		for _, item := range list.Items {
+			items[item.Label] = item
+		}
+		return items
+	}
+	keyLine := positionOf(t, text, "    mod\n").Line
+
+	keys := complete(keyLine, 7)
+	if _, ok := keys["model"]; !ok {
+		t.Fatalf("expected assistant keys, got %v", keys)
+	}
+	if _, ok := keys["input_type"]; !ok {
+		t.Fatalf("expected assistant keys, got %v", keys)
+	}
+
+	text = specYAML + "  reviewer:\n    input_type: \n    depends_on:\n      - \n"
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+	})
+	c.diagnostics(uri)
+	keyLine = positionOf(t, text, "    input_type: \n").Line - 1
+
+	types := complete(keyLine+1, len("    input_type: "))
+	for _, label := range []string{"User", "Team", "$common.Address", "string", "enum(…)"} {
+		if _, ok := types[label]; !ok {
+			t.Fatalf("expected %q in type completion, got %v", label, types)
+		}
+	}
+	if types["enum(…)"].InsertTextFormat != insertTextFormatSnippet {
+		t.Fatalf("enum completion is not a snippet: %+v", types["enum(…)"])
+	}
+
+	assistants := complete(keyLine+3, len("      - "))
+	if _, ok := assistants["writer"]; !ok || len(assistants) != 2 {
+		t.Fatalf("expected writer and editor, got %v", assistants)
+	}
+
+	fields := complete(positionOf(t, text, "    name: string").Line, len("    name: "))
+	if _, ok := fields["$User"]; !ok {
+		t.Fatalf("expected $User in field completion, got %v", fields)
+	}
+}
+
+func TestExpressionRefs(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"$User", []string{"User"}},
+		{"User", []string{"User"}},
+		{"$Item[](min:1, max:10)", []string{"Item"}},
+		{"map(string, $Money)", []string{"Money"}},
+		{"$common.Address[]", []string{"common.Address"}},
+		{"string(email)", nil},
+		{"enum(User, Admin)", nil},
+		{"int(0..10)", nil},
+	}
+	for _, tt := range tests {
+		refs := expressionRefs(tt.expr)
+		var got []string
+		for _, ref := range refs {
+			got = append(got, ref.name)
+			if text := strings.TrimPrefix(tt.expr[ref.start:ref.end], "$"); text != ref.name {
+				t.Errorf("%s: offsets point at %q, want %q", tt.expr, text, ref.name)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: refs %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand возвращает подкоманду `aiwf schema`.
+func NewCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Печатает JSON Schema формата YAML-спецификации",
+		Long: fmt.Sprintf(`Печатает JSON Schema формата спецификации версии %s.
+
+Схему можно подключить в редакторе, например для yaml-language-server:
+
+  # yaml-language-server: $schema=%s
+`, core.SpecVersion, core.SchemaURL),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := Generate()
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			return os.WriteFile(output, data, 0o644)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Файл для записи схемы (по умолчанию stdout)")
+
+	return cmd
+}
+
+// Generate возвращает JSON Schema спецификации в отформатированном виде.
+func Generate() ([]byte, error) {
+	data, err := json.MarshalIndent(core.SpecSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,27 @@
+package schema
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// Опубликованная схема должна совпадать с выводом команды: после изменения
+// структур спецификации её нужно перегенерировать через `aiwf schema -o`.
+func TestPublishedSchemaIsUpToDate(t *testing.T) {
+	path := filepath.Join("..", "..", "..", "schema", core.SpecVersion, "aiwf.schema.json")
+	published, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read published schema: %v", err)
+	}
+	data, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(published, data) {
+		t.Fatalf("%s is outdated: run go run ./cmd/aiwf schema -o %s", path, filepath.ToSlash(filepath.Join("schema", core.SpecVersion, "aiwf.schema.json")))
+	}
+}
@@ -11,11 +11,16 @@ import (
 // NewCommand возвращает подкоманду `aiwf validate`.
 func NewCommand() *cobra.Command {
 	var inputPath string
+	var format string
 
 	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "Проверяет YAML-конфигурацию AIWF",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != FormatText {
+				return runReport(cmd, inputPath, format)
+			}
+
             if inputPath == "" {
                 err := errors.New("нужно указать путь к YAML через --file")
                 fmt.Fprintln(cmd.ErrOrStderr(), FormatError(err))
@@ -34,6 +39,9 @@ func NewCommand() *cobra.Command {
                 if me, ok := err.(*core.MultiError); ok && !me.HasErrors() {
                     // Только предупреждения: продолжаем и считаем валидацию успешной.
                 } else {
+					if ok {
+						fmt.Fprintf(cmd.ErrOrStderr(), "\nОшибок: %d, предупреждений: %d\n", len(me.Errors), len(me.Warnings))
+					}
 					return err
 				}
 			}
@@ -44,6 +52,37 @@ func NewCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&inputPath, "file", "f", "", "Путь к YAML-конфигурации (обязательно)")
+	cmd.Flags().StringVar(&format, "format", FormatText, "Формат вывода: text, json или sarif")
 
 	return cmd
 }
+
+// runReport выполняет проверку и печатает машиночитаемый отчёт в stdout.
+// Команда завершается с ошибкой, если найдены ошибки; предупреждения её не прерывают.
+func runReport(cmd *cobra.Command, inputPath, format string) error {
+	if format != FormatJSON && format != FormatSARIF {
+		return fmt.Errorf("unknown format %q (supported: %s, %s, %s)", format, FormatText, FormatJSON, FormatSARIF)
+	}
+
+	var err error
+	if inputPath == "" {
+		err = errors.New("нужно указать путь к YAML через --file")
+	} else {
+		var spec *core.Spec
+		if spec, err = core.LoadSpec(inputPath); err == nil {
+			_, err = core.BuildIR(spec)
+		}
+	}
+
+	diags := Diagnostics(err)
+	if werr := WriteReport(cmd.OutOrStdout(), format, diags); werr != nil {
+		return werr
+	}
+	for _, d := range diags {
+		if d.Severity == "error" {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("validation failed")
+		}
+	}
+	return nil
+}
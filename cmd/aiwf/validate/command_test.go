@@ -5,33 +5,20 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
-
-	"github.com/andranikuz/aiwf/generator/core"
 )
 
 func TestValidateSuccess(t *testing.T) {
 	dir := t.TempDir()
-	registry := filepath.Join(dir, "registry")
-	if err := os.MkdirAll(registry, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
-	mustWrite(t, filepath.Join(registry, "output.json"), "{}")
-
 	yamlPath := filepath.Join(dir, "spec.yaml")
 	mustWrite(t, yamlPath, `
-version: 0.2
-schema_registry:
-  root: registry
+version: 0.3
+types:
+  Draft:
+    title: string
 assistants:
   writer:
     model: gpt-4
-    output_schema_ref: output.json
-workflows:
-  draft:
-    description: test
-    dag:
-      - step: run
-        assistant: writer
+    output_type: Draft
 `)
 
 	cmd := NewCommand()
@@ -63,43 +50,29 @@ func TestValidateMissingFile(t *testing.T) {
 }
 
 func TestValidateAggregatesErrors(t *testing.T) {
-	dir := t.TempDir()
-	registry := filepath.Join(dir, "registry")
-	if err := os.MkdirAll(registry, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
-	mustWrite(t, filepath.Join(registry, "schema.json"), "{}")
-
-	yamlPath := filepath.Join(dir, "spec.yaml")
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
 	mustWrite(t, yamlPath, `
-version: 0.2
-schema_registry:
-  root: registry
+version: 0.3
+providers:
+  eu:
+    type: openai
+    timeout: soon
+types:
+  Order:
+    customer: $Customer
 assistants:
   writer:
-    model: gpt-4
-    output_schema_ref: schema.json
-workflows:
-  novel:
-    description: test
-    dag:
-      - step: draft
-        assistant: writer
-      - step: draft
-        assistant: writer
-      - step: review
-        assistant: writer
-        scatter:
-          as: reviewer
-          concurrency: -1
+    use: openai
+    model: gpt-4o
+    output_type: Order
+    temperature: 3
+  critic:
+    provider: nowhere
+    model: gpt-4o
+    dialog:
+      max_rounds: 3
 `)
 
-	spec, err := core.LoadSpec(yamlPath)
-	if err != nil {
-		t.Fatalf("LoadSpec: %v", err)
-	}
-	_, _ = core.BuildIR(spec)
-
 	cmd := NewCommand()
 	out := newBuffer()
 	errBuf := newBuffer()
@@ -112,40 +85,31 @@ workflows:
 	}
 
 	lines := strings.Split(strings.TrimSpace(errBuf.String()), "\n")
-	if len(lines) < 2 {
-		t.Fatalf("expected multiple errors, got %s", errBuf.String())
-	}
-	if !containsSubstring(lines, "dag[1].step") {
-		t.Fatalf("missing duplicate step error: %v", lines)
-	}
-	if !containsSubstring(lines, "dag[2].scatter.from") {
-		t.Fatalf("missing scatter from error: %v", lines)
+	for _, field := range []string{
+		"providers.eu.timeout",
+		"types.Order.customer",
+		"assistants.writer.temperature",
+		"assistants.critic.provider",
+		"assistants.critic.dialog",
+	} {
+		if !containsSubstring(lines, field) {
+			t.Errorf("missing error for %s: %v", field, lines)
+		}
 	}
-	if !containsSubstring(lines, "dag[2].scatter.concurrency") {
-		t.Fatalf("missing scatter concurrency error: %v", lines)
+	if !containsSubstring(lines, "Ошибок: 5") {
+		t.Fatalf("expected all errors to be reported at once: %v", lines)
 	}
 }
 
 func TestValidateWarnings(t *testing.T) {
-	dir := t.TempDir()
-	registry := filepath.Join(dir, "registry")
-	if err := os.MkdirAll(registry, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
-	}
-	mustWrite(t, filepath.Join(registry, "schema.json"), "{}")
-
-	yamlPath := filepath.Join(dir, "spec.yaml")
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
 	mustWrite(t, yamlPath, `
-version: 0.2
-schema_registry:
-  root: registry
+version: 0.3
 assistants:
   writer:
-    model: gpt-4
-    output_schema_ref: schema.json
-workflows:
-  empty:
-    description: empty workflow
+    use: anthropic
+    model: claude-sonnet-4
+    seed: 1
 `)
 
 	cmd := NewCommand()
@@ -159,7 +123,7 @@ workflows:
 		t.Fatalf("expected success with warnings, got %v", err)
 	}
 
-	if !strings.Contains(errBuf.String(), "⚠ workflows.empty") {
+	if !strings.Contains(errBuf.String(), "⚠ "+yamlPath+":7:11: assistants.writer.seed") {
 		t.Fatalf("expected warning output, got %s", errBuf.String())
 	}
 	if !strings.Contains(outBuf.String(), "✓ YAML валиден") {
@@ -167,6 +131,63 @@ workflows:
 	}
 }
 
+func TestValidateRejectsUnknownKeys(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
+	mustWrite(t, yamlPath, `assistants:
+  writer:
+    model: gpt-4
+    ouput_type: string
+`)
+
+	cmd := NewCommand()
+	errBuf := newBuffer()
+	cmd.SetOut(newBuffer())
+	cmd.SetErr(errBuf)
+	cmd.SetArgs([]string{"--file", yamlPath})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	want := `assistants.writer.ouput_type — unknown key "ouput_type" (did you mean "output_type"?)`
+	if !strings.Contains(errBuf.String(), want) {
+		t.Fatalf("output misses %q:\n%s", want, errBuf.String())
+	}
+}
+
+func TestValidatePrintsSourceSnippet(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
+	mustWrite(t, yamlPath, `types:
+  Order:
+    customer: $Customer
+    total: $Money
+`)
+
+	cmd := NewCommand()
+	errBuf := newBuffer()
+	cmd.SetOut(newBuffer())
+	cmd.SetErr(errBuf)
+	cmd.SetArgs([]string{"--file", yamlPath})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	out := errBuf.String()
+	for _, want := range []string{
+		yamlPath + ":3:15: types.Order.customer — reference to undefined type: $Customer",
+		"  3 |     customer: $Customer\n    |               ^",
+		yamlPath + ":4:12: types.Order.total",
+		"Ошибок: 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output misses %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, ":3:15:") > strings.Index(out, ":4:12:") {
+		t.Fatalf("diagnostics must be ordered by position:\n%s", out)
+	}
+}
+
 func mustWrite(t *testing.T, path, contents string) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
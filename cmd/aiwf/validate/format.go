@@ -3,28 +3,46 @@ package validate
 import (
 	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
 )
 
-// formatError преобразует ошибку загрузки/IR в человекочитаемый вид.
+// FormatError преобразует ошибку загрузки/IR в человекочитаемый вид.
+// Ошибки с позицией выводятся в стиле компилятора: файл:строка:столбец,
+// строка исходника и каретка под местом ошибки.
 func FormatError(err error) string {
-    var ve *core.ValidationError
-    if errors.As(err, &ve) {
-        return fmt.Sprintf("✗ %s — %s", ve.Field, ve.Msg)
-    }
+	f := &formatter{files: make(map[string][]string)}
+	return f.format(err)
+}
+
+type formatter struct {
+	files map[string][]string // строки прочитанных файлов
+}
 
+func (f *formatter) format(err error) string {
 	var me *core.MultiError
 	if errors.As(err, &me) {
-        lines := make([]string, 0, len(me.Errors)+len(me.Warnings))
-        for _, item := range me.Errors {
-            lines = append(lines, FormatError(item))
-        }
-        for _, warn := range me.Warnings {
-            lines = append(lines, formatWarning(warn))
-        }
-        return strings.Join(lines, "\n")
+		errs := append([]*core.ValidationError(nil), me.Errors...)
+		sort.SliceStable(errs, func(i, j int) bool { return positionLess(errs[i].Pos, errs[j].Pos) })
+		warns := append([]*core.ValidationWarning(nil), me.Warnings...)
+		sort.SliceStable(warns, func(i, j int) bool { return positionLess(warns[i].Pos, warns[j].Pos) })
+
+		lines := make([]string, 0, len(errs)+len(warns))
+		for _, item := range errs {
+			lines = append(lines, f.diagnostic("✗", item.Code, item.Pos, item.Field, item.Msg))
+		}
+		for _, warn := range warns {
+			lines = append(lines, f.diagnostic("⚠", warn.Code, warn.Pos, warn.Field, warn.Msg))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	var ve *core.ValidationError
+	if errors.As(err, &ve) {
+		return f.diagnostic("✗", ve.Code, ve.Pos, ve.Field, ve.Msg)
 	}
 
 	if strings.Contains(err.Error(), "schema") {
@@ -33,6 +51,59 @@ func FormatError(err error) string {
 	return fmt.Sprintf("✗ %s", err.Error())
 }
 
-func formatWarning(w *core.ValidationWarning) string {
-	return fmt.Sprintf("⚠ %s — %s", w.Field, w.Msg)
+func (f *formatter) diagnostic(mark string, code core.Code, pos core.Position, field, msg string) string {
+	var b strings.Builder
+	b.WriteString(mark + " ")
+	if pos.IsValid() {
+		b.WriteString(pos.String() + ": ")
+	}
+	if field != "" {
+		b.WriteString(field + " — ")
+	}
+	b.WriteString(msg)
+	if code != "" && code != core.CodeUnknown {
+		b.WriteString(" [" + string(code) + "]")
+	}
+	b.WriteString(f.snippet(pos))
+	return b.String()
+}
+
+// snippet возвращает строку исходника с кареткой под столбцом позиции.
+func (f *formatter) snippet(pos core.Position) string {
+	if !pos.IsValid() || pos.File == "" {
+		return ""
+	}
+	lines, ok := f.files[pos.File]
+	if !ok {
+		data, err := os.ReadFile(pos.File)
+		if err == nil {
+			lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+		f.files[pos.File] = lines
+	}
+	if pos.Line > len(lines) {
+		return ""
+	}
+
+	number := fmt.Sprintf("%d", pos.Line)
+	gutter := strings.Repeat(" ", len(number))
+	s := fmt.Sprintf("\n  %s | %s", number, lines[pos.Line-1])
+	if pos.Column > 0 {
+		s += fmt.Sprintf("\n  %s | %s^", gutter, strings.Repeat(" ", pos.Column-1))
+	}
+	return s
+}
+
+// positionLess упорядочивает диагностику по файлу и месту; ошибки без позиции идут последними.
+func positionLess(a, b core.Position) bool {
+	if a.IsValid() != b.IsValid() {
+		return a.IsValid()
+	}
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
 }
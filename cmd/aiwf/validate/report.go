@@ -0,0 +1,243 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// Форматы вывода validate.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Diagnostic — ошибка или предупреждение в машиночитаемом отчёте.
+type Diagnostic struct {
+	Code     core.Code `json:"code"`
+	Severity string    `json:"severity"` // error или warning
+	Message  string    `json:"message"`
+	Field    string    `json:"field,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location — место диагностики в файле.
+type Location struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Report — результат validate в формате JSON.
+type Report struct {
+	Valid       bool         `json:"valid"`
+	Errors      int          `json:"errors"`
+	Warnings    int          `json:"warnings"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostics переводит ошибку LoadSpec/BuildIR в список диагностик, упорядоченный по месту в файле.
+func Diagnostics(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+
+	var errs []*core.ValidationError
+	var warns []*core.ValidationWarning
+	var me *core.MultiError
+	var ve *core.ValidationError
+	switch {
+	case errors.As(err, &me):
+		errs, warns = me.Errors, me.Warnings
+	case errors.As(err, &ve):
+		errs = []*core.ValidationError{ve}
+	default:
+		errs = []*core.ValidationError{{Code: core.CodeUnknown, Msg: err.Error()}}
+	}
+
+	diags := make([]Diagnostic, 0, len(errs)+len(warns))
+	positions := make([]core.Position, 0, cap(diags))
+	for _, e := range errs {
+		diags = append(diags, newDiagnostic(e.Code, "error", e.Field, e.Msg, e.Pos))
+		positions = append(positions, e.Pos)
+	}
+	for _, w := range warns {
+		diags = append(diags, newDiagnostic(w.Code, "warning", w.Field, w.Msg, w.Pos))
+		positions = append(positions, w.Pos)
+	}
+
+	// Сначала ошибки, затем предупреждения; внутри — по месту в файле
+	order := make([]int, len(diags))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if diags[a].Severity != diags[b].Severity {
+			return diags[a].Severity == "error"
+		}
+		return positionLess(positions[a], positions[b])
+	})
+	sorted := make([]Diagnostic, len(diags))
+	for i, idx := range order {
+		sorted[i] = diags[idx]
+	}
+	return sorted
+}
+
+func newDiagnostic(code core.Code, severity, field, msg string, pos core.Position) Diagnostic {
+	if code == "" {
+		code = core.CodeUnknown
+	}
+	d := Diagnostic{Code: code, Severity: severity, Message: msg, Field: field}
+	if pos.File != "" {
+		d.Location = &Location{File: filepath.ToSlash(pos.File), Line: pos.Line, Column: pos.Column}
+	}
+	return d
+}
+
+// WriteReport печатает диагностики в формате JSON или SARIF.
+func WriteReport(w io.Writer, format string, diags []Diagnostic) error {
+	var doc interface{}
+	switch format {
+	case FormatJSON:
+		report := Report{Diagnostics: diags}
+		for _, d := range diags {
+			if d.Severity == "error" {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+		}
+		if report.Diagnostics == nil {
+			report.Diagnostics = []Diagnostic{}
+		}
+		report.Valid = report.Errors == 0
+		doc = report
+	case FormatSARIF:
+		doc = sarifLog(diags)
+	default:
+		return fmt.Errorf("unknown format %q (supported: %s, %s, %s)", format, FormatText, FormatJSON, FormatSARIF)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// SARIF 2.1.0: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifDoc struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func sarifLog(diags []Diagnostic) sarifDoc {
+	rules := make([]sarifRule, 0, len(core.Codes))
+	ruleIndex := make(map[core.Code]int, len(core.Codes))
+	for i, info := range core.Codes {
+		rules = append(rules, sarifRule{
+			ID:               string(info.Code),
+			Name:             info.Name,
+			ShortDescription: sarifMessage{Text: info.Description},
+		})
+		ruleIndex[info.Code] = i
+	}
+
+	results := make([]sarifResult, 0, len(diags))
+	for _, d := range diags {
+		text := d.Message
+		if d.Field != "" {
+			text = d.Field + ": " + text
+		}
+		result := sarifResult{
+			RuleID:    string(d.Code),
+			RuleIndex: ruleIndex[d.Code],
+			Level:     d.Severity,
+			Message:   sarifMessage{Text: text},
+		}
+		if d.Location != nil {
+			loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: d.Location.File}}
+			if d.Location.Line > 0 {
+				loc.Region = &sarifRegion{StartLine: d.Location.Line, StartColumn: d.Location.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: loc}}
+		}
+		results = append(results, result)
+	}
+
+	return sarifDoc{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "aiwf",
+				InformationURI: "https://github.com/andranikuz/aiwf",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
@@ -0,0 +1,105 @@
+package validate
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+const invalidSpec = `types:
+  Order:
+    customer: $Customer
+assistants:
+  writer:
+    model: gpt-4o
+    dialog:
+      max_rounds: 3
+`
+
+func runValidate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewCommand()
+	out := newBuffer()
+	cmd.SetOut(out)
+	cmd.SetErr(newBuffer())
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestValidateJSONReport(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
+	mustWrite(t, yamlPath, invalidSpec)
+
+	out, err := runValidate(t, "--file", yamlPath, "--format", "json")
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if report.Valid || report.Errors != 2 || len(report.Diagnostics) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	first := report.Diagnostics[0]
+	if first.Code != core.CodeUndefinedReference || first.Severity != "error" || first.Field != "types.Order.customer" {
+		t.Fatalf("unexpected diagnostic: %+v", first)
+	}
+	if first.Location == nil || first.Location.Line != 3 || first.Location.Column != 15 {
+		t.Fatalf("unexpected location: %+v", first.Location)
+	}
+	if report.Diagnostics[1].Code != core.CodeDialogWithoutThread {
+		t.Fatalf("unexpected second diagnostic: %+v", report.Diagnostics[1])
+	}
+}
+
+func TestValidateSARIFReport(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
+	mustWrite(t, yamlPath, invalidSpec)
+
+	out, _ := runValidate(t, "--file", yamlPath, "--format", "sarif")
+
+	var log sarifDoc
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, out)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF header: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(core.Codes) || len(run.Results) != 2 {
+		t.Fatalf("unexpected run: %d rules, %d results", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	res := run.Results[0]
+	if res.RuleID != string(core.CodeUndefinedReference) || res.Level != "error" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if rule := run.Tool.Driver.Rules[res.RuleIndex]; rule.ID != res.RuleID {
+		t.Fatalf("ruleIndex points to %s", rule.ID)
+	}
+	region := res.Locations[0].PhysicalLocation.Region
+	if region == nil || region.StartLine != 3 || region.StartColumn != 15 {
+		t.Fatalf("unexpected region: %+v", region)
+	}
+}
+
+func TestValidateJSONReportValid(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "spec.yaml")
+	mustWrite(t, yamlPath, "assistants:\n  writer:\n    model: gpt-4o\n")
+
+	out, err := runValidate(t, "--file", yamlPath, "--format", "json")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !report.Valid || report.Diagnostics == nil {
+		t.Fatalf("unexpected report: %s", out)
+	}
+}
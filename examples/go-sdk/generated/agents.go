@@ -33,7 +33,7 @@ func NewDataAnalystAgent(client aiwf.ModelClient) *DataAnalystAgent {
 				InputTypeName:  "DataAnalysisRequest",
 				OutputTypeName: "DataAnalysisResult",
 				MaxTokens:      2000,
-				Temperature:    0.3,
+				Temperature:    aiwf.Float64(0.3),
 			},
 			Client: client,
 		},
@@ -79,7 +79,7 @@ func NewCreativeWriterAgent(client aiwf.ModelClient) *CreativeWriterAgent {
 				InputTypeName:  "CreativeWritingRequest",
 				OutputTypeName: "string",
 				MaxTokens:      1500,
-				Temperature:    0.9,
+				Temperature:    aiwf.Float64(0.9),
 			},
 			Client: client,
 		},
@@ -122,7 +122,7 @@ func NewCustomerSupportAgent(client aiwf.ModelClient) *CustomerSupportAgent {
 				InputTypeName:  "CustomerQuery",
 				OutputTypeName: "SupportResponse",
 				MaxTokens:      1000,
-				Temperature:    0.5,
+				Temperature:    aiwf.Float64(0.5),
 			},
 			Client: client,
 		},
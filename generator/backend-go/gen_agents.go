@@ -2,6 +2,7 @@ package backendgo
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
@@ -27,10 +28,14 @@ func (g *AgentsGenerator) Generate(packageName string) (string, error) {
 
 	// Проверяем, нужен ли json импорт
 	needsJSON := false
+	needsFmt := false
 	for _, assistant := range g.ir.Assistants {
+		if assistant.IsEmbedding() {
+			continue
+		}
+		needsFmt = true
 		if assistant.OutputTypeName != "string" {
 			needsJSON = true
-			break
 		}
 	}
 
@@ -40,7 +45,9 @@ func (g *AgentsGenerator) Generate(packageName string) (string, error) {
 	if needsJSON {
 		b.WriteString("\t\"encoding/json\"\n")
 	}
-	b.WriteString("\t\"fmt\"\n")
+	if needsFmt {
+		b.WriteString("\t\"fmt\"\n")
+	}
 	b.WriteString("\n")
 	b.WriteString("\t\"github.com/andranikuz/aiwf/runtime/go/aiwf\"\n")
 	b.WriteString(")\n\n")
@@ -48,15 +55,15 @@ func (g *AgentsGenerator) Generate(packageName string) (string, error) {
 	// Генерируем структуру Agents
 	b.WriteString("// Agents contains all generated agents\n")
 	b.WriteString("type Agents struct {\n")
-	for name := range g.ir.Assistants {
+	for _, name := range sortedAssistants(g.ir) {
 		agentTypeName := toPascalCase(name) + "Agent"
 		b.WriteString(fmt.Sprintf("\t%s *%s\n", toPascalCase(name), agentTypeName))
 	}
 	b.WriteString("}\n\n")
 
 	// Генерируем каждого агента
-	for name, assistant := range g.ir.Assistants {
-		agentCode, err := g.generateAgent(name, assistant)
+	for _, name := range sortedAssistants(g.ir) {
+		agentCode, err := g.generateAgent(name, g.ir.Assistants[name])
 		if err != nil {
 			return "", fmt.Errorf("failed to generate agent %s: %w", name, err)
 		}
@@ -69,6 +76,10 @@ func (g *AgentsGenerator) Generate(packageName string) (string, error) {
 
 // generateAgent генерирует код для одного агента
 func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant) (string, error) {
+	if assistant.IsEmbedding() {
+		return g.generateEmbeddingAgent(name, assistant), nil
+	}
+
 	var b strings.Builder
 
 	agentTypeName := toPascalCase(name) + "Agent"
@@ -106,7 +117,15 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 	b.WriteString("\t\tAgentBase: aiwf.AgentBase{\n")
 	b.WriteString("\t\t\tConfig: aiwf.AgentConfig{\n")
 	b.WriteString(fmt.Sprintf("\t\t\t\tName:           \"%s\",\n", name))
-	b.WriteString(fmt.Sprintf("\t\t\t\tModel:          \"%s\",\n", assistant.Model))
+	if assistant.Provider != "" {
+		b.WriteString(fmt.Sprintf("\t\t\t\tProvider:       %q,\n", assistant.Provider))
+	}
+	// Azure OpenAI адресует модели по имени деплоймента
+	model := assistant.Model
+	if assistant.Use == "azure" && assistant.Deployment != "" {
+		model = assistant.Deployment
+	}
+	b.WriteString(fmt.Sprintf("\t\t\t\tModel:          \"%s\",\n", model))
 
 	// Экранируем системный промпт
 	escapedPrompt := strings.ReplaceAll(assistant.SystemPrompt, "`", "` + \"`\" + `")
@@ -123,12 +142,11 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 	}
 	b.WriteString(fmt.Sprintf("\t\t\t\tMaxTokens:      %d,\n", maxTokens))
 
-	// Set Temperature with default fallback
-	temperature := assistant.Temperature
-	if temperature == 0 {
-		temperature = 0.7
+	// Параметры генерации выводятся только если заданы: temperature: 0 остаётся нулём
+	writeGeneration(&b, assistant.Generation)
+	if assistant.PromptCache {
+		b.WriteString("\t\t\t\tPromptCache:    true,\n")
 	}
-	b.WriteString(fmt.Sprintf("\t\t\t\tTemperature:    %.1f,\n", temperature))
 	b.WriteString("\t\t\t},\n")
 	b.WriteString("\t\t\tClient: client,\n")
 	b.WriteString("\t\t},\n")
@@ -143,7 +161,7 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 
 	// Метод Run
 	b.WriteString(fmt.Sprintf("// Run executes the %s agent\n", name))
-	b.WriteString(fmt.Sprintf("func (a *%s) Run(ctx context.Context, input %s) (*%s, *aiwf.Trace, error) {\n",
+	b.WriteString(fmt.Sprintf("func (a *%s) Run(ctx context.Context, input %s, opts ...aiwf.CallOption) (*%s, *aiwf.Trace, error) {\n",
 		agentTypeName, inputTypeName, outputTypeName))
 
 	// Валидация если есть
@@ -156,7 +174,7 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 
 	// Вызов модели
 	b.WriteString("\t// Call model\n")
-	b.WriteString("\tresult, trace, err := a.CallModel(ctx, input, nil)\n")
+	b.WriteString("\tresult, trace, err := a.CallModel(ctx, input, nil, opts...)\n")
 	b.WriteString("\tif err != nil {\n")
 	b.WriteString("\t\treturn nil, trace, err\n")
 	b.WriteString("\t}\n\n")
@@ -180,7 +198,7 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 	// Метод RunWithThread если агент поддерживает треды
 	if assistant.Thread != nil {
 		b.WriteString(fmt.Sprintf("// RunWithThread executes the %s agent with thread state\n", name))
-		b.WriteString(fmt.Sprintf("func (a *%s) RunWithThread(ctx context.Context, input %s, thread *aiwf.ThreadState) (*%s, *aiwf.Trace, error) {\n",
+		b.WriteString(fmt.Sprintf("func (a *%s) RunWithThread(ctx context.Context, input %s, thread *aiwf.ThreadState, opts ...aiwf.CallOption) (*%s, *aiwf.Trace, error) {\n",
 			agentTypeName, inputTypeName, outputTypeName))
 
 		if assistant.InputTypeName != "" {
@@ -189,7 +207,7 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 			b.WriteString("\t}\n\n")
 		}
 
-		b.WriteString("\tresult, trace, err := a.CallModel(ctx, input, thread)\n")
+		b.WriteString("\tresult, trace, err := a.CallModel(ctx, input, thread, opts...)\n")
 		b.WriteString("\tif err != nil {\n")
 		b.WriteString("\t\treturn nil, trace, err\n")
 		b.WriteString("\t}\n\n")
@@ -226,4 +244,76 @@ func (g *AgentsGenerator) generateAgent(name string, assistant core.IRAssistant)
 	}
 
 	return b.String(), nil
-}
\ No newline at end of file
+}
+
+// generateEmbeddingAgent генерирует агента kind: embedding с методом Embed.
+func (g *AgentsGenerator) generateEmbeddingAgent(name string, assistant core.IRAssistant) string {
+	var b strings.Builder
+
+	agentTypeName := toPascalCase(name) + "Agent"
+
+	b.WriteString(fmt.Sprintf("// %s represents the %s embedding agent\n", agentTypeName, name))
+	b.WriteString(fmt.Sprintf("type %s struct {\n", agentTypeName))
+	b.WriteString("\taiwf.AgentBase\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString(fmt.Sprintf("// New%s creates a new %s agent\n", agentTypeName, name))
+	b.WriteString(fmt.Sprintf("func New%s(client aiwf.ModelClient) *%s {\n", agentTypeName, agentTypeName))
+	b.WriteString(fmt.Sprintf("\treturn &%s{\n", agentTypeName))
+	b.WriteString("\t\tAgentBase: aiwf.AgentBase{\n")
+	b.WriteString("\t\t\tConfig: aiwf.AgentConfig{\n")
+	b.WriteString(fmt.Sprintf("\t\t\t\tName:       %q,\n", name))
+	if assistant.Provider != "" {
+		b.WriteString(fmt.Sprintf("\t\t\t\tProvider:   %q,\n", assistant.Provider))
+	}
+	model := assistant.Model
+	if assistant.Use == "azure" && assistant.Deployment != "" {
+		model = assistant.Deployment
+	}
+	b.WriteString(fmt.Sprintf("\t\t\t\tModel:      %q,\n", model))
+	if assistant.Dimensions > 0 {
+		b.WriteString(fmt.Sprintf("\t\t\t\tDimensions: %d,\n", assistant.Dimensions))
+	}
+	b.WriteString("\t\t\t},\n")
+	b.WriteString("\t\t\tClient: client,\n")
+	b.WriteString("\t\t},\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Embed returns embeddings of texts in input order\n")
+	b.WriteString(fmt.Sprintf("func (a *%s) Embed(ctx context.Context, texts []string) ([][]float32, *aiwf.Trace, error) {\n", agentTypeName))
+	b.WriteString("\treturn a.CallEmbedding(ctx, texts)\n")
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// writeGeneration выводит заданные параметры генерации в aiwf.AgentConfig.
+func writeGeneration(b *strings.Builder, g core.Generation) {
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("\t\t\t\t%-15s %s,\n", name+":", value))
+	}
+	floatField := func(name string, v *float64) {
+		if v != nil {
+			field(name, "aiwf.Float64("+strconv.FormatFloat(*v, 'g', -1, 64)+")")
+		}
+	}
+
+	floatField("Temperature", g.Temperature)
+	floatField("TopP", g.TopP)
+	if g.Seed != nil {
+		field("Seed", fmt.Sprintf("aiwf.Int(%d)", *g.Seed))
+	}
+	if len(g.Stop) > 0 {
+		quoted := make([]string, len(g.Stop))
+		for i, stop := range g.Stop {
+			quoted[i] = strconv.Quote(stop)
+		}
+		field("Stop", "[]string{"+strings.Join(quoted, ", ")+"}")
+	}
+	floatField("PresencePenalty", g.PresencePenalty)
+	floatField("FrequencyPenalty", g.FrequencyPenalty)
+	if g.ReasoningEffort != "" {
+		field("ReasoningEffort", strconv.Quote(g.ReasoningEffort))
+	}
+}
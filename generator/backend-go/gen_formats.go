@@ -0,0 +1,214 @@
+package backendgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// formatValidator — Go-хелпер проверки встроенного формата строки
+type formatValidator struct {
+	fn   string // имя функции
+	imp  string // нужный импорт; пусто, если не нужен
+	code string
+}
+
+var formatValidators = map[string]formatValidator{
+	"email": {"isValidEmail", `"net/mail"`, `// isValidEmail reports whether s is a bare email address
+func isValidEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+`},
+	"url": {"isValidURL", `"net/url"`, `// isValidURL reports whether s is an absolute URL with a host
+func isValidURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+`},
+	"phone": {"isValidPhone", "", `// isValidPhone reports whether s has an optional leading +, 7 to 15 digits
+// and only space, dot, dash and parentheses as separators
+func isValidPhone(s string) bool {
+	digits := 0
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '(' || r == ')' || r == '.' || r == '-':
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+`},
+	"uuid": {"isValidUUID", "", `// isValidUUID reports whether s is a UUID in the 8-4-4-4-12 hex form
+func isValidUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			if c != '-' {
+				return false
+			}
+			continue
+		}
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+`},
+	"date": {"isValidDate", `"time"`, `// isValidDate reports whether s is a date in the YYYY-MM-DD form
+func isValidDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+`},
+	"datetime": {"isValidDateTime", `"time"`, `// isValidDateTime reports whether s is an RFC 3339 date and time
+func isValidDateTime(s string) bool {
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+`},
+}
+
+// stringFormat возвращает формат, по которому проверяется строковое значение:
+// uuid проверяется как одноимённый формат
+func stringFormat(td *core.TypeDef) string {
+	if td.Kind == core.KindUUID {
+		return "uuid"
+	}
+	if td.Kind == core.KindString {
+		return td.Format
+	}
+	return ""
+}
+
+// validatedValues вызывает fn для значений, которые проверяют валидаторы: именованных
+// не-объектных типов и полей объектов, в порядке имён
+func (g *TypesGenerator) validatedValues(fn func(name string, td *core.TypeDef)) {
+	if g.ir.Types == nil {
+		return
+	}
+	for _, typeName := range sortedTypeNames(g.ir.Types) {
+		td := g.ir.Types.Types[typeName]
+		if td.Kind != core.KindObject {
+			fn(typeName, td)
+			continue
+		}
+		for _, field := range sortedProperties(td) {
+			fn(typeName+toPascalCase(field), td.Properties[field])
+		}
+	}
+}
+
+// collectPatterns даёт имена переменным с регулярными выражениями шаблонов /regex/
+// и пользовательских форматов: <тип><Поле>Pattern
+func (g *TypesGenerator) collectPatterns() {
+	g.patterns = make(map[*core.TypeDef]string)
+	g.validatedValues(func(name string, td *core.TypeDef) {
+		if g.valuePattern(td) != "" {
+			g.patterns[td] = lowerFirst(name) + "Pattern"
+		}
+	})
+}
+
+// valuePattern возвращает регулярное выражение, которому должна соответствовать строка
+func (g *TypesGenerator) valuePattern(td *core.TypeDef) string {
+	if td.Kind != core.KindString {
+		return ""
+	}
+	if td.Pattern != "" {
+		return td.Pattern
+	}
+	if td.Format == "" || formatValidators[td.Format].fn != "" {
+		return ""
+	}
+	return g.ir.Types.Formats[td.Format]
+}
+
+// usedFormats возвращает встроенные форматы, для которых нужны хелперы
+func (g *TypesGenerator) usedFormats() []string {
+	used := make(map[string]bool)
+	g.validatedValues(func(_ string, td *core.TypeDef) {
+		if format := stringFormat(td); formatValidators[format].fn != "" {
+			used[format] = true
+		}
+	})
+	formats := make([]string, 0, len(used))
+	for format := range used {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// generatePatterns объявляет скомпилированные регулярные выражения
+func (g *TypesGenerator) generatePatterns() string {
+	values := make([]*core.TypeDef, 0, len(g.patterns))
+	for td := range g.patterns {
+		values = append(values, td)
+	}
+	sort.Slice(values, func(i, j int) bool { return g.patterns[values[i]] < g.patterns[values[j]] })
+
+	var b strings.Builder
+	b.WriteString("var (\n")
+	for _, td := range values {
+		b.WriteString(fmt.Sprintf("\t%s = regexp.MustCompile(%s)\n", g.patterns[td], goRawString(g.valuePattern(td))))
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// generateFormatValidation проверяет формат и шаблон строки; пустая строка
+// необязательного поля не проверяется
+func (g *TypesGenerator) generateFormatValidation(label, value string, td *core.TypeDef) []string {
+	var checks []string
+	cond := func(check string) string {
+		if td.Optional {
+			return fmt.Sprintf("%s != \"\" && %s", value, check)
+		}
+		return check
+	}
+
+	format := stringFormat(td)
+	if v, ok := formatValidators[format]; ok {
+		checks = append(checks, fmt.Sprintf(
+			"\tif %s {\n\t\treturn fmt.Errorf(\"%s must be a valid %s\")\n\t}\n",
+			cond(fmt.Sprintf("!%s(string(%s))", v.fn, value)), label, format,
+		))
+	}
+	if pattern, ok := g.patterns[td]; ok {
+		msg := fmt.Sprintf("\"%s must match %%s\", %s", label, pattern)
+		if td.Pattern == "" {
+			msg = fmt.Sprintf("\"%s must be a valid %s\"", label, td.Format)
+		}
+		checks = append(checks, fmt.Sprintf(
+			"\tif %s {\n\t\treturn fmt.Errorf(%s)\n\t}\n",
+			cond(fmt.Sprintf("!%s.MatchString(string(%s))", pattern, value)), msg,
+		))
+	}
+	return checks
+}
+
+// goRawString записывает строку как raw-литерал Go, если в ней нет обратной кавычки
+func goRawString(s string) string {
+	if strings.Contains(s, "`") {
+		return fmt.Sprintf("%q", s)
+	}
+	return "`" + s + "`"
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
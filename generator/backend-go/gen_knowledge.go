@@ -0,0 +1,168 @@
+package backendgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// KnowledgeGenerator генерирует knowledge.go: описание источников знаний
+// и метод Service.LoadKnowledge, подключающий индексы к агентам.
+type KnowledgeGenerator struct {
+	ir *core.IR
+}
+
+// NewKnowledgeGenerator создаёт генератор источников знаний
+func NewKnowledgeGenerator(ir *core.IR) *KnowledgeGenerator {
+	return &KnowledgeGenerator{ir: ir}
+}
+
+// Generate генерирует код источников знаний
+func (g *KnowledgeGenerator) Generate(packageName string) (string, error) {
+	var b strings.Builder
+
+	b.WriteString("// Code generated by aiwf. DO NOT EDIT.\n\n")
+	b.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+
+	names := sortedKnowledge(g.ir)
+	used := usedKnowledge(g.ir)
+
+	// aiwf нужен для подключения источников к агентам и для литерала ChunkOverlap
+	needAIWF := len(used) > 0
+	for _, name := range names {
+		if g.ir.Knowledge[name].ChunkOverlap != nil {
+			needAIWF = true
+		}
+	}
+
+	b.WriteString("import (\n")
+	b.WriteString("\t\"context\"\n")
+	b.WriteString("\n")
+	if needAIWF {
+		b.WriteString("\t\"github.com/andranikuz/aiwf/runtime/go/aiwf\"\n")
+	}
+	b.WriteString("\t\"github.com/andranikuz/aiwf/runtime/go/aiwf/knowledge\"\n")
+	b.WriteString(")\n\n")
+
+	// Все источники описаны в одной спецификации, поэтому каталог у них общий
+	dir := ""
+	if len(names) > 0 {
+		dir = g.ir.Knowledge[names[0]].Dir
+	}
+	b.WriteString("// KnowledgeDir is the directory relative paths in KnowledgeSources are\n")
+	b.WriteString("// resolved against: the directory of the spec file. Change it before\n")
+	b.WriteString("// LoadKnowledge when the documents are deployed elsewhere.\n")
+	b.WriteString(fmt.Sprintf("var KnowledgeDir = %q\n\n", filepath.ToSlash(dir)))
+	b.WriteString("// KnowledgeSources describes the knowledge sources of the spec.\n")
+	b.WriteString("// Relative paths are resolved against KnowledgeDir.\n")
+	b.WriteString("var KnowledgeSources = map[string]knowledge.Config{\n")
+	for _, name := range names {
+		k := g.ir.Knowledge[name]
+		b.WriteString(fmt.Sprintf("\t%q: {\n", name))
+		field := func(key, value string) {
+			b.WriteString(fmt.Sprintf("\t\t%-13s %s,\n", key+":", value))
+		}
+		field("Name", fmt.Sprintf("%q", name))
+		field("Paths", stringSliceLiteral(k.Paths))
+		if len(k.Include) > 0 {
+			field("Include", stringSliceLiteral(k.Include))
+		}
+		if k.ChunkSize > 0 {
+			field("ChunkSize", fmt.Sprint(k.ChunkSize))
+		}
+		if k.ChunkOverlap != nil {
+			field("ChunkOverlap", fmt.Sprintf("aiwf.Int(%d)", *k.ChunkOverlap))
+		}
+		if k.TopK > 0 {
+			field("TopK", fmt.Sprint(k.TopK))
+		}
+		b.WriteString("\t},\n")
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// LoadKnowledge builds knowledge indexes and attaches them to agents.\n")
+	b.WriteString("// Indexes are cached in the artifact store when it is set.\n")
+	b.WriteString("func (s *Service) LoadKnowledge(ctx context.Context) error {\n")
+	// Источники, не подключённые ни к одному агенту, не индексируются
+	for _, name := range names {
+		if !used[name] {
+			continue
+		}
+		k := g.ir.Knowledge[name]
+		varName := unexport(toPascalCase(name)) + "Index"
+		b.WriteString(fmt.Sprintf("\t%s, err := knowledge.Build(ctx, KnowledgeSources[%q], knowledge.Options{\n", varName, name))
+		b.WriteString("\t\tStore: s.artifactStore,\n")
+		b.WriteString("\t\tDir:   KnowledgeDir,\n")
+		if k.Embedding != "" {
+			embedder := "s.agents." + toPascalCase(k.Embedding)
+			b.WriteString("\t\tEmbed: func(ctx context.Context, texts []string) ([][]float32, error) {\n")
+			b.WriteString(fmt.Sprintf("\t\t\tvectors, _, err := %s.Embed(ctx, texts)\n", embedder))
+			b.WriteString("\t\t\treturn vectors, err\n")
+			b.WriteString("\t\t},\n")
+			b.WriteString(fmt.Sprintf("\t\tEmbeddingModel: %s.Model(),\n", embedder))
+		}
+		b.WriteString("\t})\n")
+		b.WriteString("\tif err != nil {\n")
+		b.WriteString("\t\treturn err\n")
+		b.WriteString("\t}\n")
+	}
+	if len(used) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, name := range sortedAssistants(g.ir) {
+		assistant := g.ir.Assistants[name]
+		if len(assistant.Knowledge) == 0 {
+			continue
+		}
+		var refs []string
+		for _, ref := range assistant.Knowledge {
+			refs = append(refs, unexport(toPascalCase(ref))+"Index")
+		}
+		b.WriteString(fmt.Sprintf("\ts.agents.%s.Knowledge = []aiwf.Retriever{%s}\n", toPascalCase(name), strings.Join(refs, ", ")))
+	}
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+func sortedKnowledge(ir *core.IR) []string {
+	names := make([]string, 0, len(ir.Knowledge))
+	for name := range ir.Knowledge {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// usedKnowledge возвращает источники, на которые ссылаются ассистенты.
+func usedKnowledge(ir *core.IR) map[string]bool {
+	used := make(map[string]bool)
+	for _, assistant := range ir.Assistants {
+		for _, ref := range assistant.Knowledge {
+			used[ref] = true
+		}
+	}
+	return used
+}
+
+func sortedAssistants(ir *core.IR) []string {
+	names := make([]string, 0, len(ir.Assistants))
+	for name := range ir.Assistants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func stringSliceLiteral(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
@@ -2,9 +2,11 @@ package backendgo
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
 )
 
 // ServerGenerator генерирует HTTP сервер для агентов
@@ -29,6 +31,7 @@ func (g *ServerGenerator) Generate(packageName string) (string, error) {
 	b.WriteString("import (\n")
 	b.WriteString("\t\"context\"\n")
 	b.WriteString("\t\"encoding/json\"\n")
+	b.WriteString("\t\"errors\"\n")
 	b.WriteString("\t\"fmt\"\n")
 	b.WriteString("\t\"log\"\n")
 	b.WriteString("\t\"net/http\"\n")
@@ -41,20 +44,17 @@ func (g *ServerGenerator) Generate(packageName string) (string, error) {
 	// Import SDK from aiwf-server module
 	b.WriteString(fmt.Sprintf("\t\"aiwf-server/%s\"\n", packageName))
 
-	// Only import providers that are actually used
-	usedProviders := make(map[string]bool)
-	for _, assistant := range g.ir.Assistants {
-		usedProviders[assistant.Use] = true
-	}
-
-	if usedProviders["openai"] {
-		b.WriteString("\t\"github.com/andranikuz/aiwf/providers/openai\"\n")
+	// Импортируем только используемые провайдеры: их пакеты регистрируются в реестре aiwf
+	providers, err := g.usedProviders()
+	if err != nil {
+		return "", err
 	}
-	if usedProviders["grok"] {
-		b.WriteString("\t\"github.com/andranikuz/aiwf/providers/grok\"\n")
-	}
-	if usedProviders["anthropic"] {
-		b.WriteString("\t\"github.com/andranikuz/aiwf/providers/anthropic\"\n")
+	imported := make(map[string]bool)
+	for _, p := range providers {
+		if !imported[p.Info.ImportPath] {
+			imported[p.Info.ImportPath] = true
+			b.WriteString(fmt.Sprintf("\t_ %q\n", p.Info.ImportPath))
+		}
 	}
 
 	b.WriteString("\t\"github.com/andranikuz/aiwf/runtime/go/aiwf\"\n")
@@ -65,7 +65,7 @@ func (g *ServerGenerator) Generate(packageName string) (string, error) {
 	b.WriteString("\n")
 
 	// Main function
-	b.WriteString(g.generateMainFunction())
+	b.WriteString(g.generateMainFunction(providers))
 	b.WriteString("\n")
 
 	// HTTP handlers
@@ -73,11 +73,120 @@ func (g *ServerGenerator) Generate(packageName string) (string, error) {
 	b.WriteString("\n")
 
 	// Helper functions
-	b.WriteString(g.generateHelpers())
+	b.WriteString(g.generateHelpers(providers))
 
 	return b.String(), nil
 }
 
+// serverProvider — провайдер, который сгенерированный сервер создаёт при старте.
+type serverProvider struct {
+	Name   string            // имя для маршрутизации (запись providers: или use:)
+	Info   aiwf.ProviderInfo // зарегистрированный провайдер
+	Config core.IRProvider   // настройки из providers:; пустые для use: без записи
+}
+
+// usedProviders возвращает провайдеров, на которые ссылаются ассистенты, отсортированных по имени.
+// Провайдеры берутся из реестра aiwf, поэтому должны быть зарегистрированы в генераторе.
+func (g *ServerGenerator) usedProviders() ([]serverProvider, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, assistant := range g.ir.Assistants {
+		name := assistant.Provider
+		if name == "" {
+			name = assistant.Use
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]serverProvider, 0, len(names))
+	for _, name := range names {
+		config, ok := g.ir.Providers[name]
+		if !ok {
+			config = core.IRProvider{Name: name, Type: name}
+		}
+		info, ok := core.LookupProvider(config.Type)
+		if !ok {
+			return nil, fmt.Errorf("server: provider %q is not registered", config.Type)
+		}
+		providers = append(providers, serverProvider{Name: name, Info: info, Config: config})
+	}
+	return providers, nil
+}
+
+// envKeys возвращает переменные окружения с ключами используемых провайдеров.
+func envKeys(providers []serverProvider) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range providers {
+		candidates := p.Info.EnvKeys
+		if p.Config.APIKeyEnv != "" {
+			candidates = []string{p.Config.APIKeyEnv}
+		}
+		for _, key := range candidates {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// providerConfigLiteral возвращает литерал aiwf.ProviderConfig для записи providers:.
+func providerConfigLiteral(cfg core.IRProvider) string {
+	var fields []string
+	if cfg.BaseURL != "" {
+		fields = append(fields, fmt.Sprintf("BaseURL: %q", cfg.BaseURL))
+	}
+	if cfg.APIKeyEnv != "" {
+		fields = append(fields, fmt.Sprintf("APIKeyEnv: %q", cfg.APIKeyEnv))
+	}
+	if cfg.Timeout > 0 {
+		fields = append(fields, fmt.Sprintf("Timeout: %d * time.Millisecond", cfg.Timeout.Milliseconds()))
+	}
+	if len(cfg.Headers) > 0 {
+		fields = append(fields, "Headers: "+stringMapLiteral(cfg.Headers))
+	}
+	if cfg.Org != "" {
+		fields = append(fields, fmt.Sprintf("Org: %q", cfg.Org))
+	}
+	if cfg.Mode != "" {
+		fields = append(fields, fmt.Sprintf("Mode: %q", cfg.Mode))
+	}
+	if cfg.NumCtx > 0 {
+		fields = append(fields, fmt.Sprintf("NumCtx: %d", cfg.NumCtx))
+	}
+	if cfg.KeepAlive != "" {
+		fields = append(fields, fmt.Sprintf("KeepAlive: %q", cfg.KeepAlive))
+	}
+	if cfg.PullMissing {
+		fields = append(fields, "PullMissing: true")
+	}
+	if len(cfg.Deployments) > 0 {
+		fields = append(fields, "Deployments: "+stringMapLiteral(cfg.Deployments))
+	}
+	return "aiwf.ProviderConfig{" + strings.Join(fields, ", ") + "}"
+}
+
+// stringMapLiteral возвращает литерал map[string]string с ключами по алфавиту.
+func stringMapLiteral(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%q: %q", k, m[k]))
+	}
+	return "map[string]string{" + strings.Join(pairs, ", ") + "}"
+}
+
 func (g *ServerGenerator) generateServerConfig() string {
 	var b strings.Builder
 
@@ -108,27 +217,30 @@ func (g *ServerGenerator) generateServerConfig() string {
 	return b.String()
 }
 
-func (g *ServerGenerator) generateMainFunction() string {
+func (g *ServerGenerator) generateMainFunction(providers []serverProvider) string {
 	var b strings.Builder
 
 	b.WriteString("func main() {\n")
 	b.WriteString("\tconfig := getConfig()\n\n")
 
 	b.WriteString("\t// Initialize providers based on agent configuration\n")
-	b.WriteString("\tproviders := initializeProviders()\n\n")
-
-	b.WriteString("\t// Create service\n")
-	b.WriteString("\tvar service *sdk.Service\n")
-	b.WriteString("\tfor _, provider := range providers {\n")
-	b.WriteString("\t\tif provider != nil {\n")
-	b.WriteString("\t\t\tservice = sdk.NewService(provider)\n")
-	b.WriteString("\t\t\tbreak\n")
-	b.WriteString("\t\t}\n")
+	b.WriteString("\trouter := initializeProviders()\n")
+	b.WriteString("\tif router.Len() == 0 {\n")
+	message := "No providers configured"
+	if keys := envKeys(providers); len(keys) > 0 {
+		message += ". Set " + strings.Join(keys, ", ")
+	}
+	b.WriteString(fmt.Sprintf("\t\tlog.Fatal(%q)\n", message))
 	b.WriteString("\t}\n\n")
 
-	b.WriteString("\tif service == nil {\n")
-	b.WriteString("\t\tlog.Fatal(\"No providers configured. Set OPENAI_API_KEY, GROK_API_KEY, or ANTHROPIC_API_KEY\")\n")
-	b.WriteString("\t}\n\n")
+	b.WriteString("\t// Create service; the router dispatches calls by agent provider\n")
+	b.WriteString("\tservice := sdk.NewService(router)\n\n")
+	if len(g.ir.Knowledge) > 0 {
+		b.WriteString("\t// Build knowledge indexes before serving requests\n")
+		b.WriteString("\tif err := service.LoadKnowledge(context.Background()); err != nil {\n")
+		b.WriteString("\t\tlog.Fatalf(\"Failed to load knowledge: %v\", err)\n")
+		b.WriteString("\t}\n\n")
+	}
 
 	b.WriteString("\t// Setup HTTP server\n")
 	b.WriteString("\tmux := http.NewServeMux()\n\n")
@@ -219,6 +331,10 @@ func (g *ServerGenerator) generateHandlers() string {
 	// Generate handlers for each agent
 	for assistantName, assistant := range g.ir.Assistants {
 		pascalName := toPascalCase(assistantName)
+		if assistant.IsEmbedding() {
+			b.WriteString(embeddingHandler(pascalName))
+			continue
+		}
 		inputType := "interface{}"
 
 		if assistant.InputType != nil {
@@ -258,52 +374,71 @@ func (g *ServerGenerator) generateHandlers() string {
 	return b.String()
 }
 
-func (g *ServerGenerator) generateHelpers() string {
+// embeddingHandler генерирует обработчик агента эмбеддингов: {"input": [...]} → {"data": [[...]], "trace": ...}.
+func embeddingHandler(pascalName string) string {
 	var b strings.Builder
 
-	b.WriteString("// ============ HELPERS ============\n\n")
+	b.WriteString(fmt.Sprintf("func handle%s(service *sdk.Service) http.HandlerFunc {\n", pascalName))
+	b.WriteString("\treturn func(w http.ResponseWriter, r *http.Request) {\n")
+	b.WriteString("\t\tif r.Method != http.MethodPost {\n")
+	b.WriteString("\t\t\thttp.Error(w, \"Method not allowed\", http.StatusMethodNotAllowed)\n")
+	b.WriteString("\t\t\treturn\n")
+	b.WriteString("\t\t}\n\n")
 
-	// Initialize providers
-	b.WriteString("func initializeProviders() []aiwf.ModelClient {\n")
-	b.WriteString("\tvar providers []aiwf.ModelClient\n\n")
+	b.WriteString("\t\tvar req struct {\n")
+	b.WriteString("\t\t\tInput []string `json:\"input\"`\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\tif err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Input) == 0 {\n")
+	b.WriteString("\t\t\trespondError(w, \"Invalid request body: expected {\\\"input\\\": [\\\"text\\\", ...]}\", http.StatusBadRequest)\n")
+	b.WriteString("\t\t\treturn\n")
+	b.WriteString("\t\t}\n\n")
+
+	b.WriteString(fmt.Sprintf("\t\tvectors, trace, err := service.Agents().%s.Embed(r.Context(), req.Input)\n", pascalName))
+	b.WriteString("\t\tif err != nil {\n")
+	b.WriteString("\t\t\trespondError(w, fmt.Sprintf(\"Agent error: %v\", err), http.StatusInternalServerError)\n")
+	b.WriteString("\t\t\treturn\n")
+	b.WriteString("\t\t}\n\n")
+
+	b.WriteString("\t\trespondJSON(w, map[string]interface{}{\n")
+	b.WriteString("\t\t\t\"data\":  vectors,\n")
+	b.WriteString("\t\t\t\"trace\": trace,\n")
+	b.WriteString("\t\t})\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n\n")
 
-	// Check which providers are used
-	usedProviders := make(map[string]bool)
-	for _, assistant := range g.ir.Assistants {
-		usedProviders[assistant.Use] = true
-	}
+	return b.String()
+}
 
-	if usedProviders["openai"] {
-		b.WriteString("\t// OpenAI\n")
-		b.WriteString("\tif apiKey := os.Getenv(\"OPENAI_API_KEY\"); apiKey != \"\" {\n")
-		b.WriteString("\t\tif client, err := openai.NewClient(openai.ClientConfig{APIKey: apiKey}); err == nil {\n")
-		b.WriteString("\t\t\tproviders = append(providers, client)\n")
-		b.WriteString("\t\t\tlog.Println(\"✓ OpenAI provider initialized\")\n")
-		b.WriteString("\t\t}\n")
-		b.WriteString("\t}\n\n")
-	}
+func (g *ServerGenerator) generateHelpers(providers []serverProvider) string {
+	var b strings.Builder
 
-	if usedProviders["grok"] {
-		b.WriteString("\t// Grok\n")
-		b.WriteString("\tif apiKey := os.Getenv(\"GROK_API_KEY\"); apiKey != \"\" {\n")
-		b.WriteString("\t\tif client, err := grok.NewClient(grok.ClientConfig{APIKey: apiKey}); err == nil {\n")
-		b.WriteString("\t\t\tproviders = append(providers, client)\n")
-		b.WriteString("\t\t\tlog.Println(\"✓ Grok provider initialized\")\n")
-		b.WriteString("\t\t}\n")
-		b.WriteString("\t}\n\n")
-	}
+	b.WriteString("// ============ HELPERS ============\n\n")
 
-	if usedProviders["anthropic"] {
-		b.WriteString("\t// Anthropic\n")
-		b.WriteString("\tif apiKey := os.Getenv(\"ANTHROPIC_API_KEY\"); apiKey != \"\" {\n")
-		b.WriteString("\t\tif client, err := anthropic.NewClient(anthropic.ClientConfig{APIKey: apiKey}); err == nil {\n")
-		b.WriteString("\t\t\tproviders = append(providers, client)\n")
-		b.WriteString("\t\t\tlog.Println(\"✓ Anthropic provider initialized\")\n")
-		b.WriteString("\t\t}\n")
-		b.WriteString("\t}\n\n")
+	// Initialize providers
+	b.WriteString("func initializeProviders() *aiwf.Router {\n")
+	b.WriteString("\trouter := aiwf.NewRouter()\n")
+	b.WriteString("\tconfigs := []struct {\n")
+	b.WriteString("\t\tname     string\n")
+	b.WriteString("\t\tprovider string\n")
+	b.WriteString("\t\tconfig   aiwf.ProviderConfig\n")
+	b.WriteString("\t}{\n")
+	for _, p := range providers {
+		b.WriteString(fmt.Sprintf("\t\t{name: %q, provider: %q, config: %s},\n", p.Name, p.Info.Name, providerConfigLiteral(p.Config)))
 	}
-
-	b.WriteString("\treturn providers\n")
+	b.WriteString("\t}\n\n")
+	b.WriteString("\tfor _, c := range configs {\n")
+	b.WriteString("\t\tc.config.Getenv = os.Getenv\n")
+	b.WriteString("\t\tclient, err := aiwf.NewProvider(c.provider, c.config)\n")
+	b.WriteString("\t\tif err != nil {\n")
+	b.WriteString("\t\t\tif !errors.Is(err, aiwf.ErrProviderNotConfigured) {\n")
+	b.WriteString("\t\t\t\tlog.Printf(\"✗ %s provider: %v\", c.name, err)\n")
+	b.WriteString("\t\t\t}\n")
+	b.WriteString("\t\t\tcontinue\n")
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t\trouter.Route(c.name, client)\n")
+	b.WriteString("\t\tlog.Printf(\"✓ %s provider initialized\", c.name)\n")
+	b.WriteString("\t}\n\n")
+	b.WriteString("\treturn router\n")
 	b.WriteString("}\n\n")
 
 	// Middleware functions
@@ -55,7 +55,7 @@ func (g *ServiceGenerator) Generate(packageName string) (string, error) {
 		// Генерируем переменные для агентов если их несколько
 		hasMultipleAgents := len(g.ir.Assistants) > 1
 		if hasMultipleAgents {
-			for name := range g.ir.Assistants {
+			for _, name := range sortedAssistants(g.ir) {
 				agentTypeName := toPascalCase(name) + "Agent"
 				b.WriteString(fmt.Sprintf("\t%s := New%s(client)\n",
 					strings.ToLower(string(name[0])) + name[1:] + "Agent", agentTypeName))
@@ -66,7 +66,7 @@ func (g *ServiceGenerator) Generate(packageName string) (string, error) {
 		}
 	}
 	b.WriteString("\ts.agents = &Agents{\n")
-	for name := range g.ir.Assistants {
+	for _, name := range sortedAssistants(g.ir) {
 		agentTypeName := toPascalCase(name) + "Agent"
 		if len(g.ir.Assistants) > 1 {
 			varName := strings.ToLower(string(name[0])) + name[1:] + "Agent"
@@ -79,7 +79,7 @@ func (g *ServiceGenerator) Generate(packageName string) (string, error) {
 
 	// Inject TypeProvider for single agent
 	if len(g.ir.Assistants) == 1 {
-		for name := range g.ir.Assistants {
+		for _, name := range sortedAssistants(g.ir) {
 			b.WriteString(fmt.Sprintf("\ts.agents.%s.Types = s // Inject TypeProvider\n", toPascalCase(name)))
 		}
 	}
@@ -121,7 +121,8 @@ func (g *ServiceGenerator) Generate(packageName string) (string, error) {
 	b.WriteString("// GetInputTypeFor returns input type for an agent\n")
 	b.WriteString("func (s *Service) GetInputTypeFor(agentName string) (string, any, error) {\n")
 	b.WriteString("\tswitch agentName {\n")
-	for name, assistant := range g.ir.Assistants {
+	for _, name := range sortedAssistants(g.ir) {
+		assistant := g.ir.Assistants[name]
 		if assistant.InputTypeName != "" {
 			b.WriteString(fmt.Sprintf("\tcase \"%s\":\n", name))
 			b.WriteString(fmt.Sprintf("\t\treturn \"%s\", TypeMetadata[\"%s\"], nil\n",
@@ -136,7 +137,8 @@ func (g *ServiceGenerator) Generate(packageName string) (string, error) {
 	b.WriteString("// GetOutputTypeFor returns output type for an agent\n")
 	b.WriteString("func (s *Service) GetOutputTypeFor(agentName string) (string, any, error) {\n")
 	b.WriteString("\tswitch agentName {\n")
-	for name, assistant := range g.ir.Assistants {
+	for _, name := range sortedAssistants(g.ir) {
+		assistant := g.ir.Assistants[name]
 		if assistant.OutputTypeName != "" {
 			b.WriteString(fmt.Sprintf("\tcase \"%s\":\n", name))
 			b.WriteString(fmt.Sprintf("\t\treturn \"%s\", TypeMetadata[\"%s\"], nil\n",
@@ -1,7 +1,10 @@
 package backendgo
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
@@ -9,8 +12,10 @@ import (
 
 // TypesGenerator генерирует types.go файл
 type TypesGenerator struct {
-	ir      *core.IR
-	imports map[string]bool
+	ir       *core.IR
+	imports  map[string]bool
+	unions   map[*core.TypeDef]string // объединения oneOf и их Go-имена
+	patterns map[*core.TypeDef]string // переменные с регулярными выражениями строк
 }
 
 // NewTypesGenerator создаёт новый генератор типов
@@ -30,19 +35,42 @@ func (g *TypesGenerator) Generate(packageName string) (string, error) {
 	b.WriteString(fmt.Sprintf("package %s\n\n", packageName))
 
 	// Собираем импорты
+	g.collectUnions()
+	g.collectPatterns()
 	g.collectImports()
 	g.removeUnusedImports()
+	if len(g.unions) > 0 {
+		g.imports[`"encoding/json"`] = true
+		g.imports[`"fmt"`] = true
+	}
+	if g.hasUntaggedUnion() {
+		g.imports[`"slices"`] = true
+	}
+	if g.hasDefaults() {
+		g.imports[`"encoding/json"`] = true
+	}
 
-	// Добавляем импорт strings если нужна email валидация
-	if g.hasEmailValidation() {
-		g.imports[`"strings"`] = true
+	// Импорты хелперов форматов и регулярных выражений
+	for _, format := range g.usedFormats() {
+		if imp := formatValidators[format].imp; imp != "" {
+			g.imports[imp] = true
+		}
+		g.imports[`"fmt"`] = true
+	}
+	if len(g.patterns) > 0 {
+		g.imports[`"regexp"`] = true
 		g.imports[`"fmt"`] = true
 	}
 
 	// Импорты
 	if len(g.imports) > 0 {
-		b.WriteString("import (\n")
+		imports := make([]string, 0, len(g.imports))
 		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		b.WriteString("import (\n")
+		for _, imp := range imports {
 			b.WriteString(fmt.Sprintf("\t%s\n", imp))
 		}
 		b.WriteString(")\n\n")
@@ -50,8 +78,8 @@ func (g *TypesGenerator) Generate(packageName string) (string, error) {
 
 	// Генерируем типы
 	if g.ir.Types != nil {
-		for typeName, typeDef := range g.ir.Types.Types {
-			typeCode, err := g.generateType(typeName, typeDef)
+		for _, typeName := range sortedTypeNames(g.ir.Types) {
+			typeCode, err := g.generateType(typeName, g.ir.Types.Types[typeName])
 			if err != nil {
 				return "", fmt.Errorf("failed to generate type %s: %w", typeName, err)
 			}
@@ -60,35 +88,84 @@ func (g *TypesGenerator) Generate(packageName string) (string, error) {
 		}
 	}
 
+	// Inline-объединения из полей объектов
+	inlineUnions := g.inlineUnions()
+	for _, td := range inlineUnions {
+		typeCode, err := g.generateUnion(g.unions[td], td)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate type %s: %w", g.unions[td], err)
+		}
+		b.WriteString(typeCode)
+		b.WriteString("\n")
+	}
+
 	// Генерируем валидаторы
 	b.WriteString("// ============ VALIDATORS ============\n\n")
+	if len(g.patterns) > 0 {
+		b.WriteString(g.generatePatterns())
+		b.WriteString("\n")
+	}
 	if g.ir.Types != nil {
-		for typeName, typeDef := range g.ir.Types.Types {
-			if typeDef.Kind == core.KindObject {
-				validator := g.generateValidator(typeName, typeDef)
-				b.WriteString(validator)
-				b.WriteString("\n")
-			}
+		for _, typeName := range sortedTypeNames(g.ir.Types) {
+			validator := g.generateValidator(typeName, g.ir.Types.Types[typeName])
+			b.WriteString(validator)
+			b.WriteString("\n")
 		}
 	}
+	for _, td := range inlineUnions {
+		b.WriteString(g.generateUnionValidator(g.unions[td], td))
+		b.WriteString("\n")
+	}
 
 	// Генерируем метаданные типов для провайдеров
 	b.WriteString("// ============ TYPE METADATA ============\n\n")
-	b.WriteString(g.generateTypeMetadata())
+	metadata, err := g.generateTypeMetadata()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(metadata)
 
 	// Add helper functions if needed
 	b.WriteString("// ============ HELPERS ============\n\n")
-	if g.hasEmailValidation() {
-		b.WriteString("func isValidEmail(email string) bool {\n")
-		b.WriteString("\t// Simple email validation\n")
-		b.WriteString("\treturn strings.Contains(email, \"@\") && strings.Contains(email, \".\")\n")
+	for i, format := range g.usedFormats() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(formatValidators[format].code)
+	}
+	if g.hasDefaults() {
+		b.WriteString("\n// fieldMissing reports whether a field is absent or null\n")
+		b.WriteString("func fieldMissing(fields map[string]json.RawMessage, name string) bool {\n")
+		b.WriteString("\traw, ok := fields[name]\n")
+		b.WriteString("\treturn !ok || string(raw) == \"null\"\n")
+		b.WriteString("}\n")
+	}
+	if g.hasUntaggedUnion() {
+		b.WriteString("\n// matchesFields reports whether an object has all required fields and only known ones\n")
+		b.WriteString("func matchesFields(fields map[string]json.RawMessage, required, known []string) bool {\n")
+		b.WriteString("\tfor _, name := range required {\n")
+		b.WriteString("\t\tif _, ok := fields[name]; !ok {\n\t\t\treturn false\n\t\t}\n\t}\n")
+		b.WriteString("\tfor name := range fields {\n")
+		b.WriteString("\t\tif !slices.Contains(known, name) {\n\t\t\treturn false\n\t\t}\n\t}\n")
+		b.WriteString("\treturn true\n")
 		b.WriteString("}\n")
-		g.imports[`"strings"`] = true
 	}
 
 	return b.String(), nil
 }
 
+// inlineUnions возвращает объединения из полей, которых нет в реестре, в порядке имён
+func (g *TypesGenerator) inlineUnions() []*core.TypeDef {
+	var unions []*core.TypeDef
+	for td, name := range g.unions {
+		if g.ir.Types.Types[name] != td {
+			unions = append(unions, td)
+		}
+	}
+	sort.Slice(unions, func(i, j int) bool { return g.unions[unions[i]] < g.unions[unions[j]] })
+	return unions
+}
+
 // collectImports собирает необходимые импорты
 func (g *TypesGenerator) collectImports() {
 	if g.ir.Types == nil {
@@ -149,7 +226,9 @@ func (g *TypesGenerator) removeUnusedImports() {
 			break
 		}
 	}
-	if !fmtUsed {
+	if fmtUsed {
+		g.imports[`"fmt"`] = true
+	} else {
 		delete(g.imports, `"fmt"`)
 	}
 }
@@ -200,11 +279,11 @@ func (g *TypesGenerator) usesFmt(td *core.TypeDef) bool {
 	// - length constraints
 	// - email/url validation
 	if td.Kind != core.KindObject {
-		return false
+		return g.generateValueValidation(td.Name, "*v", td) != ""
 	}
 
-	for _, prop := range td.Properties {
-		if prop.MinLength != nil || prop.MaxLength != nil {
+	for fieldName, prop := range td.Properties {
+		if g.generateFieldValidation(fieldName, prop) != "" {
 			return true
 		}
 		// Check for email or url validation
@@ -216,25 +295,6 @@ func (g *TypesGenerator) usesFmt(td *core.TypeDef) bool {
 	return false
 }
 
-// hasEmailValidation checks if any type needs email validation
-func (g *TypesGenerator) hasEmailValidation() bool {
-	if g.ir.Types == nil {
-		return false
-	}
-
-	for _, typeDef := range g.ir.Types.Types {
-		if typeDef.Kind == core.KindObject {
-			for _, prop := range typeDef.Properties {
-				if prop.Format == "email" {
-					return true
-				}
-			}
-		}
-	}
-
-	return false
-}
-
 // generateType генерирует Go структуру из TypeDef
 func (g *TypesGenerator) generateType(name string, td *core.TypeDef) (string, error) {
 	var b strings.Builder
@@ -250,28 +310,49 @@ func (g *TypesGenerator) generateType(name string, td *core.TypeDef) (string, er
 		}
 		b.WriteString(")\n")
 
+	case core.KindOneOf:
+		return g.generateUnion(name, td)
+
 	case core.KindObject:
 		// Генерируем структуру
-		b.WriteString(fmt.Sprintf("// %s represents %s\n", name, name))
+		if td.Description != "" {
+			writeDocComment(&b, "", name+": "+td.Description, nil)
+		} else {
+			b.WriteString(fmt.Sprintf("// %s represents %s\n", name, name))
+		}
 		b.WriteString(fmt.Sprintf("type %s struct {\n", name))
 
-		for fieldName, fieldType := range td.Properties {
+		for _, fieldName := range sortedProperties(td) {
+			fieldType := td.Properties[fieldName]
 			goFieldName := toPascalCase(fieldName)
 			goType, err := g.goType(fieldType)
 			if err != nil {
 				return "", err
 			}
+			writeDocComment(&b, "\t", fieldType.Description, fieldType.Examples)
 			jsonTag := fmt.Sprintf("`json:\"%s\"`", fieldName)
 			b.WriteString(fmt.Sprintf("\t%s %s %s\n", goFieldName, goType, jsonTag))
 		}
 		b.WriteString("}\n")
 
+		if hasDefaults(td) {
+			defaults, err := g.generateDefaults(name, td)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString("\n")
+			b.WriteString(defaults)
+		}
+
 	default:
 		// Для простых типов создаём type alias
 		goType, err := g.goType(td)
 		if err != nil {
 			return "", err
 		}
+		if td.Description != "" {
+			writeDocComment(&b, "", name+": "+td.Description, td.Examples)
+		}
 		b.WriteString(fmt.Sprintf("type %s %s\n", name, goType))
 	}
 
@@ -334,6 +415,12 @@ func (g *TypesGenerator) goType(td *core.TypeDef) (string, error) {
 	case core.KindEnum:
 		// Для inline enum возвращаем string
 		return "string", nil
+	case core.KindOneOf:
+		name, ok := g.unions[td]
+		if !ok {
+			return "", fmt.Errorf("oneOf outside of a named type")
+		}
+		return name, nil
 	case core.KindObject:
 		// Для inline объектов нужно генерировать отдельный тип
 		// Пока возвращаем map
@@ -345,14 +432,25 @@ func (g *TypesGenerator) goType(td *core.TypeDef) (string, error) {
 
 // generateValidator генерирует функцию валидации
 func (g *TypesGenerator) generateValidator(name string, td *core.TypeDef) string {
+	if td.Kind == core.KindOneOf {
+		return g.generateUnionValidator(name, td)
+	}
+
 	var b strings.Builder
 
 	b.WriteString(fmt.Sprintf("// Validate%s validates %s\n", name, name))
 	b.WriteString(fmt.Sprintf("func Validate%s(v *%s) error {\n", name, name))
 
 	hasValidation := false
-	for fieldName, fieldType := range td.Properties {
-		validation := g.generateFieldValidation(fieldName, fieldType)
+	if td.Kind != core.KindObject {
+		// Именованный примитив, массив или map: проверяем само значение
+		if validation := g.generateValueValidation(name, "*v", td); validation != "" {
+			b.WriteString(validation)
+			hasValidation = true
+		}
+	}
+	for _, fieldName := range sortedProperties(td) {
+		validation := g.generateFieldValidation(fieldName, td.Properties[fieldName])
 		if validation != "" {
 			b.WriteString(validation)
 			hasValidation = true
@@ -371,51 +469,79 @@ func (g *TypesGenerator) generateValidator(name string, td *core.TypeDef) string
 
 // generateFieldValidation генерирует валидацию для поля
 func (g *TypesGenerator) generateFieldValidation(fieldName string, td *core.TypeDef) string {
+	return g.generateValueValidation(fieldName, "v."+toPascalCase(fieldName), td)
+}
+
+// generateValueValidation генерирует проверки ограничений td для выражения value;
+// label используется в тексте ошибки
+func (g *TypesGenerator) generateValueValidation(label, value string, td *core.TypeDef) string {
 	var validations []string
-	goFieldName := toPascalCase(fieldName)
 
 	switch td.Kind {
 	case core.KindString:
-		if td.MinLength != nil && td.MaxLength != nil {
+		if td.MinLength != nil && td.MaxLength != nil && *td.MinLength == *td.MaxLength {
+			validations = append(validations, fmt.Sprintf(
+				"\tif len(%s) != %d {\n\t\treturn fmt.Errorf(\"%s length must be exactly %d, got %%d\", len(%s))\n\t}\n",
+				value, *td.MinLength, label, *td.MinLength, value,
+			))
+		} else if td.MinLength != nil && td.MaxLength != nil {
 			validations = append(validations, fmt.Sprintf(
-				"\tif len(v.%s) < %d || len(v.%s) > %d {\n\t\treturn fmt.Errorf(\"%s length must be between %d and %d, got %%d\", len(v.%s))\n\t}\n",
-				goFieldName, *td.MinLength, goFieldName, *td.MaxLength,
-				fieldName, *td.MinLength, *td.MaxLength, goFieldName,
+				"\tif len(%s) < %d || len(%s) > %d {\n\t\treturn fmt.Errorf(\"%s length must be between %d and %d, got %%d\", len(%s))\n\t}\n",
+				value, *td.MinLength, value, *td.MaxLength,
+				label, *td.MinLength, *td.MaxLength, value,
 			))
 		} else if td.MinLength != nil {
 			validations = append(validations, fmt.Sprintf(
-				"\tif len(v.%s) < %d {\n\t\treturn fmt.Errorf(\"%s length must be at least %d, got %%d\", len(v.%s))\n\t}\n",
-				goFieldName, *td.MinLength, fieldName, *td.MinLength, goFieldName,
+				"\tif len(%s) < %d {\n\t\treturn fmt.Errorf(\"%s length must be at least %d, got %%d\", len(%s))\n\t}\n",
+				value, *td.MinLength, label, *td.MinLength, value,
 			))
 		} else if td.MaxLength != nil {
 			validations = append(validations, fmt.Sprintf(
-				"\tif len(v.%s) > %d {\n\t\treturn fmt.Errorf(\"%s length must be at most %d, got %%d\", len(v.%s))\n\t}\n",
-				goFieldName, *td.MaxLength, fieldName, *td.MaxLength, goFieldName,
+				"\tif len(%s) > %d {\n\t\treturn fmt.Errorf(\"%s length must be at most %d, got %%d\", len(%s))\n\t}\n",
+				value, *td.MaxLength, label, *td.MaxLength, value,
 			))
 		}
 
-		if td.Format == "email" {
-			validations = append(validations, fmt.Sprintf(
-				"\tif !isValidEmail(v.%s) {\n\t\treturn fmt.Errorf(\"%s must be a valid email\")\n\t}\n",
-				goFieldName, fieldName,
-			))
-		}
+		validations = append(validations, g.generateFormatValidation(label, value, td)...)
+
+	case core.KindUUID:
+		validations = append(validations, g.generateFormatValidation(label, value, td)...)
 
 	case core.KindInt, core.KindNumber:
 		if td.Min != nil && td.Max != nil {
 			validations = append(validations, fmt.Sprintf(
-				"\tif v.%s < %v || v.%s > %v {\n\t\treturn fmt.Errorf(\"%s must be between %v and %v, got %%v\", v.%s)\n\t}\n",
-				goFieldName, *td.Min, goFieldName, *td.Max,
-				fieldName, *td.Min, *td.Max, goFieldName,
+				"\tif %s < %v || %s > %v {\n\t\treturn fmt.Errorf(\"%s must be between %v and %v, got %%v\", %s)\n\t}\n",
+				value, *td.Min, value, *td.Max,
+				label, *td.Min, *td.Max, value,
+			))
+		} else if td.Min != nil {
+			validations = append(validations, fmt.Sprintf(
+				"\tif %s < %v {\n\t\treturn fmt.Errorf(\"%s must be at least %v, got %%v\", %s)\n\t}\n",
+				value, *td.Min, label, *td.Min, value,
+			))
+		} else if td.Max != nil {
+			validations = append(validations, fmt.Sprintf(
+				"\tif %s > %v {\n\t\treturn fmt.Errorf(\"%s must be at most %v, got %%v\", %s)\n\t}\n",
+				value, *td.Max, label, *td.Max, value,
 			))
 		}
 
 	case core.KindArray:
 		if td.MinItems != nil && td.MaxItems != nil {
 			validations = append(validations, fmt.Sprintf(
-				"\tif len(v.%s) < %d || len(v.%s) > %d {\n\t\treturn fmt.Errorf(\"%s must have between %d and %d items, got %%d\", len(v.%s))\n\t}\n",
-				goFieldName, *td.MinItems, goFieldName, *td.MaxItems,
-				fieldName, *td.MinItems, *td.MaxItems, goFieldName,
+				"\tif len(%s) < %d || len(%s) > %d {\n\t\treturn fmt.Errorf(\"%s must have between %d and %d items, got %%d\", len(%s))\n\t}\n",
+				value, *td.MinItems, value, *td.MaxItems,
+				label, *td.MinItems, *td.MaxItems, value,
+			))
+		} else if td.MinItems != nil {
+			validations = append(validations, fmt.Sprintf(
+				"\tif len(%s) < %d {\n\t\treturn fmt.Errorf(\"%s must have at least %d items, got %%d\", len(%s))\n\t}\n",
+				value, *td.MinItems, label, *td.MinItems, value,
+			))
+		} else if td.MaxItems != nil {
+			validations = append(validations, fmt.Sprintf(
+				"\tif len(%s) > %d {\n\t\treturn fmt.Errorf(\"%s must have at most %d items, got %%d\", len(%s))\n\t}\n",
+				value, *td.MaxItems, label, *td.MaxItems, value,
 			))
 		}
 	}
@@ -424,112 +550,195 @@ func (g *TypesGenerator) generateFieldValidation(fieldName string, td *core.Type
 }
 
 // generateTypeMetadata генерирует метаданные типов
-func (g *TypesGenerator) generateTypeMetadata() string {
+func (g *TypesGenerator) generateTypeMetadata() (string, error) {
 	var b strings.Builder
 
 	b.WriteString("// TypeMetadata exports type definitions for providers\n")
 	b.WriteString("var TypeMetadata = map[string]interface{}{\n")
 
 	if g.ir.Types != nil {
-		for typeName, typeDef := range g.ir.Types.Types {
-			schema := g.typeDefToSchema(typeName, typeDef)
-			b.WriteString(fmt.Sprintf("\t\"%s\": %s,\n", typeName, schema))
+		compiler := core.NewSchemaCompiler(g.ir.Types, core.DialectDraft2020)
+		names := make([]string, 0, len(g.ir.Types.Types))
+		for typeName := range g.ir.Types.Types {
+			names = append(names, typeName)
+		}
+		sort.Strings(names)
+
+		for _, typeName := range names {
+			schema, err := compiler.Compile(g.ir.Types.Types[typeName])
+			if err != nil {
+				return "", fmt.Errorf("type %s: %w", typeName, err)
+			}
+			b.WriteString(fmt.Sprintf("\t%q: ", typeName))
+			writeGoLiteral(&b, schema, 1)
+			b.WriteString(",\n")
 		}
 	}
 
 	b.WriteString("}\n")
 
-	return b.String()
+	return b.String(), nil
 }
 
-// typeDefToSchema конвертирует TypeDef в JSON Schema representation для TypeMetadata
-func (g *TypesGenerator) typeDefToSchema(typeName string, td *core.TypeDef) string {
-	var b strings.Builder
-	b.WriteString("map[string]interface{}{\n")
+// writeGoLiteral записывает значение JSON Schema как Go-литерал с отсортированными ключами
+func writeGoLiteral(b *strings.Builder, v any, depth int) {
+	indent := strings.Repeat("\t", depth)
 
-	switch td.Kind {
-	case core.KindString:
-		b.WriteString("\t\t\"type\": \"string\",\n")
-	case core.KindInt:
-		b.WriteString("\t\t\"type\": \"integer\",\n")
-	case core.KindNumber:
-		b.WriteString("\t\t\"type\": \"number\",\n")
-	case core.KindBool:
-		b.WriteString("\t\t\"type\": \"boolean\",\n")
-	case core.KindDatetime:
-		b.WriteString("\t\t\"type\": \"string\",\n")
-		b.WriteString("\t\t\"format\": \"date-time\",\n")
-	case core.KindDate:
-		b.WriteString("\t\t\"type\": \"string\",\n")
-		b.WriteString("\t\t\"format\": \"date\",\n")
-	case core.KindUUID:
-		b.WriteString("\t\t\"type\": \"string\",\n")
-		b.WriteString("\t\t\"format\": \"uuid\",\n")
-	case core.KindEnum:
-		b.WriteString("\t\t\"type\": \"string\",\n")
-		b.WriteString("\t\t\"enum\": []string{")
-		for i, e := range td.Enum {
-			if i > 0 {
-				b.WriteString(", ")
-			}
-			b.WriteString(fmt.Sprintf("\"%s\"", e))
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
 		}
-		b.WriteString("},\n")
-	case core.KindArray:
-		b.WriteString("\t\t\"type\": \"array\",\n")
-		if td.Items != nil {
-			b.WriteString("\t\t\"items\": ")
-			b.WriteString(g.typeDefToSchema("", td.Items))
+		sort.Strings(keys)
+
+		b.WriteString("map[string]interface{}{\n")
+		for _, k := range keys {
+			b.WriteString(fmt.Sprintf("%s\t%q: ", indent, k))
+			writeGoLiteral(b, val[k], depth+1)
 			b.WriteString(",\n")
 		}
-	case core.KindObject:
-		b.WriteString("\t\t\"type\": \"object\",\n")
-		if len(td.Properties) > 0 {
-			b.WriteString("\t\t\"properties\": map[string]interface{}{\n")
-			required := []string{}
-			for propName, propDef := range td.Properties {
-				b.WriteString(fmt.Sprintf("\t\t\t\"%s\": %s,\n", propName, g.typeDefToSchema("", propDef)))
-				// Add all fields to required array (OpenAI strict schema requires this)
-				// If a field is truly optional, it should not be in properties at all
-				required = append(required, propName)
+		b.WriteString(indent + "}")
+	case []string:
+		b.WriteString("[]string{")
+		for i, s := range val {
+			if i > 0 {
+				b.WriteString(", ")
 			}
-			b.WriteString("\t\t},\n")
-			// Always add required array with all properties (OpenAI requirement)
-			if len(required) > 0 {
-				b.WriteString("\t\t\"required\": []string{")
-				for i, r := range required {
-					if i > 0 {
-						b.WriteString(", ")
-					}
-					b.WriteString(fmt.Sprintf("\"%s\"", r))
-				}
-				b.WriteString("},\n")
+			b.WriteString(fmt.Sprintf("%q", s))
+		}
+		b.WriteString("}")
+	case []any:
+		b.WriteString("[]interface{}{")
+		for i, item := range val {
+			if i > 0 {
+				b.WriteString(", ")
 			}
+			writeGoLiteral(b, item, depth)
 		}
-		b.WriteString("\t\t\"additionalProperties\": false,\n")
-	case core.KindMap:
-		b.WriteString("\t\t\"type\": \"object\",\n")
-		b.WriteString("\t\t\"additionalProperties\": true,\n")
-	case core.KindRef:
-		// Resolve reference
-		refName := strings.TrimPrefix(td.Ref, "$")
-		if idx := strings.LastIndex(refName, "."); idx > 0 {
-			refName = refName[idx+1:]
+		b.WriteString("}")
+	case string:
+		b.WriteString(fmt.Sprintf("%q", val))
+	case nil:
+		b.WriteString("nil")
+	default:
+		b.WriteString(fmt.Sprintf("%v", val))
+	}
+}
+
+// writeDocComment пишет комментарий из описания и примеров значений
+func writeDocComment(b *strings.Builder, indent, description string, examples []any) {
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.WriteString(fmt.Sprintf("%s// %s\n", indent, line))
 		}
-		// Look up the referenced type
-		if g.ir.Types != nil {
-			if refType, ok := g.ir.Types.Types[refName]; ok {
-				// Recursively generate schema for referenced type
-				return g.typeDefToSchema(refName, refType)
+	}
+	if len(examples) > 0 {
+		values := make([]string, len(examples))
+		for i, example := range examples {
+			data, _ := json.Marshal(example)
+			values[i] = string(data)
+		}
+		b.WriteString(fmt.Sprintf("%s// Examples: %s\n", indent, strings.Join(values, ", ")))
+	}
+}
+
+// hasDefaults сообщает, есть ли у объектов поля со значениями по умолчанию
+func (g *TypesGenerator) hasDefaults() bool {
+	if g.ir.Types == nil {
+		return false
+	}
+	for _, td := range g.ir.Types.Types {
+		if td.Kind == core.KindObject && hasDefaults(td) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDefaults(td *core.TypeDef) bool {
+	for _, prop := range td.Properties {
+		if prop.Default != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// generateDefaults генерирует UnmarshalJSON, который заполняет отсутствующие и null поля
+// значениями по умолчанию; так они применяются и к входам, и к ответам модели
+func (g *TypesGenerator) generateDefaults(name string, td *core.TypeDef) (string, error) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("// UnmarshalJSON decodes %s and fills absent fields with defaults\n", name))
+	b.WriteString(fmt.Sprintf("func (v *%s) UnmarshalJSON(data []byte) error {\n", name))
+	b.WriteString(fmt.Sprintf("\ttype plain %s\n", name))
+	b.WriteString("\tif err := json.Unmarshal(data, (*plain)(v)); err != nil {\n\t\treturn err\n\t}\n")
+	b.WriteString("\tvar fields map[string]json.RawMessage\n")
+	b.WriteString("\tif err := json.Unmarshal(data, &fields); err != nil {\n\t\treturn err\n\t}\n")
+	for _, fieldName := range sortedProperties(td) {
+		prop := td.Properties[fieldName]
+		if prop.Default == nil {
+			continue
+		}
+		goFieldName := toPascalCase(fieldName)
+		b.WriteString(fmt.Sprintf("\tif fieldMissing(fields, %q) {\n", fieldName))
+		if literal, ok := g.goScalar(prop, prop.Default); ok {
+			b.WriteString(fmt.Sprintf("\t\tv.%s = %s\n", goFieldName, literal))
+		} else {
+			// Составные значения и time.Time декодируются из JSON
+			data, err := json.Marshal(prop.Default)
+			if err != nil {
+				return "", fmt.Errorf("field %s: default: %w", fieldName, err)
 			}
+			b.WriteString(fmt.Sprintf("\t\tif err := json.Unmarshal([]byte(%q), &v.%s); err != nil {\n", data, goFieldName))
+			b.WriteString("\t\t\treturn err\n\t\t}\n")
 		}
-		// Fallback if reference not found
-		b.WriteString("\t\t\"type\": \"object\",\n")
-	default:
-		b.WriteString("\t\t\"type\": \"object\",\n")
+		b.WriteString("\t}\n")
 	}
+	b.WriteString("\treturn nil\n}\n")
+	return b.String(), nil
+}
 
-	b.WriteString("\t}")
-	return b.String()
+// goScalar возвращает Go-литерал для значения строкового, числового или булева поля
+func (g *TypesGenerator) goScalar(td *core.TypeDef, value any) (string, bool) {
+	if td.Kind == core.KindRef && g.ir.Types != nil {
+		target, err := g.ir.Types.Resolve(td.Ref)
+		if err != nil {
+			return "", false
+		}
+		td = target
+	}
+	switch v := value.(type) {
+	case string:
+		switch td.Kind {
+		case core.KindString, core.KindEnum, core.KindUUID:
+			return strconv.Quote(v), true
+		}
+	case bool:
+		if td.Kind == core.KindBool {
+			return strconv.FormatBool(v), true
+		}
+	case int, int64, uint64, float64:
+		switch td.Kind {
+		case core.KindInt:
+			return fmt.Sprintf("%d", int64(toFloat(v))), true
+		case core.KindNumber:
+			return strconv.FormatFloat(toFloat(v), 'g', -1, 64), true
+		}
+	}
+	return "", false
 }
 
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package backendgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// collectUnions даёт имена объединениям oneOf: именованные типы сохраняют своё имя,
+// inline-объединения в полях получают имя <Тип><Поле>
+func (g *TypesGenerator) collectUnions() {
+	g.unions = make(map[*core.TypeDef]string)
+	if g.ir.Types == nil {
+		return
+	}
+	for _, typeName := range sortedTypeNames(g.ir.Types) {
+		td := g.ir.Types.Types[typeName]
+		if td.Kind == core.KindOneOf {
+			g.unions[td] = typeName
+			continue
+		}
+		for _, fieldName := range sortedProperties(td) {
+			g.collectInlineUnion(typeName+toPascalCase(fieldName), td.Properties[fieldName])
+		}
+	}
+}
+
+func (g *TypesGenerator) collectInlineUnion(name string, td *core.TypeDef) {
+	switch td.Kind {
+	case core.KindOneOf:
+		g.unions[td] = name
+	case core.KindArray:
+		g.collectInlineUnion(name+"Item", td.Items)
+	case core.KindMap:
+		g.collectInlineUnion(name+"Value", td.ValueType)
+	}
+}
+
+// generateUnion генерирует объединение: структуру с полем Value типа sealed-интерфейса,
+// методы-маркеры вариантов и (Un)MarshalJSON
+func (g *TypesGenerator) generateUnion(name string, td *core.TypeDef) (string, error) {
+	variants, err := g.ir.Types.UnionVariants(td)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(variants))
+	for i, v := range variants {
+		names[i] = v.Name
+	}
+	marker := "is" + name
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("// %s is one of: %s\n", name, strings.Join(names, ", ")))
+	b.WriteString(fmt.Sprintf("type %s struct {\n\tValue %sVariant\n}\n\n", name, name))
+	b.WriteString(fmt.Sprintf("// %sVariant is implemented by *%s\n", name, strings.Join(names, ", *")))
+	b.WriteString(fmt.Sprintf("type %sVariant interface {\n\t%s()\n}\n\n", name, marker))
+	for _, v := range variants {
+		b.WriteString(fmt.Sprintf("func (*%s) %s() {}\n", v.Name, marker))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("// MarshalJSON encodes the selected variant\n")
+	b.WriteString(fmt.Sprintf("func (u %s) MarshalJSON() ([]byte, error) {\n", name))
+	b.WriteString("\tif u.Value == nil {\n\t\treturn []byte(\"null\"), nil\n\t}\n")
+	b.WriteString("\treturn json.Marshal(u.Value)\n}\n\n")
+
+	if td.Discriminator != "" {
+		b.WriteString(fmt.Sprintf("// UnmarshalJSON picks the variant by the %q field\n", td.Discriminator))
+		b.WriteString(fmt.Sprintf("func (u *%s) UnmarshalJSON(data []byte) error {\n", name))
+		b.WriteString("\tif string(data) == \"null\" {\n\t\tu.Value = nil\n\t\treturn nil\n\t}\n")
+		b.WriteString(fmt.Sprintf("\tvar probe struct {\n\t\tTag string `json:%q`\n\t}\n", td.Discriminator))
+		b.WriteString("\tif err := json.Unmarshal(data, &probe); err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\tswitch probe.Tag {\n")
+		for _, v := range variants {
+			b.WriteString(fmt.Sprintf("\tcase %q:\n", v.Tag))
+			b.WriteString(fmt.Sprintf("\t\tu.Value = new(%s)\n", v.Name))
+		}
+		b.WriteString("\tdefault:\n")
+		b.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: unknown %s %%q\", probe.Tag)\n", name, td.Discriminator))
+		b.WriteString("\t}\n")
+	} else {
+		// Без дискриминатора выбираем первый вариант, у которого есть все
+		// обязательные поля и нет лишних
+		b.WriteString("// UnmarshalJSON picks the first variant whose fields match the object\n")
+		b.WriteString(fmt.Sprintf("func (u *%s) UnmarshalJSON(data []byte) error {\n", name))
+		b.WriteString("\tif string(data) == \"null\" {\n\t\tu.Value = nil\n\t\treturn nil\n\t}\n")
+		b.WriteString("\tvar fields map[string]json.RawMessage\n")
+		b.WriteString("\tif err := json.Unmarshal(data, &fields); err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\tswitch {\n")
+		for _, v := range variants {
+			required, known := unionFields(v.Type)
+			b.WriteString(fmt.Sprintf("\tcase matchesFields(fields, %s, %s):\n", goStringSlice(required), goStringSlice(known)))
+			b.WriteString(fmt.Sprintf("\t\tu.Value = new(%s)\n", v.Name))
+		}
+		b.WriteString("\tdefault:\n")
+		b.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: value matches none of %s\")\n", name, strings.Join(names, ", ")))
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("\treturn json.Unmarshal(data, u.Value)\n}\n")
+
+	return b.String(), nil
+}
+
+// generateUnionValidator проверяет выбранный вариант его валидатором
+func (g *TypesGenerator) generateUnionValidator(name string, td *core.TypeDef) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("// Validate%s validates %s\n", name, name))
+	b.WriteString(fmt.Sprintf("func Validate%s(v *%s) error {\n", name, name))
+	b.WriteString("\tswitch value := v.Value.(type) {\n")
+	if variants, err := g.ir.Types.UnionVariants(td); err == nil {
+		for _, variant := range variants {
+			b.WriteString(fmt.Sprintf("\tcase *%s:\n\t\treturn Validate%s(value)\n", variant.Name, variant.Name))
+		}
+	}
+	b.WriteString("\t}\n")
+	b.WriteString(fmt.Sprintf("\treturn fmt.Errorf(\"%s: no variant set\")\n", name))
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// hasUntaggedUnion сообщает, нужен ли хелпер matchesFields
+func (g *TypesGenerator) hasUntaggedUnion() bool {
+	for td := range g.unions {
+		if td.Discriminator == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// unionFields возвращает обязательные (без значения по умолчанию) и все поля варианта
+func unionFields(td *core.TypeDef) (required, known []string) {
+	for _, field := range sortedProperties(td) {
+		known = append(known, field)
+		if prop := td.Properties[field]; !prop.Optional && prop.Default == nil {
+			required = append(required, field)
+		}
+	}
+	return required, known
+}
+
+func goStringSlice(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+func sortedTypeNames(registry *core.TypeRegistry) []string {
+	names := make([]string, 0, len(registry.Types))
+	for name := range registry.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedProperties(td *core.TypeDef) []string {
+	names := make([]string, 0, len(td.Properties))
+	for name := range td.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
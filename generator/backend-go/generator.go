@@ -2,8 +2,10 @@ package backendgo
 
 import (
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
 )
@@ -56,6 +58,15 @@ func Generate(ir *core.IR, opts Options) (map[string][]byte, error) {
 		files[filepath.Join(sdkDir, "agents.go")] = []byte(agentsCode)
 	}
 
+	// Generate knowledge.go
+	if len(ir.Knowledge) > 0 {
+		knowledgeCode, err := NewKnowledgeGenerator(ir).Generate(opts.Package)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate knowledge: %w", err)
+		}
+		files[filepath.Join(sdkDir, "knowledge.go")] = []byte(knowledgeCode)
+	}
+
 	// Generate service.go
 	serviceGen := NewServiceGenerator(ir)
 	serviceCode, err := serviceGen.Generate(opts.Package)
@@ -78,6 +89,18 @@ func Generate(ir *core.IR, opts Options) (map[string][]byte, error) {
 		files[filepath.Join(opts.OutputDir, "go.mod")] = []byte(goModContent)
 	}
 
+	// Генераторы пишут код построчно; gofmt выравнивает поля и убирает лишние пустые строки
+	for path, content := range files {
+		if !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		formatted, err := format.Source(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		files[path] = formatted
+	}
+
 	return files, nil
 }
 
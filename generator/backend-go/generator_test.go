@@ -1,69 +1,84 @@
 package backendgo
 
 import (
+	"go/format"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/andranikuz/aiwf/generator/core"
+	_ "github.com/andranikuz/aiwf/providers/all"
 )
 
-func TestGenerateService(t *testing.T) {
-	writerInput := filepath.Join("testdata", "writer_input.json")
-	writerOutput := filepath.Join("testdata", "writer_output.json")
-	criticInput := filepath.Join("testdata", "critic_input.json")
-	criticOutput := filepath.Join("testdata", "critic_output.json")
+// testIR собирает IR с чат-агентами на объектных и строковых типах и агентом эмбеддингов.
+func testIR(t *testing.T) *core.IR {
+	t.Helper()
+	registry, err := core.NewTypeParser().ParseTypes(map[string]any{
+		"Tone": "enum(dark, hopeful, playful)",
+		"DraftRequest": map[string]any{
+			"topic":  "string(1..200)",
+			"tone?":  "$Tone",
+			"email?": "string(email)",
+		},
+		"Draft": map[string]any{
+			"text":     "string",
+			"chapters": "string[](min:1, max:10)",
+			"score":    "int(0..10)",
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse types: %v", err)
+	}
 
-	ir := &core.IR{
+	return &core.IR{
 		Assistants: map[string]core.IRAssistant{
 			"writer": {
-				Name:             "writer",
-				Model:            "gpt-4",
-				SystemPrompt:     "You are a writing assistant",
-				InputSchemaRef:   "writer_input.json",
-				OutputSchemaRef:  "writer_output.json",
-				InputSchemaPath:  writerInput,
-				OutputSchemaPath: writerOutput,
-				InputSchemaData:  mustRead(t, writerInput),
-				OutputSchemaData: mustRead(t, writerOutput),
+				Name:           "writer",
+				Kind:           core.KindChat,
+				Use:            "openai",
+				Provider:       "openai",
+				Model:          "gpt-4",
+				SystemPrompt:   "You are a writing assistant",
+				InputTypeName:  "DraftRequest",
+				OutputTypeName: "Draft",
+				InputType:      registry.Types["DraftRequest"],
+				OutputType:     registry.Types["Draft"],
 			},
 			"critic": {
-				Name:             "critic",
-				Model:            "gpt-4-turbo",
-				SystemPrompt:     "Provide critique",
-				InputSchemaRef:   "critic_input.json",
-				OutputSchemaRef:  "critic_output.json",
-				InputSchemaPath:  criticInput,
-				OutputSchemaPath: criticOutput,
-				InputSchemaData:  mustRead(t, criticInput),
-				OutputSchemaData: mustRead(t, criticOutput),
+				Name:           "critic",
+				Kind:           core.KindChat,
+				Use:            "anthropic",
+				Provider:       "anthropic",
+				Model:          "claude-sonnet-4",
+				SystemPrompt:   "Provide critique",
+				InputTypeName:  "Draft",
+				OutputTypeName: "string",
+				InputType:      registry.Types["Draft"],
+				OutputType:     &core.TypeDef{Kind: core.KindString},
 			},
-		},
-		Workflows: map[string]core.IRWorkflow{
-			"novel": {
-				Name: "novel",
-				Steps: []core.IRStep{
-					{Name: "draft", Assistant: "writer"},
-					{Name: "critique", Assistant: "critic", Needs: []string{"draft"}},
-				},
+			"embedder": {
+				Name:     "embedder",
+				Kind:     core.KindEmbedding,
+				Use:      "openai",
+				Provider: "openai",
+				Model:    "text-embedding-3-small",
 			},
 		},
-		TypeRegistry: map[string][]byte{
-			"aiwf://common/Tone": []byte(`{"type":"string","enum":["dark","hopeful","playful"]}`),
-		},
+		Types: registry,
 	}
+}
 
-	files, err := Generate(ir, Options{Package: "generated", OutputDir: "sdk"})
+func TestGenerateService(t *testing.T) {
+	files, err := Generate(testIR(t), Options{Package: "generated", OutputDir: "sdk"})
 	if err != nil {
 		t.Fatalf("Generate: %v", err)
 	}
 
 	goldens := map[string]string{
-		"sdk/service.go":   "service.golden",
-		"sdk/agents.go":    "agents.golden",
-		"sdk/workflows.go": "workflows.golden",
-		"sdk/dialog.go":    "dialog.golden",
-		"sdk/contracts.go": "contracts.golden",
+		"sdk/types.go":   "types.golden",
+		"sdk/agents.go":  "agents.golden",
+		"sdk/service.go": "service.golden",
 	}
 
 	for path, goldenName := range goldens {
@@ -71,6 +86,9 @@ func TestGenerateService(t *testing.T) {
 		if !ok {
 			t.Fatalf("expected generated file %s", path)
 		}
+		if formatted, err := format.Source(content); err != nil || string(formatted) != string(content) {
+			t.Fatalf("generated %s is not gofmt-formatted: %v", path, err)
+		}
 
 		goldenPath := filepath.Join("testdata", goldenName)
 		if os.Getenv("UPDATE_GOLDEN") == "1" {
@@ -89,41 +107,49 @@ func TestGenerateService(t *testing.T) {
 	}
 }
 
-func TestGenerateWithoutWorkflows(t *testing.T) {
-	writerOutput := filepath.Join("testdata", "writer_output.json")
-
-	ir := &core.IR{
-		Assistants: map[string]core.IRAssistant{
-			"writer": {
-				Name:             "writer",
-				Model:            "gpt-4",
-				OutputSchemaRef:  "writer_output.json",
-				OutputSchemaPath: writerOutput,
-				OutputSchemaData: mustRead(t, writerOutput),
-			},
-		},
-		Workflows: map[string]core.IRWorkflow{},
-	}
-
-	files, err := Generate(ir, Options{Package: "generated", OutputDir: "sdk"})
+func TestGenerateWithServer(t *testing.T) {
+	files, err := Generate(testIR(t), Options{Package: "sdk", OutputDir: "out", GenerateServer: true})
 	if err != nil {
 		t.Fatalf("Generate: %v", err)
 	}
 
-	if _, ok := files[filepath.Join("sdk", "workflows.go")]; ok {
-		t.Fatalf("workflows.go should not be generated when workflows are absent")
+	for _, path := range []string{"out/sdk/agents.go", "out/sdk/service.go", "out/cmd/server/main.go", "out/go.mod"} {
+		if _, ok := files[path]; !ok {
+			t.Fatalf("expected generated file %s", path)
+		}
 	}
-
-	if _, ok := files[filepath.Join("sdk", "service.go")]; !ok {
-		t.Fatalf("service.go not generated")
+	if _, ok := files[filepath.Join("out", "sdk", "knowledge.go")]; ok {
+		t.Fatalf("knowledge.go should not be generated without knowledge sources")
 	}
 }
 
-func mustRead(t *testing.T, path string) []byte {
-	t.Helper()
-	data, err := os.ReadFile(path)
+func TestGenerateOneSidedBounds(t *testing.T) {
+	registry, err := core.NewTypeParser().ParseTypes(map[string]any{
+		"Limits": map[string]any{
+			"age":   "int(0..)",
+			"ratio": "number(..1)",
+			"tags":  "string[](max:5)",
+			"items": "string[](min:1)",
+			"code":  "string(3)",
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse types: %v", err)
+	}
+
+	code, err := NewTypesGenerator(&core.IR{Types: registry}).Generate("sdk")
 	if err != nil {
-		t.Fatalf("read file %s: %v", path, err)
+		t.Fatalf("Generate: %v", err)
+	}
+	for _, check := range []string{
+		"if v.Age < 0 {",
+		"if v.Ratio > 1 {",
+		"if len(v.Tags) > 5 {",
+		"if len(v.Items) < 1 {",
+		"if len(v.Code) != 3 {",
+	} {
+		if !strings.Contains(code, check) {
+			t.Errorf("expected %q in validator:\n%s", check, code)
+		}
 	}
-	return data
 }
@@ -2,6 +2,7 @@ package clientphp
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
@@ -11,6 +12,7 @@ import (
 type Generator struct {
 	ir      *core.IR
 	baseURL string
+	unions  map[*core.TypeDef]string // объединения oneOf и имена их классов
 }
 
 // New создает новый генератор PHP клиента
@@ -39,9 +41,13 @@ func (g *Generator) Generate() (string, error) {
 	}
 
 	// Generate type classes
+	g.collectUnions()
 	if err := g.generateTypes(&b); err != nil {
 		return "", err
 	}
+	if err := g.generateUnions(&b); err != nil {
+		return "", err
+	}
 
 	// Generate main client class
 	if err := g.generateClient(&b); err != nil {
@@ -55,7 +61,11 @@ func (g *Generator) Generate() (string, error) {
 func (g *Generator) generateEnums(b *strings.Builder) error {
 	for typeName, typeDef := range g.ir.Types.Types {
 		if typeDef.Kind == core.KindEnum && len(typeDef.Enum) > 0 {
-			b.WriteString(fmt.Sprintf("/**\n * Enum for %s\n */\n", typeName))
+			b.WriteString(fmt.Sprintf("/**\n * Enum for %s\n", typeName))
+			if typeDef.Description != "" {
+				b.WriteString(fmt.Sprintf(" * %s\n", typeDef.Description))
+			}
+			b.WriteString(" */\n")
 			b.WriteString(fmt.Sprintf("enum %s: string {\n", typeName))
 			for _, value := range typeDef.Enum {
 				// Convert to valid PHP constant name
@@ -104,6 +114,10 @@ func (g *Generator) generateTypes(b *strings.Builder) error {
 			}
 
 			phpType := g.mapTypeToPHP(field)
+			if g.nullableClass(field) {
+				phpType = "?" + phpType
+			}
+			writeDocBlock(b, "        ", field.Description, field.Examples)
 			b.WriteString(fmt.Sprintf("        public %s $%s", phpType, fieldName))
 		}
 		b.WriteString("\n    ) {}\n\n")
@@ -116,10 +130,12 @@ func (g *Generator) generateTypes(b *strings.Builder) error {
 		b.WriteString("        return [\n")
 		for _, fieldName := range fieldNames {
 			field := typeDef.Properties[fieldName]
-			if field.Kind == core.KindArray && field.Items != nil && field.Items.Kind == core.KindRef {
+			if _, ok := g.itemClass(field); ok {
 				// Array of objects - need to convert each
 				b.WriteString(fmt.Sprintf("            '%s' => array_map(fn($item) => $item->toArray(), $this->%s),\n", fieldName, fieldName))
-			} else if field.Kind == core.KindRef {
+			} else if g.nullableClass(field) {
+				b.WriteString(fmt.Sprintf("            '%s' => $this->%s?->toArray(),\n", fieldName, fieldName))
+			} else if _, ok := g.className(field); ok {
 				// Single object - convert to array
 				b.WriteString(fmt.Sprintf("            '%s' => $this->%s->toArray(),\n", fieldName, fieldName))
 			} else {
@@ -142,16 +158,23 @@ func (g *Generator) generateTypes(b *strings.Builder) error {
 				b.WriteString(",\n")
 			}
 
+			// Отсутствующее или null поле получает значение по умолчанию
+			value := fmt.Sprintf("$data['%s']", fieldName)
+			if field.Default != nil {
+				value = fmt.Sprintf("(%s ?? %s)", value, phpLiteral(field.Default))
+			}
+
 			// Handle nested objects
-			if field.Kind == core.KindRef {
-				refType := strings.TrimPrefix(field.Ref, "$")
-				b.WriteString(fmt.Sprintf("            %s::fromArray($data['%s'])", refType, fieldName))
-			} else if field.Kind == core.KindArray && field.Items != nil && field.Items.Kind == core.KindRef {
+			if refType, ok := g.className(field); ok && g.nullableClass(field) {
+				// Необязательная ссылка (в том числе на свой же тип) может отсутствовать
+				b.WriteString(fmt.Sprintf("            isset(%s) ? %s::fromArray(%s) : null", value, refType, value))
+			} else if ok {
+				b.WriteString(fmt.Sprintf("            %s::fromArray(%s)", refType, value))
+			} else if refType, ok := g.itemClass(field); ok {
 				// Handle array of objects
-				refType := strings.TrimPrefix(field.Items.Ref, "$")
-				b.WriteString(fmt.Sprintf("            array_map(fn($item) => %s::fromArray($item), $data['%s'])", refType, fieldName))
+				b.WriteString(fmt.Sprintf("            array_map(fn($item) => %s::fromArray($item), %s)", refType, value))
 			} else {
-				b.WriteString(fmt.Sprintf("            $data['%s']", fieldName))
+				b.WriteString(fmt.Sprintf("            %s", value))
 			}
 		}
 		b.WriteString("\n        );\n")
@@ -183,7 +206,17 @@ func (g *Generator) generateClient(b *strings.Builder) error {
 	b.WriteString("    }\n\n")
 
 	// Generate methods for each agent
-	for agentName, agent := range g.ir.Assistants {
+	agentNames := make([]string, 0, len(g.ir.Assistants))
+	for agentName := range g.ir.Assistants {
+		agentNames = append(agentNames, agentName)
+	}
+	sort.Strings(agentNames)
+	for _, agentName := range agentNames {
+		agent := g.ir.Assistants[agentName]
+		if agent.IsEmbedding() {
+			g.generateEmbeddingMethod(b, agentName)
+			continue
+		}
 		if err := g.generateAgentMethod(b, agentName, &agent); err != nil {
 			return err
 		}
@@ -228,6 +261,20 @@ func (g *Generator) generateClient(b *strings.Builder) error {
 	return nil
 }
 
+// generateEmbeddingMethod генерирует метод агента эмбеддингов: тексты → векторы в том же порядке
+func (g *Generator) generateEmbeddingMethod(b *strings.Builder, agentName string) {
+	b.WriteString("    /**\n")
+	b.WriteString(fmt.Sprintf("     * Embed texts with %s agent\n", agentName))
+	b.WriteString("     *\n")
+	b.WriteString("     * @param string[] $texts\n")
+	b.WriteString("     * @return float[][] one vector per text, in input order\n")
+	b.WriteString("     */\n")
+	b.WriteString(fmt.Sprintf("    public function %s(array $texts): array {\n", agentName))
+	b.WriteString(fmt.Sprintf("        $response = $this->request('/agent/%s', ['input' => array_values($texts)]);\n\n", agentName))
+	b.WriteString("        return $response['data'];\n")
+	b.WriteString("    }\n\n")
+}
+
 // generateAgentMethod генерирует метод для вызова агента
 func (g *Generator) generateAgentMethod(b *strings.Builder, agentName string, agent *core.IRAssistant) error {
 	methodName := agentName
@@ -302,10 +349,9 @@ func (g *Generator) mapTypeToPHP(field *core.TypeDef) string {
 		return "array"
 	}
 
-	// Reference type
-	if field.Kind == core.KindRef {
-		refType := strings.TrimPrefix(field.Ref, "$")
-		return refType
+	// Reference type or oneOf union
+	if className, ok := g.className(field); ok {
+		return className
 	}
 
 	// Enum type (inline enum - just use string)
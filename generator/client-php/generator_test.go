@@ -0,0 +1,53 @@
+package clientphp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+func TestGenerateEmbeddingMethod(t *testing.T) {
+	ir := &core.IR{
+		Assistants: map[string]core.IRAssistant{
+			"search": {Name: "search", Kind: core.KindEmbedding, Model: "text-embedding-3-small"},
+			"writer": {
+				Name:           "writer",
+				Kind:           core.KindChat,
+				InputTypeName:  "string",
+				OutputTypeName: "string",
+				InputType:      &core.TypeDef{Kind: core.KindString},
+				OutputType:     &core.TypeDef{Kind: core.KindString},
+			},
+		},
+		Types: &core.TypeRegistry{Types: map[string]*core.TypeDef{}},
+	}
+
+	code, err := New(ir, "http://localhost:8080").Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	start := strings.Index(code, "public function search(")
+	if start < 0 {
+		t.Fatalf("embedding method not generated:\n%s", code)
+	}
+	method := code[start:]
+	method = method[:strings.Index(method, "\n    }\n")]
+
+	for _, want := range []string{
+		"public function search(array $texts): array {",
+		"$this->request('/agent/search', ['input' => array_values($texts)])",
+		"return $response['data'];",
+	} {
+		if !strings.Contains(method, want) {
+			t.Errorf("embedding method must contain %q:\n%s", want, method)
+		}
+	}
+	if strings.Contains(method, "toArray()") {
+		t.Errorf("embedding method must post texts, not a request object:\n%s", method)
+	}
+	if !strings.Contains(code, "public function writer(string $input): string {") {
+		t.Errorf("chat method changed:\n%s", code)
+	}
+}
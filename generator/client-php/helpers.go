@@ -1,7 +1,10 @@
 package clientphp
 
 import (
+	"encoding/json"
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/andranikuz/aiwf/generator/core"
@@ -26,3 +29,86 @@ func (g *Generator) getTypeName(typeName string, typeDef *core.TypeDef) string {
 func sortFields(fields []string) {
 	sort.Strings(fields)
 }
+
+// pascalCase переводит snake_case и kebab-case в PascalCase
+func pascalCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// writeDocBlock пишет PHPDoc из описания и примеров значений
+func writeDocBlock(b *strings.Builder, indent, description string, examples []any) {
+	var lines []string
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(examples) > 0 {
+		values := make([]string, len(examples))
+		for i, example := range examples {
+			data, _ := json.Marshal(example)
+			values[i] = string(data)
+		}
+		lines = append(lines, "Examples: "+strings.Join(values, ", "))
+	}
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		b.WriteString(fmt.Sprintf("%s/** %s */\n", indent, lines[0]))
+	default:
+		b.WriteString(indent + "/**\n")
+		for _, line := range lines {
+			b.WriteString(fmt.Sprintf("%s * %s\n", indent, line))
+		}
+		b.WriteString(indent + " */\n")
+	}
+}
+
+// phpLiteral записывает значение из YAML как литерал PHP
+func phpLiteral(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(val) + "'"
+	case bool:
+		return strconv.FormatBool(val)
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case float64:
+		s := strconv.FormatFloat(val, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = phpLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sortFields(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = phpLiteral(k) + " => " + phpLiteral(val[k])
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return "null"
+}
@@ -0,0 +1,145 @@
+package clientphp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andranikuz/aiwf/generator/core"
+)
+
+// collectUnions даёт имена объединениям oneOf так же, как Go-генератор:
+// именованные типы сохраняют имя, inline-объединения в полях получают <Тип><Поле>.
+func (g *Generator) collectUnions() {
+	g.unions = make(map[*core.TypeDef]string)
+	if g.ir.Types == nil {
+		return
+	}
+	for typeName, td := range g.ir.Types.Types {
+		if td.Kind == core.KindOneOf {
+			g.unions[td] = typeName
+			continue
+		}
+		for fieldName, field := range td.Properties {
+			g.collectInlineUnion(typeName+pascalCase(fieldName), field)
+		}
+	}
+}
+
+func (g *Generator) collectInlineUnion(name string, td *core.TypeDef) {
+	switch td.Kind {
+	case core.KindOneOf:
+		g.unions[td] = name
+	case core.KindArray:
+		g.collectInlineUnion(name+"Item", td.Items)
+	case core.KindMap:
+		g.collectInlineUnion(name+"Value", td.ValueType)
+	}
+}
+
+// generateUnions генерирует для каждого объединения final-класс с полем $value одного
+// из классов-вариантов; fromArray выбирает вариант по дискриминатору или по набору полей.
+func (g *Generator) generateUnions(b *strings.Builder) error {
+	names := make([]string, 0, len(g.unions))
+	byName := make(map[string]*core.TypeDef, len(g.unions))
+	for td, name := range g.unions {
+		names = append(names, name)
+		byName[name] = td
+	}
+	sortFields(names)
+
+	for _, name := range names {
+		td := byName[name]
+		variants, err := g.ir.Types.UnionVariants(td)
+		if err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+		classes := make([]string, len(variants))
+		for i, v := range variants {
+			classes[i] = v.Name
+		}
+
+		b.WriteString(fmt.Sprintf("/**\n * %s is one of: %s\n */\n", name, strings.Join(classes, ", ")))
+		b.WriteString(fmt.Sprintf("final class %s {\n", name))
+		b.WriteString("    public function __construct(\n")
+		b.WriteString(fmt.Sprintf("        public %s $value\n", strings.Join(classes, "|")))
+		b.WriteString("    ) {}\n\n")
+
+		b.WriteString("    /**\n     * Convert to array for JSON encoding\n     */\n")
+		b.WriteString("    public function toArray(): array {\n")
+		b.WriteString("        return $this->value->toArray();\n")
+		b.WriteString("    }\n\n")
+
+		b.WriteString("    /**\n     * Create instance from array, picking the variant\n     */\n")
+		b.WriteString(fmt.Sprintf("    public static function fromArray(array $data): %s {\n", name))
+		if td.Discriminator != "" {
+			b.WriteString(fmt.Sprintf("        return match ($data['%s'] ?? null) {\n", td.Discriminator))
+			for _, v := range variants {
+				b.WriteString(fmt.Sprintf("            '%s' => new %s(%s::fromArray($data)),\n", v.Tag, name, v.Name))
+			}
+			b.WriteString(fmt.Sprintf("            default => throw new \\InvalidArgumentException('%s: unknown %s ' . json_encode($data['%s'] ?? null)),\n",
+				name, td.Discriminator, td.Discriminator))
+			b.WriteString("        };\n")
+		} else {
+			b.WriteString("        $keys = array_keys($data);\n")
+			for _, v := range variants {
+				required, known := variantFields(v.Type)
+				b.WriteString(fmt.Sprintf("        if (!array_diff(%s, $keys) && !array_diff($keys, %s)) {\n", phpList(required), phpList(known)))
+				b.WriteString(fmt.Sprintf("            return new %s(%s::fromArray($data));\n", name, v.Name))
+				b.WriteString("        }\n")
+			}
+			b.WriteString(fmt.Sprintf("        throw new \\InvalidArgumentException('%s: value matches none of %s');\n",
+				name, strings.Join(classes, ", ")))
+		}
+		b.WriteString("    }\n")
+		b.WriteString("}\n\n")
+	}
+	return nil
+}
+
+// className возвращает PHP-класс для ссылки или объединения
+func (g *Generator) className(field *core.TypeDef) (string, bool) {
+	switch field.Kind {
+	case core.KindRef:
+		return strings.TrimPrefix(field.Ref, "$"), true
+	case core.KindOneOf:
+		name, ok := g.unions[field]
+		return name, ok
+	}
+	return "", false
+}
+
+// nullableClass сообщает, что поле — необязательная ссылка на класс без значения по умолчанию:
+// такое поле объявляется как ?Class и может быть null
+func (g *Generator) nullableClass(field *core.TypeDef) bool {
+	_, ok := g.className(field)
+	return ok && field.Optional && field.Default == nil
+}
+
+// itemClass возвращает класс элементов массива ссылок или объединений
+func (g *Generator) itemClass(field *core.TypeDef) (string, bool) {
+	if field.Kind != core.KindArray || field.Items == nil {
+		return "", false
+	}
+	return g.className(field.Items)
+}
+
+// variantFields возвращает обязательные (без значения по умолчанию) и все поля варианта
+func variantFields(td *core.TypeDef) (required, known []string) {
+	for fieldName, field := range td.Properties {
+		known = append(known, fieldName)
+		if !field.Optional && field.Default == nil {
+			required = append(required, fieldName)
+		}
+	}
+	sortFields(required)
+	sortFields(known)
+	return required, known
+}
+
+func phpList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
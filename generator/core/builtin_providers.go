@@ -0,0 +1,113 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+// builtinProviders описывает встроенные провайдеры из пакетов providers/*, чтобы
+// проверка спецификации не зависела от импорта providers/all. Фабрики здесь нет:
+// клиентов создаёт реестр aiwf. Совпадение с регистрацией проверяет тест providers/all.
+var builtinProviders = []aiwf.ProviderInfo{
+	{
+		Name:           "anthropic",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/anthropic",
+		EnvKeys:        []string{"ANTHROPIC_API_KEY"},
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityTools, aiwf.CapabilityPromptCache},
+		IgnoredOptions: fixedIgnoredOptions("seed", "presence_penalty", "frequency_penalty", "reasoning_effort"),
+	},
+	{
+		Name:           "azure",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/azure",
+		EnvKeys:        []string{"AZURE_OPENAI_API_KEY", "AZURE_OPENAI_ENDPOINT"},
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityThreads, aiwf.CapabilityPromptCache, aiwf.CapabilityEmbeddings},
+		IgnoredOptions: openAIIgnoredOptions(""),
+	},
+	{
+		Name:           "gemini",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/gemini",
+		EnvKeys:        []string{"GEMINI_API_KEY"},
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityStreaming, aiwf.CapabilityThreads},
+		IgnoredOptions: fixedIgnoredOptions("reasoning_effort"),
+	},
+	{
+		Name:         "grok",
+		ImportPath:   "github.com/andranikuz/aiwf/providers/grok",
+		EnvKeys:      []string{"GROK_API_KEY"},
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityStreaming, aiwf.CapabilityThreads},
+	},
+	{
+		Name:           "local",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/local",
+		EnvKeys:        []string{"LOCAL_LLM_BASE_URL"},
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityEmbeddings},
+		IgnoredOptions: openAIIgnoredOptions(""),
+	},
+	{
+		Name:           "ollama",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/ollama",
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityStreaming},
+		IgnoredOptions: fixedIgnoredOptions("reasoning_effort"),
+	},
+	{
+		Name:           "openai",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/openai",
+		EnvKeys:        []string{"OPENAI_API_KEY"},
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityThreads, aiwf.CapabilityPromptCache, aiwf.CapabilityEmbeddings},
+		IgnoredOptions: openAIIgnoredOptions("responses"),
+	},
+}
+
+// fixedIgnoredOptions возвращает IgnoredOptions, не зависящий от режима.
+func fixedIgnoredOptions(options ...string) func(string) []string {
+	return func(string) []string { return options }
+}
+
+// openAIIgnoredOptions повторяет openai.IgnoredOptions: Responses API не принимает
+// seed, stop и штрафы. defaultMode — режим клиента, если mode: не задан.
+func openAIIgnoredOptions(defaultMode string) func(string) []string {
+	return func(mode string) []string {
+		if mode == "" {
+			mode = defaultMode
+		}
+		if mode == "responses" {
+			return []string{"seed", "stop", "presence_penalty", "frequency_penalty"}
+		}
+		return nil
+	}
+}
+
+// BuiltinProviders возвращает описания встроенных провайдеров, отсортированные по имени.
+func BuiltinProviders() []aiwf.ProviderInfo {
+	return append([]aiwf.ProviderInfo(nil), builtinProviders...)
+}
+
+// LookupProvider ищет провайдера в реестре aiwf, а если он не зарегистрирован —
+// среди встроенных. Так пользовательские провайдеры работают после регистрации,
+// а встроенные — и без импорта providers/all.
+func LookupProvider(name string) (aiwf.ProviderInfo, bool) {
+	if info, ok := aiwf.LookupProvider(name); ok {
+		return info, true
+	}
+	for _, info := range builtinProviders {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return aiwf.ProviderInfo{}, false
+}
+
+// ProviderNames возвращает имена встроенных и зарегистрированных в реестре aiwf провайдеров.
+func ProviderNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, info := range append(BuiltinProviders(), aiwf.Providers()...) {
+		if !seen[info.Name] {
+			seen[info.Name] = true
+			names = append(names, info.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,86 @@
+package core
+
+// Code — стабильный код проверки. Коды не меняются между версиями и используются
+// в машиночитаемом выводе validate (JSON, SARIF) для фильтрации и подавления.
+type Code string
+
+// Коды ошибок и предупреждений. Сотни группируют проверки по разделам спецификации.
+const (
+	CodeUnknown    Code = "AIWF000" // ошибка без отдельного кода
+	CodeReadFailed Code = "AIWF001"
+	CodeYAMLSyntax Code = "AIWF002"
+	CodeYAMLType   Code = "AIWF003"
+	CodeUnknownKey Code = "AIWF004"
+
+	CodeInvalidType        Code = "AIWF101"
+	CodeUnknownType        Code = "AIWF102"
+	CodeUndefinedReference Code = "AIWF103"
+	CodeInvalidValue       Code = "AIWF104"
+	CodeRecursiveType      Code = "AIWF105"
+	CodeInvalidFormat      Code = "AIWF106"
+	CodeImportFailed       Code = "AIWF110"
+	CodeImportCycle        Code = "AIWF111"
+	CodeTypeNameClash      Code = "AIWF112"
+
+	CodeDialogWithoutThread Code = "AIWF201"
+	CodeUnknownKind         Code = "AIWF202"
+	CodeKindMismatch        Code = "AIWF203"
+	CodeInvalidGeneration   Code = "AIWF204"
+
+	CodeUnknownProvider       Code = "AIWF301"
+	CodeUnsupportedCapability Code = "AIWF302"
+	CodeInvalidProvider       Code = "AIWF303"
+	CodeInvalidModel          Code = "AIWF304"
+	CodeIgnoredOption         Code = "AIWF305"
+
+	CodeInvalidKnowledge Code = "AIWF401"
+	CodeUnknownKnowledge Code = "AIWF402"
+	CodeUnusedKnowledge  Code = "AIWF403"
+)
+
+// CodeInfo описывает проверку для отчётов (правила SARIF).
+type CodeInfo struct {
+	Code        Code
+	Name        string // короткое имя в PascalCase
+	Description string
+}
+
+// Codes перечисляет все проверки в порядке кодов.
+var Codes = []CodeInfo{
+	{CodeUnknown, "Unknown", "Error without a dedicated check code"},
+	{CodeReadFailed, "ReadFailed", "Specification or imported file cannot be read"},
+	{CodeYAMLSyntax, "YAMLSyntax", "YAML syntax error"},
+	{CodeYAMLType, "YAMLType", "Value has the wrong YAML type for the field"},
+	{CodeUnknownKey, "UnknownKey", "Key is not part of the spec format"},
+	{CodeInvalidType, "InvalidType", "Type definition cannot be parsed"},
+	{CodeUnknownType, "UnknownType", "Input or output type of an assistant cannot be resolved"},
+	{CodeUndefinedReference, "UndefinedReference", "Reference to an undefined type or module"},
+	{CodeInvalidValue, "InvalidValue", "Default or example value does not match the field type"},
+	{CodeRecursiveType, "RecursiveType", "Recursive type has no finite value"},
+	{CodeInvalidFormat, "InvalidFormat", "Unknown string format or invalid custom format pattern"},
+	{CodeImportFailed, "ImportFailed", "Invalid import entry"},
+	{CodeImportCycle, "ImportCycle", "Imports form a cycle"},
+	{CodeTypeNameClash, "TypeNameClash", "Imported type name clashes with another type"},
+	{CodeDialogWithoutThread, "DialogWithoutThread", "Dialog mode requires a thread"},
+	{CodeUnknownKind, "UnknownKind", "Unknown assistant kind"},
+	{CodeKindMismatch, "KindMismatch", "Field is not allowed for the assistant kind"},
+	{CodeInvalidGeneration, "InvalidGeneration", "Generation parameter is out of range"},
+	{CodeUnknownProvider, "UnknownProvider", "Provider is not declared or registered"},
+	{CodeUnsupportedCapability, "UnsupportedCapability", "Provider lacks a capability the assistant needs"},
+	{CodeInvalidProvider, "InvalidProvider", "Invalid provider settings"},
+	{CodeInvalidModel, "InvalidModel", "Invalid model alias"},
+	{CodeIgnoredOption, "IgnoredOption", "Option has no effect with the selected provider"},
+	{CodeInvalidKnowledge, "InvalidKnowledge", "Invalid knowledge source settings"},
+	{CodeUnknownKnowledge, "UnknownKnowledge", "Assistant references an unknown or duplicate knowledge source"},
+	{CodeUnusedKnowledge, "UnusedKnowledge", "Knowledge source is not used by any assistant"},
+}
+
+// LookupCode возвращает описание проверки по коду.
+func LookupCode(code Code) (CodeInfo, bool) {
+	for _, info := range Codes {
+		if info.Code == code {
+			return info, true
+		}
+	}
+	return CodeInfo{}, false
+}
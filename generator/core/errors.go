@@ -28,7 +28,7 @@ func (m *MultiError) Append(err error) {
 		m.Errors = append(m.Errors, multi.Errors...)
 		return
 	}
-	m.Errors = append(m.Errors, &ValidationError{Msg: err.Error()})
+	m.Errors = append(m.Errors, &ValidationError{Code: CodeUnknown, Msg: err.Error()})
 }
 
 func (m *MultiError) HasErrors() bool {
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BuiltinFormats перечисляет встроенные форматы string(format).
+var BuiltinFormats = []string{"email", "url", "phone", "uuid", "date", "datetime"}
+
+// PhonePattern — шаблон формата phone в JSON Schema: необязательный +, цифры и
+// разделители. Генерируемые валидаторы дополнительно считают цифры (от 7 до 15).
+const PhonePattern = `^\+?[0-9 ().-]{7,20}$`
+
+// IsBuiltinFormat сообщает, встроен ли формат.
+func IsBuiltinFormat(format string) bool {
+	for _, f := range BuiltinFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatPattern возвращает регулярное выражение формата для JSON Schema:
+// для пользовательских форматов — из formats:, для phone — PhonePattern.
+func (r *TypeRegistry) FormatPattern(format string) string {
+	if format == "phone" {
+		return PhonePattern
+	}
+	if r == nil {
+		return ""
+	}
+	return r.Formats[format]
+}
+
+// ValidFormat проверяет строку по встроенному формату так же, как генерируемые
+// валидаторы. Для неизвестного формата возвращает true.
+func ValidFormat(format, s string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "url":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	case "phone":
+		return validPhone(s)
+	case "uuid":
+		return validUUID(s)
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "datetime":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	}
+	return true
+}
+
+func validPhone(s string) bool {
+	digits := 0
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" ().-", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+func validUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			if c != '-' {
+				return false
+			}
+			continue
+		}
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveFormats проверяет раздел formats: имя — идентификатор, не совпадающий со
+// встроенным форматом, значение — компилируемое регулярное выражение.
+func resolveFormats(formats map[string]string, merr *MultiError) map[string]string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	valid := make(map[string]string, len(formats))
+	for _, name := range names {
+		field := "formats." + name
+		pattern := formats[name]
+		switch {
+		case !isIdent(name):
+			merr.Append(&ValidationError{Code: CodeInvalidFormat, Field: field, Msg: fmt.Sprintf("format name %q must be an identifier", name)})
+		case IsBuiltinFormat(name):
+			merr.Append(&ValidationError{Code: CodeInvalidFormat, Field: field, Msg: fmt.Sprintf("format %s is built in and cannot be redefined", name)})
+		case pattern == "":
+			merr.Append(&ValidationError{Code: CodeInvalidFormat, Field: field, Msg: "format pattern is empty"})
+		default:
+			if _, err := regexp.Compile(pattern); err != nil {
+				merr.Append(&ValidationError{Code: CodeInvalidFormat, Field: field, Msg: fmt.Sprintf("invalid pattern: %v", err)})
+				continue
+			}
+			valid[name] = pattern
+		}
+	}
+	return valid
+}
+
+// checkFormat сообщает о формате, который не встроен и не объявлен в formats:.
+func checkFormat(field string, td *TypeDef, registry *TypeRegistry, merr *MultiError) {
+	if td.Format == "" || IsBuiltinFormat(td.Format) {
+		return
+	}
+	if _, ok := registry.Formats[td.Format]; ok {
+		return
+	}
+	merr.Append(&ValidationError{
+		Code:  CodeInvalidFormat,
+		Field: field,
+		Msg:   fmt.Sprintf("unknown string format %q (built in: %s; declare custom ones in formats:)", td.Format, strings.Join(BuiltinFormats, ", ")),
+	})
+}
+
+func isIdent(s string) bool {
+	if s == "" || !isIdentStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isIdentPart(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidFormat(t *testing.T) {
+	tests := []struct {
+		format, value string
+		valid         bool
+	}{
+		{"email", "user@example.com", true},
+		{"email", "User <user@example.com>", false},
+		{"email", "user.example.com", false},
+		{"url", "https://example.com/path?q=1", true},
+		{"url", "example.com", false},
+		{"phone", "+1 (555) 123-4567", true},
+		{"phone", "12-34", false},
+		{"phone", "+1 555 CALL NOW", false},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"date", "2024-02-29", true},
+		{"date", "2023-02-29", false},
+		{"datetime", "2024-02-29T10:00:00+03:00", true},
+		{"datetime", "2024-02-29 10:00", false},
+	}
+	for _, tt := range tests {
+		if got := ValidFormat(tt.format, tt.value); got != tt.valid {
+			t.Errorf("%s %q: got %v, want %v", tt.format, tt.value, got, tt.valid)
+		}
+	}
+}
+
+func TestFormatsValidation(t *testing.T) {
+	spec := &Spec{
+		Formats: map[string]string{
+			"sku":   `^[A-Z]{3}-\d{4}$`,
+			"email": ".+",
+			"bad":   "([",
+		},
+		Types: map[string]any{
+			"Item": map[string]any{
+				"sku":  "string(sku)",
+				"ssn":  "string(ssn)",
+				"code": map[string]any{"$type": "string(sku)", "default": "abc"},
+			},
+		},
+	}
+
+	err := ResolveSpec(spec)
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	want := map[string]Code{
+		"formats.email":           CodeInvalidFormat,
+		"formats.bad":             CodeInvalidFormat,
+		"types.Item.ssn":          CodeInvalidFormat,
+		"types.Item.code.default": CodeInvalidValue,
+	}
+	if len(merr.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), merr.Errors)
+	}
+	for _, e := range merr.Errors {
+		if want[e.Field] != e.Code {
+			t.Errorf("unexpected error %+v", e)
+		}
+	}
+	if formats := spec.Resolved.TypeRegistry.Formats; len(formats) != 1 || formats["sku"] == "" {
+		t.Errorf("expected only the valid format to be registered, got %v", formats)
+	}
+}
+
+func TestSchemaPatterns(t *testing.T) {
+	reg := &TypeRegistry{
+		Formats: map[string]string{"sku": `^[A-Z]{3}-\d{4}$`},
+		Types: map[string]*TypeDef{
+			"Item": {Kind: KindObject, Properties: map[string]*TypeDef{
+				"code":  {Kind: KindString, Pattern: "^[a-z]+$"},
+				"sku":   {Kind: KindString, Format: "sku"},
+				"phone": {Kind: KindString, Format: "phone"},
+				"email": {Kind: KindString, Format: "email"},
+			}},
+		},
+	}
+	schema, err := NewSchemaCompiler(reg, DialectOpenAIStrict).Compile(reg.Types["Item"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := schema["properties"].(map[string]any)
+	for field, want := range map[string]string{"code": "^[a-z]+$", "sku": `^[A-Z]{3}-\d{4}$`, "phone": PhonePattern} {
+		if got := props[field].(map[string]any)["pattern"]; got != want {
+			t.Errorf("%s: pattern %v, want %s", field, got, want)
+		}
+	}
+	email := props["email"].(map[string]any)
+	if email["format"] != "email" || email["pattern"] != nil {
+		t.Errorf("email must keep the format keyword only: %v", email)
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import "fmt"
+
+// ReasoningEfforts перечисляет допустимые значения reasoning_effort.
+var ReasoningEfforts = []string{"minimal", "low", "medium", "high"}
+
+// validateGeneration проверяет диапазоны параметров генерации ассистента.
+func validateGeneration(assistant string, g Generation) []*ValidationError {
+	var errs []*ValidationError
+	field := func(name string) string {
+		return fmt.Sprintf("assistants.%s.%s", assistant, name)
+	}
+	checkRange := func(name string, v *float64, min, max float64) {
+		if v != nil && (*v < min || *v > max) {
+			errs = append(errs, &ValidationError{
+				Code:  CodeInvalidGeneration,
+				Field: field(name),
+				Msg:   fmt.Sprintf("must be between %g and %g, got %g", min, max, *v),
+			})
+		}
+	}
+
+	checkRange("temperature", g.Temperature, 0, 2)
+	checkRange("top_p", g.TopP, 0, 1)
+	checkRange("presence_penalty", g.PresencePenalty, -2, 2)
+	checkRange("frequency_penalty", g.FrequencyPenalty, -2, 2)
+
+	if g.ReasoningEffort != "" && !contains(ReasoningEfforts, g.ReasoningEffort) {
+		errs = append(errs, &ValidationError{
+			Code:  CodeInvalidGeneration,
+			Field: field("reasoning_effort"),
+			Msg:   fmt.Sprintf("unknown value %q (expected one of %v)", g.ReasoningEffort, ReasoningEfforts),
+		})
+	}
+	return errs
+}
+
+// ignoredGeneration предупреждает о заданных параметрах генерации, которые провайдер
+// ассистента в режиме mode не передаёт модели.
+func ignoredGeneration(assistant string, as AssistantSpec, mode string) []*ValidationWarning {
+	info, ok := LookupProvider(as.Use)
+	if !ok || info.IgnoredOptions == nil {
+		return nil
+	}
+	set := map[string]bool{
+		"temperature":       as.Temperature != nil,
+		"top_p":             as.TopP != nil,
+		"seed":              as.Seed != nil,
+		"stop":              len(as.Stop) > 0,
+		"presence_penalty":  as.PresencePenalty != nil,
+		"frequency_penalty": as.FrequencyPenalty != nil,
+		"reasoning_effort":  as.ReasoningEffort != "",
+	}
+
+	provider := fmt.Sprintf("provider %q", as.Use)
+	if mode != "" {
+		provider += fmt.Sprintf(" in %s mode", mode)
+	}
+	var warns []*ValidationWarning
+	for _, option := range info.IgnoredOptions(mode) {
+		if set[option] {
+			warns = append(warns, &ValidationWarning{
+				Code:  CodeIgnoredOption,
+				Field: fmt.Sprintf("assistants.%s.%s", assistant, option),
+				Msg:   fmt.Sprintf("%s does not support %s; option is ignored", provider, option),
+			})
+		}
+	}
+	return warns
+}
+
+// cloneGeneration копирует параметры, чтобы IR не разделял указатели со Spec.
+func cloneGeneration(g Generation) Generation {
+	out := Generation{
+		Stop:            cloneSlice(g.Stop),
+		ReasoningEffort: g.ReasoningEffort,
+	}
+	out.Temperature = cloneFloat(g.Temperature)
+	out.TopP = cloneFloat(g.TopP)
+	out.PresencePenalty = cloneFloat(g.PresencePenalty)
+	out.FrequencyPenalty = cloneFloat(g.FrequencyPenalty)
+	out.Seed = cloneInt(g.Seed)
+	return out
+}
+
+func cloneFloat(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	out := *v
+	return &out
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	out := *v
+	return &out
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
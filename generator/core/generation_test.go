@@ -0,0 +1,81 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "coretest-modes",
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema},
+		New: func(aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+			return nil, aiwf.ErrProviderNotConfigured
+		},
+		IgnoredOptions: func(mode string) []string {
+			if mode == "chat_completions" {
+				return nil
+			}
+			return []string{"seed", "stop"}
+		},
+	})
+}
+
+func TestGenerationDistinguishesZeroFromUnset(t *testing.T) {
+	var as AssistantSpec
+	if err := yaml.Unmarshal([]byte("model: gpt-4o\ntemperature: 0\nseed: 1\nstop: [END]\n"), &as); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if as.Temperature == nil || *as.Temperature != 0 {
+		t.Fatalf("expected explicit zero temperature, got %v", as.Temperature)
+	}
+	if as.TopP != nil {
+		t.Fatalf("expected unset top_p, got %v", *as.TopP)
+	}
+	if as.Seed == nil || *as.Seed != 1 || len(as.Stop) != 1 {
+		t.Fatalf("unexpected params: %+v", as.Generation)
+	}
+}
+
+func TestValidateGeneration(t *testing.T) {
+	high, negative := 2.5, -0.1
+	errs := validateGeneration("writer", Generation{Temperature: &high, TopP: &negative, ReasoningEffort: "extreme"})
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+	if errs[0].Field != "assistants.writer.temperature" {
+		t.Errorf("unexpected field: %s", errs[0].Field)
+	}
+}
+
+func TestIgnoredGenerationWarnings(t *testing.T) {
+	seed := 7
+	spec := &Spec{
+		Providers: map[string]ProviderSpec{
+			"chat": {Type: "coretest-modes", Mode: "chat_completions"},
+		},
+		Assistants: map[string]AssistantSpec{
+			"writer": {Use: "coretest-modes", Model: "m", Generation: Generation{Seed: &seed, Stop: []string{"END"}}},
+			"critic": {Provider: "chat", Model: "m", Generation: Generation{Seed: &seed}},
+		},
+	}
+
+	ir, err := BuildIR(spec)
+	merr, ok := err.(*MultiError)
+	if ir == nil || !ok || merr.HasErrors() {
+		t.Fatalf("expected warnings only, got %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, warn := range merr.Warnings {
+		if warn.Code != CodeIgnoredOption {
+			t.Errorf("unexpected warning %+v", warn)
+		}
+		fields[warn.Field] = true
+	}
+	// В режиме chat_completions провайдер передаёт seed, поэтому critic без предупреждений
+	if len(fields) != 2 || !fields["assistants.writer.seed"] || !fields["assistants.writer.stop"] {
+		t.Fatalf("unexpected warnings: %v", merr.Warnings)
+	}
+}
@@ -0,0 +1,351 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// module — загруженный файл с типами из раздела imports:.
+type module struct {
+	name     string // каноническое имя модуля, уникальное в пределах спецификации
+	path     string // абсолютный путь к файлу
+	source   *Source
+	registry *TypeRegistry     // типы модуля под исходными именами
+	aliases  map[string]string // псевдоним импорта → каноническое имя модуля
+}
+
+// importLoader загружает импорты рекурсивно: каждый файл читается один раз,
+// циклы обнаруживаются по стеку загрузки.
+type importLoader struct {
+	root    string             // каталог корневой спецификации, для сообщений об ошибках
+	modules map[string]*module // абсолютный путь → модуль
+	names   map[string]string  // каноническое имя → абсолютный путь
+	order   []*module          // модули в порядке загрузки
+	stack   []string
+}
+
+func newImportLoader(root string) *importLoader {
+	return &importLoader{
+		root:    root,
+		modules: make(map[string]*module),
+		names:   make(map[string]string),
+	}
+}
+
+// loadImports загружает импорты спецификации и возвращает псевдонимы её импортов.
+// src — файл, в котором объявлены импорты; по нему ошибки получают позиции.
+func (l *importLoader) loadImports(dir string, imports []ImportSpec, src *Source) (map[string]string, error) {
+	aliases := make(map[string]string, len(imports))
+	for i, imp := range imports {
+		field := fmt.Sprintf("imports[%d]", i)
+		fail := func(code Code, msg string) error {
+			return &ValidationError{Code: code, Field: field, Msg: msg, Pos: src.Lookup(field)}
+		}
+		if imp.As == "" {
+			return nil, fail(CodeImportFailed, fmt.Sprintf("import %s: 'as' is required", imp.Path))
+		}
+		if strings.ContainsAny(imp.As, ".$") {
+			return nil, fail(CodeImportFailed, fmt.Sprintf("import %s: invalid alias %q", imp.Path, imp.As))
+		}
+		if imp.Path == "" {
+			return nil, fail(CodeImportFailed, fmt.Sprintf("import %s: 'path' is required", imp.As))
+		}
+		if _, dup := aliases[imp.As]; dup {
+			return nil, fail(CodeImportFailed, fmt.Sprintf("import alias %s is used more than once", imp.As))
+		}
+
+		path := imp.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		mod, err := l.load(path, imp.As)
+		if err != nil {
+			// Ошибки внутри модуля уже указывают на его файл
+			if _, ok := err.(*MultiError); ok {
+				return nil, err
+			}
+			if ve, ok := err.(*ValidationError); ok {
+				if ve.Pos.IsValid() {
+					return nil, err
+				}
+				return nil, fail(ve.Code, ve.Msg)
+			}
+			return nil, fail(CodeImportFailed, err.Error())
+		}
+		aliases[imp.As] = mod.name
+	}
+	return aliases, nil
+}
+
+func (l *importLoader) load(path, alias string) (*module, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range l.stack {
+		if p == path {
+			chain := append(append([]string{}, l.stack[i:]...), path)
+			for j := range chain {
+				chain[j] = l.display(chain[j])
+			}
+			return nil, &ValidationError{Code: CodeImportCycle, Msg: "import cycle: " + strings.Join(chain, " -> ")}
+		}
+	}
+	if mod, ok := l.modules[path]; ok {
+		return mod, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("import %s: %w", alias, err)
+	}
+	source, err := parseSource(relativePath(path), data)
+	if err != nil {
+		return nil, err
+	}
+	if err := source.checkKeys(reflect.TypeOf(Spec{})); err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := source.decode(&spec); err != nil {
+		return nil, err
+	}
+	registry, err := NewTypeParser().ParseTypes(spec.Types)
+	if merr, ok := err.(*MultiError); ok {
+		source.annotate(merr)
+		return nil, merr
+	}
+
+	mod := &module{name: l.uniqueName(alias), path: path, source: source, registry: registry}
+	l.modules[path] = mod
+	l.names[mod.name] = path
+	l.order = append(l.order, mod)
+
+	l.stack = append(l.stack, path)
+	mod.aliases, err = l.loadImports(filepath.Dir(path), spec.Imports, source)
+	l.stack = l.stack[:len(l.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+	return mod, nil
+}
+
+// uniqueName возвращает псевдоним, а если он уже занят другим файлом — псевдоним с номером.
+func (l *importLoader) uniqueName(alias string) string {
+	name := alias
+	for i := 2; ; i++ {
+		if _, taken := l.names[name]; !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", alias, i)
+	}
+}
+
+func (l *importLoader) display(path string) string {
+	if rel, err := filepath.Rel(l.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// ImportedTypeName возвращает имя импортированного типа в сгенерированном коде:
+// common.Address → CommonAddress.
+func ImportedTypeName(module, typeName string) string {
+	parts := strings.FieldsFunc(module, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "") + strings.ToUpper(typeName[:1]) + typeName[1:]
+}
+
+// resolveImports загружает импорты спецификации и добавляет их типы в реестр.
+//
+// Импортированные типы попадают в registry.Types под именами ImportedTypeName,
+// чтобы генераторы выпускали каждый из них один раз, и в registry.Imports под
+// каноническим именем модуля. Ссылки $alias.Type внутри спецификации и модулей
+// переписываются на плоские имена; ID типов имеют вид aiwf://module/Type.
+func resolveImports(spec *Spec, registry *TypeRegistry) error {
+	dir := "."
+	if spec.Resolved.Path != "" {
+		dir = filepath.Dir(spec.Resolved.Path)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	loader := newImportLoader(dir)
+	aliases, err := loader.loadImports(dir, spec.Imports, spec.Resolved.Source)
+	if err != nil {
+		return err
+	}
+
+	merr := &MultiError{}
+	if registry.Imports == nil {
+		registry.Imports = make(map[string]*TypeRegistry)
+	}
+	origins := make(map[string]string) // плоское имя → module.Type
+	for _, mod := range loader.order {
+		registry.Imports[mod.name] = mod.registry
+		for _, typeName := range sortedTypeNames(mod.registry) {
+			td := mod.registry.Types[typeName]
+			flat := ImportedTypeName(mod.name, typeName)
+			qualified := mod.name + "." + typeName
+			if _, clash := registry.Types[flat]; clash {
+				if origin, ok := origins[flat]; ok {
+					field := "types." + typeName
+					merr.Append(&ValidationError{
+						Code:  CodeTypeNameClash,
+						Field: field,
+						Msg:   fmt.Sprintf("imported types %s and %s both map to %s", origin, qualified, flat),
+						Pos:   mod.source.Lookup(field),
+					})
+				} else {
+					merr.Append(&ValidationError{
+						Code:  CodeTypeNameClash,
+						Field: "types." + flat,
+						Msg:   fmt.Sprintf("imported type %s clashes with local type %s", qualified, flat),
+					})
+				}
+				continue
+			}
+			td.Name = flat
+			td.ID = fmt.Sprintf("aiwf://%s/%s", mod.name, typeName)
+			registry.Types[flat] = td
+			origins[flat] = qualified
+		}
+	}
+
+	// Ссылки переписываются после регистрации всех модулей: модуль может ссылаться на тип,
+	// загруженный позже него
+	for _, mod := range loader.order {
+		q := &refQualifier{loader: loader, mod: mod, aliases: mod.aliases, merr: merr}
+		for typeName, td := range mod.registry.Types {
+			q.qualify("types."+typeName, td)
+		}
+	}
+	q := &refQualifier{loader: loader, aliases: aliases, merr: merr}
+	for name, td := range registry.Types {
+		if _, imported := origins[name]; imported {
+			continue
+		}
+		q.qualify("types."+name, td)
+	}
+
+	for name, assistant := range spec.Assistants {
+		fields := map[string]*string{"input_type": &assistant.InputType, "output_type": &assistant.OutputType}
+		for key, value := range fields {
+			if !isQualifiedRef(*value) {
+				continue
+			}
+			flat, err := qualifiedName(strings.TrimPrefix(*value, "$"), loader, aliases)
+			if err != nil {
+				merr.Append(&ValidationError{Code: CodeUndefinedReference, Field: fmt.Sprintf("assistants.%s.%s", name, key), Msg: err.Error()})
+				continue
+			}
+			*value = flat
+		}
+		spec.Assistants[name] = assistant
+	}
+
+	if merr.HasErrors() {
+		return merr
+	}
+	return nil
+}
+
+// refQualifier заменяет ссылки в типах на плоские имена. Для типов модуля (mod != nil)
+// неквалифицированные ссылки на его собственные типы тоже переписываются.
+type refQualifier struct {
+	loader  *importLoader
+	mod     *module
+	aliases map[string]string
+	merr    *MultiError
+}
+
+func (q *refQualifier) qualify(field string, td *TypeDef) {
+	if td == nil {
+		return
+	}
+	switch td.Kind {
+	case KindRef:
+		ref := strings.TrimPrefix(td.Ref, "$")
+		switch {
+		case strings.Contains(ref, "."):
+			flat, err := qualifiedName(ref, q.loader, q.aliases)
+			if err != nil {
+				verr := &ValidationError{Code: CodeUndefinedReference, Field: field, Msg: err.Error()}
+				if q.mod != nil {
+					verr.Pos = q.mod.source.Lookup(field)
+				}
+				q.merr.Append(verr)
+				return
+			}
+			td.Ref = "$" + flat
+		case q.mod != nil:
+			if _, ok := q.mod.registry.Types[ref]; ok {
+				td.Ref = "$" + ImportedTypeName(q.mod.name, ref)
+			}
+		}
+	case KindArray:
+		q.qualify(field, td.Items)
+	case KindMap:
+		q.qualify(field, td.ValueType)
+	case KindOneOf:
+		for _, variant := range td.Variants {
+			q.qualify(field, variant)
+		}
+	case KindObject:
+		for fieldName, prop := range td.Properties {
+			q.qualify(field+"."+fieldName, prop)
+		}
+	}
+}
+
+// qualifiedName переводит alias.Type в плоское имя импортированного типа.
+func qualifiedName(ref string, loader *importLoader, aliases map[string]string) (string, error) {
+	idx := strings.Index(ref, ".")
+	alias, typeName := ref[:idx], ref[idx+1:]
+	name, ok := aliases[alias]
+	if !ok {
+		return "", fmt.Errorf("module %s not imported", alias)
+	}
+	mod := loader.modules[loader.names[name]]
+	if _, ok := mod.registry.Types[typeName]; !ok {
+		return "", fmt.Errorf("type %s not found in module %s", typeName, alias)
+	}
+	return ImportedTypeName(name, typeName), nil
+}
+
+func sortedTypeNames(registry *TypeRegistry) []string {
+	names := make([]string, 0, len(registry.Types))
+	for name := range registry.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// relativePath возвращает путь относительно рабочего каталога, если это возможно.
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// isQualifiedRef сообщает, является ли выражение типа ссылкой вида [$]module.Type.
+func isQualifiedRef(expr string) bool {
+	expr = strings.TrimPrefix(expr, "$")
+	idx := strings.Index(expr, ".")
+	return idx > 0 && idx < len(expr)-1 && !strings.ContainsAny(expr, "()[] ")
+}
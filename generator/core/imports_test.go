@@ -0,0 +1,170 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSpecFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolveImports(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"spec.yaml": `
+imports:
+  - as: common
+    path: ./types/common.yaml
+  - as: billing
+    path: ./types/billing.yaml
+types:
+  Order:
+    address: $common.Address
+    invoice: $billing.Invoice
+assistants:
+  shipper:
+    use: coretest
+    model: m
+    input_type: $common.Address
+    output_type: Order
+`,
+		"types/common.yaml": `
+types:
+  Address:
+    city: string
+    country: $Country
+  Country: enum(ru, us)
+`,
+		// billing импортирует тот же файл под другим псевдонимом
+		"types/billing.yaml": `
+imports:
+  - as: shared
+    path: common.yaml
+types:
+  Invoice:
+    billing_address: $shared.Address
+    lines: $Line[]
+  Line:
+    amount: number
+`,
+	})
+
+	spec, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ResolveSpec(spec); err != nil {
+		t.Fatalf("ResolveSpec: %v", err)
+	}
+	registry := spec.Resolved.TypeRegistry
+
+	for _, name := range []string{"Order", "CommonAddress", "CommonCountry", "BillingInvoice", "BillingLine"} {
+		if _, ok := registry.Types[name]; !ok {
+			t.Fatalf("type %s is missing", name)
+		}
+	}
+	if len(registry.Types) != 5 || len(registry.Imports) != 2 {
+		t.Fatalf("common.yaml must be loaded once: types %d, imports %d", len(registry.Types), len(registry.Imports))
+	}
+
+	address := registry.Types["CommonAddress"]
+	if address.ID != "aiwf://common/Address" {
+		t.Fatalf("unexpected ID %s", address.ID)
+	}
+	if ref := address.Properties["country"].Ref; ref != "$CommonCountry" {
+		t.Fatalf("module-local ref not qualified: %s", ref)
+	}
+	if ref := registry.Types["Order"].Properties["address"].Ref; ref != "$CommonAddress" {
+		t.Fatalf("unexpected ref %s", ref)
+	}
+	invoice := registry.Types["BillingInvoice"]
+	if ref := invoice.Properties["billing_address"].Ref; ref != "$CommonAddress" {
+		t.Fatalf("transitive ref must point to the shared module: %s", ref)
+	}
+	if ref := invoice.Properties["lines"].Items.Ref; ref != "$BillingLine" {
+		t.Fatalf("unexpected ref %s", ref)
+	}
+
+	if td, err := registry.Resolve("$common.Address"); err != nil || td != address {
+		t.Fatalf("Resolve($common.Address) = %v, %v", td, err)
+	}
+	shipper := spec.Assistants["shipper"]
+	if shipper.InputType != "CommonAddress" || shipper.Resolved.InputType != address {
+		t.Fatalf("assistant input type not resolved: %q", shipper.InputType)
+	}
+}
+
+func TestResolveImportsErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"spec.yaml": "imports:\n  - {as: a, path: a.yaml}\n",
+				"a.yaml":    "imports:\n  - {as: b, path: b.yaml}\ntypes:\n  A: string\n",
+				"b.yaml":    "imports:\n  - {as: a, path: a.yaml}\ntypes:\n  B: string\n",
+			},
+			want: "import cycle: a.yaml -> b.yaml -> a.yaml",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"spec.yaml": "imports:\n  - {as: common, path: nope.yaml}\n",
+			},
+			want: "import common",
+		},
+		{
+			name: "unknown module",
+			files: map[string]string{
+				"spec.yaml": "types:\n  Order:\n    address: $common.Address\n",
+			},
+			want: "module common not imported",
+		},
+		{
+			name: "unknown type",
+			files: map[string]string{
+				"spec.yaml":   "imports:\n  - {as: common, path: common.yaml}\ntypes:\n  Order:\n    address: $common.Adress\n",
+				"common.yaml": "types:\n  Address: string\n",
+			},
+			want: "type Adress not found in module common",
+		},
+		{
+			name: "clash with local type",
+			files: map[string]string{
+				"spec.yaml":   "imports:\n  - {as: common, path: common.yaml}\ntypes:\n  CommonAddress: string\n",
+				"common.yaml": "types:\n  Address: string\n",
+			},
+			want: "imported type common.Address clashes with local type CommonAddress",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeSpecFiles(t, tc.files)
+			spec, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ResolveSpec(spec)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
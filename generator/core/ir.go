@@ -1,24 +1,35 @@
 package core
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // IR описывает нормализованный набор ассистентов.
 type IR struct {
     Assistants map[string]IRAssistant
+    Providers  map[string]IRProvider
     Threads    map[string]ThreadSpec
+    Knowledge  map[string]IRKnowledge
     Types      *TypeRegistry
 }
 
 // IRAssistant содержит сведения для генерации SDK.
 type IRAssistant struct {
     Name           string
+    Kind           string // KindChat или KindEmbedding
+    Provider       string // имя провайдера для маршрутизации: запись providers: или use:
     Model          string
+    Deployment     string
     SystemPrompt   string
     Use            string
     InputTypeName  string
     OutputTypeName string
     MaxTokens      int
-    Temperature    float64
+    Dimensions     int
+    Generation     Generation
+    PromptCache    bool
+    Knowledge      []string
     InputType      *TypeDef
     OutputType     *TypeDef
     DependsOn      []string
@@ -27,41 +38,134 @@ type IRAssistant struct {
 }
 
 
+// IRProvider — разобранная запись раздела providers:.
+type IRProvider struct {
+    Name      string
+    Type      string
+    BaseURL   string
+    APIKeyEnv string
+    Timeout   time.Duration
+    Headers   map[string]string
+    Org       string
+    Mode      string
+
+    NumCtx      int
+    KeepAlive   string
+    PullMissing bool
+
+    Deployments map[string]string
+}
+
+// IRKnowledge — разобранная запись раздела knowledge:.
+type IRKnowledge struct {
+    Name         string
+    Paths        []string
+    Include      []string
+    ChunkSize    int
+    ChunkOverlap *int // nil — значение по умолчанию рантайма
+    TopK         int
+    Embedding    string // имя ассистента kind: embedding
+    Dir          string // каталог спецификации, от которого считаются относительные Paths
+}
+
 // BuildIR преобразует Spec в IR и выполняет дополнительную валидацию.
 func BuildIR(spec *Spec) (*IR, error) {
 	if spec == nil {
 		return nil, fmt.Errorf("core: spec is nil")
 	}
 
-	// Resolve types first
+	merr := &MultiError{}
+
+	// Resolve types first; ошибки типов не прерывают остальные проверки
 	if err := ResolveSpec(spec); err != nil {
-		return nil, fmt.Errorf("failed to resolve spec: %w", err)
+		merr.Append(err)
 	}
 
     ir := &IR{
         Assistants: make(map[string]IRAssistant, len(spec.Assistants)),
+        Providers:  make(map[string]IRProvider, len(spec.Providers)),
         Threads:    make(map[string]ThreadSpec, len(spec.Threads)),
         Types:      spec.Resolved.TypeRegistry,
     }
 
-	merr := &MultiError{}
+	for name, ps := range spec.Providers {
+		provider, errs := buildProvider(name, ps)
+		for _, verr := range errs {
+			merr.Append(verr)
+		}
+		ir.Providers[name] = provider
+	}
+	for _, verr := range validateModels(spec) {
+		merr.Append(verr)
+	}
+	knowledge, errs := buildKnowledge(spec)
+	for _, verr := range errs {
+		merr.Append(verr)
+	}
+	ir.Knowledge = knowledge
+	for _, warn := range unusedKnowledge(spec) {
+		merr.AppendWarning(warn)
+	}
 
 	for name, as := range spec.Assistants {
-		// Если output_type не указан, используем string по умолчанию
+		as, route, errs := resolveAssistantProvider(spec, name, as)
+		for _, verr := range errs {
+			merr.Append(verr)
+		}
+		for _, verr := range validateProvider(name, as) {
+			merr.Append(verr)
+		}
+		for _, verr := range validateAssistantKnowledge(spec, name, as) {
+			merr.Append(verr)
+		}
+		for _, verr := range validateKind(name, as) {
+			merr.Append(verr)
+		}
+		for _, verr := range validateGeneration(name, as.Generation) {
+			merr.Append(verr)
+		}
+		for _, warn := range ignoredGeneration(name, as, spec.Providers[route].Mode) {
+			merr.AppendWarning(warn)
+		}
+		if as.Deployment != "" && as.Use != "azure" {
+			merr.AppendWarning(&ValidationWarning{
+				Code:  CodeIgnoredOption,
+				Field: fmt.Sprintf("assistants.%s.deployment", name),
+				Msg:   "deployment is only used by the azure provider",
+			})
+		}
+		if warn := validatePromptCache(name, as); warn != nil {
+			merr.AppendWarning(warn)
+		}
+
+		// Если output_type не указан, используем string по умолчанию;
+		// у агентов эмбеддингов выходного типа нет
 		outputTypeName := as.OutputType
-		if outputTypeName == "" {
+		kind := as.Kind
+		if kind == "" {
+			kind = KindChat
+		}
+		if kind == KindEmbedding {
+			outputTypeName = ""
+		} else if outputTypeName == "" {
 			outputTypeName = "string"
 		}
 
         assistant := IRAssistant{
             Name:           name,
+            Kind:           kind,
+            Provider:       route,
             Model:          as.Model,
+            Deployment:     as.Deployment,
             SystemPrompt:   as.SystemPrompt,
             Use:            as.Use,
             InputTypeName:  as.InputType,
             OutputTypeName: outputTypeName,
             MaxTokens:      as.MaxTokens,
-            Temperature:    as.Temperature,
+            Dimensions:     as.Dimensions,
+            Generation:     cloneGeneration(as.Generation),
+            PromptCache:    as.PromptCache,
+            Knowledge:      cloneSlice(as.Knowledge),
             InputType:      as.Resolved.InputType,
             OutputType:     as.Resolved.OutputType,
             DependsOn:      cloneSlice(as.DependsOn),
@@ -78,6 +182,8 @@ func BuildIR(spec *Spec) (*IR, error) {
 
 
 
+	spec.Resolved.Source.annotate(merr)
+
 	if merr.HasErrors() {
 		return nil, merr
 	}
@@ -4,25 +4,21 @@ import "testing"
 
 func TestBuildIRSuccess(t *testing.T) {
 	spec := &Spec{
+		Types: map[string]any{
+			"Draft": map[string]any{"text": "string"},
+		},
 		Assistants: map[string]AssistantSpec{
 			"writer": {
+				Use:          "coretest",
 				Model:        "gpt-4",
 				SystemPrompt: "Be creative",
-				Resolved: AssistantResolution{
-					InputSchemaPath:  "/tmp/input.json",
-					OutputSchemaPath: "/tmp/output.json",
-				},
+				InputType:    "string",
+				OutputType:   "$Draft",
 			},
-		},
-		Workflows: map[string]WorkflowSpec{
-			"novel": {
-				Description: "Novel workflow",
-				DAG: []WorkflowDAG{
-					{
-						Step:      "draft",
-						Assistant: "writer",
-					},
-				},
+			"critic": {
+				Use:       "coretest",
+				Model:     "gpt-4",
+				DependsOn: []string{"writer"},
 			},
 		},
 	}
@@ -32,100 +28,29 @@ func TestBuildIRSuccess(t *testing.T) {
 		t.Fatalf("BuildIR: %v", err)
 	}
 
-	if len(ir.Assistants) != 1 {
-		t.Fatalf("expected 1 assistant, got %d", len(ir.Assistants))
+	if len(ir.Assistants) != 2 {
+		t.Fatalf("expected 2 assistants, got %d", len(ir.Assistants))
 	}
 	writer := ir.Assistants["writer"]
-	if writer.OutputSchemaPath != "/tmp/output.json" {
-		t.Fatalf("unexpected output schema path: %s", writer.OutputSchemaPath)
-	}
-
-	wf, ok := ir.Workflows["novel"]
-	if !ok {
-		t.Fatalf("workflow novel not found")
-	}
-	if len(wf.Steps) != 1 || wf.Steps[0].Name != "draft" {
-		t.Fatalf("unexpected steps: %+v", wf.Steps)
-	}
-}
-
-func TestBuildIRDuplicateStep(t *testing.T) {
-	spec := &Spec{
-		Assistants: map[string]AssistantSpec{
-			"writer": {
-				Resolved: AssistantResolution{OutputSchemaPath: "/tmp/output.json"},
-			},
-		},
-		Workflows: map[string]WorkflowSpec{
-			"novel": {
-				DAG: []WorkflowDAG{
-					{Step: "draft", Assistant: "writer"},
-					{Step: "draft", Assistant: "writer"},
-				},
-			},
-		},
-	}
-
-	if _, err := BuildIR(spec); err == nil {
-		t.Fatalf("expected duplicate step error")
-	}
-}
-
-func TestBuildIRNeedsUnknownStep(t *testing.T) {
-	spec := &Spec{
-		Assistants: map[string]AssistantSpec{
-			"writer": {
-				Resolved: AssistantResolution{OutputSchemaPath: "/tmp/output.json"},
-			},
-		},
-		Workflows: map[string]WorkflowSpec{
-			"novel": {
-				DAG: []WorkflowDAG{
-					{Step: "draft", Assistant: "writer", Needs: []string{"outline"}},
-				},
-			},
-		},
+	if writer.Kind != KindChat || writer.OutputTypeName != "Draft" || writer.OutputType == nil {
+		t.Fatalf("unexpected writer: %+v", writer)
 	}
-
-	if _, err := BuildIR(spec); err == nil {
-		t.Fatalf("expected missing dependency error")
-	}
-}
-
-func TestBuildIRScatterValidation(t *testing.T) {
-	spec := &Spec{
-		Assistants: map[string]AssistantSpec{
-			"writer": {
-				Resolved: AssistantResolution{OutputSchemaPath: "/tmp/output.json"},
-			},
-		},
-		Workflows: map[string]WorkflowSpec{
-			"novel": {
-				DAG: []WorkflowDAG{
-					{Step: "draft", Assistant: "writer", Scatter: &ScatterSpec{}},
-				},
-			},
-		},
+	if _, ok := ir.Types.Types["Draft"]; !ok {
+		t.Fatal("type Draft not registered")
 	}
 
-	if _, err := BuildIR(spec); err == nil {
-		t.Fatalf("expected scatter validation error")
+	// Без output_type ассистент возвращает строку
+	critic := ir.Assistants["critic"]
+	if critic.OutputTypeName != "string" || len(critic.DependsOn) != 1 {
+		t.Fatalf("unexpected critic: %+v", critic)
 	}
 }
 
 func TestBuildIRReturnsMultiError(t *testing.T) {
 	spec := &Spec{
 		Assistants: map[string]AssistantSpec{
-			"writer": {Resolved: AssistantResolution{OutputSchemaPath: "/tmp/output.json"}},
-		},
-		Workflows: map[string]WorkflowSpec{
-			"novel": {
-				DAG: []WorkflowDAG{
-					{Step: "draft", Assistant: "writer"},
-					{Step: "draft", Assistant: "writer"},
-					{Step: "review", Assistant: "missing"},
-				},
-			},
+			"writer": {Use: "coretest", Model: "gpt-4", OutputType: "$Missing"},
+			"critic": {Use: "coretest", Model: "gpt-4", Kind: "vision"},
 		},
 	}
 
@@ -138,6 +63,12 @@ func TestBuildIRReturnsMultiError(t *testing.T) {
 		t.Fatalf("expected MultiError, got %T", err)
 	}
 	if len(me.Errors) < 2 {
-		t.Fatalf("expected multiple errors, got %d", len(me.Errors))
+		t.Fatalf("expected multiple errors, got %v", me.Errors)
+	}
+}
+
+func TestBuildIRNilSpec(t *testing.T) {
+	if _, err := BuildIR(nil); err == nil {
+		t.Fatal("expected error for nil spec")
 	}
 }
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Виды ассистентов (поле kind:).
+const (
+	KindChat      = "chat"      // вызов модели с типизированным входом и выходом (по умолчанию)
+	KindEmbedding = "embedding" // векторизация текстов
+)
+
+// IsEmbedding сообщает, является ли ассистент агентом эмбеддингов.
+func (a IRAssistant) IsEmbedding() bool {
+	return a.Kind == KindEmbedding
+}
+
+// validateKind проверяет kind: ассистента и поля, несовместимые с агентом эмбеддингов.
+func validateKind(assistant string, as AssistantSpec) []*ValidationError {
+	field := func(name string) string { return fmt.Sprintf("assistants.%s.%s", assistant, name) }
+
+	switch as.Kind {
+	case "", KindChat:
+		if as.Dimensions != 0 {
+			return []*ValidationError{{Code: CodeKindMismatch, Field: field("dimensions"), Msg: "dimensions is only allowed for kind: embedding"}}
+		}
+		return nil
+	case KindEmbedding:
+	default:
+		return []*ValidationError{{
+			Code:  CodeUnknownKind,
+			Field: field("kind"),
+			Msg:   fmt.Sprintf("unknown kind %q (supported: %s, %s)", as.Kind, KindChat, KindEmbedding),
+		}}
+	}
+
+	var errs []*ValidationError
+	unsupported := func(name string, set bool) {
+		if set {
+			errs = append(errs, &ValidationError{Code: CodeKindMismatch, Field: field(name), Msg: name + " is not supported for kind: embedding"})
+		}
+	}
+	unsupported("input_type", as.InputType != "")
+	unsupported("output_type", as.OutputType != "")
+	unsupported("system_prompt", as.SystemPrompt != "")
+	unsupported("thread", as.Thread != nil)
+	unsupported("dialog", as.Dialog != nil)
+	if as.Dimensions < 0 {
+		errs = append(errs, &ValidationError{Code: CodeKindMismatch, Field: field("dimensions"), Msg: "dimensions must be positive"})
+	}
+	return errs
+}
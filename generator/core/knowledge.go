@@ -0,0 +1,156 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf/knowledge"
+)
+
+// buildKnowledge проверяет раздел knowledge: и переводит его в IR.
+// Относительные пути считаются от каталога файла спецификации; для
+// спецификации, загруженной из файла, проверяется, что они существуют.
+func buildKnowledge(spec *Spec) (map[string]IRKnowledge, []*ValidationError) {
+	names := sortedKeys(spec.Knowledge)
+	dir := ""
+	if spec.Resolved.Path != "" {
+		dir = filepath.Dir(spec.Resolved.Path)
+	}
+
+	out := make(map[string]IRKnowledge, len(spec.Knowledge))
+	var errs []*ValidationError
+	for _, name := range names {
+		ks := spec.Knowledge[name]
+		field := fmt.Sprintf("knowledge.%s", name)
+		fail := func(suffix, msg string) {
+			errs = append(errs, &ValidationError{Code: CodeInvalidKnowledge, Field: field + suffix, Msg: msg})
+		}
+
+		if len(ks.Paths) == 0 {
+			fail(".paths", "at least one path is required")
+		}
+		for i, p := range ks.Paths {
+			switch {
+			case p == "":
+				fail(fmt.Sprintf(".paths[%d]", i), "path must not be empty")
+			case dir != "":
+				path := p
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if _, err := os.Stat(path); os.IsNotExist(err) {
+					fail(fmt.Sprintf(".paths[%d]", i), fmt.Sprintf("path %q does not exist (resolved to %s)", p, path))
+				} else if err != nil {
+					fail(fmt.Sprintf(".paths[%d]", i), err.Error())
+				}
+			}
+		}
+		for i, pattern := range ks.Include {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				fail(fmt.Sprintf(".include[%d]", i), fmt.Sprintf("invalid pattern %q", pattern))
+			}
+		}
+		if ks.ChunkSize < 0 {
+			fail(".chunk_size", "chunk_size must be positive")
+		}
+		if ks.ChunkOverlap != nil {
+			if *ks.ChunkOverlap < 0 {
+				fail(".chunk_overlap", "chunk_overlap must not be negative")
+			} else if *ks.ChunkOverlap >= chunkSize(ks.ChunkSize) {
+				fail(".chunk_overlap", "chunk_overlap must be less than chunk_size")
+			}
+		}
+		if ks.TopK < 0 {
+			fail(".top_k", "top_k must be positive")
+		}
+		if ks.Embedding != "" {
+			if as, ok := spec.Assistants[ks.Embedding]; !ok {
+				fail(".embedding", fmt.Sprintf("unknown assistant %q", ks.Embedding))
+			} else if as.Kind != KindEmbedding {
+				fail(".embedding", fmt.Sprintf("assistant %q must have kind: embedding", ks.Embedding))
+			}
+		}
+
+		out[name] = IRKnowledge{
+			Name:         name,
+			Paths:        cloneSlice(ks.Paths),
+			Include:      cloneSlice(ks.Include),
+			ChunkSize:    ks.ChunkSize,
+			ChunkOverlap: cloneInt(ks.ChunkOverlap),
+			TopK:         ks.TopK,
+			Embedding:    ks.Embedding,
+			Dir:          dir,
+		}
+	}
+	return out, errs
+}
+
+// validateAssistantKnowledge проверяет ссылки knowledge: ассистента.
+func validateAssistantKnowledge(spec *Spec, assistant string, as AssistantSpec) []*ValidationError {
+	if len(as.Knowledge) == 0 {
+		return nil
+	}
+	field := fmt.Sprintf("assistants.%s.knowledge", assistant)
+	if as.Kind == KindEmbedding {
+		return []*ValidationError{{Code: CodeKindMismatch, Field: field, Msg: "knowledge is not supported for kind: embedding"}}
+	}
+
+	var errs []*ValidationError
+	seen := make(map[string]bool)
+	for i, ref := range as.Knowledge {
+		switch {
+		case seen[ref]:
+			errs = append(errs, &ValidationError{Code: CodeUnknownKnowledge, Field: fmt.Sprintf("%s[%d]", field, i), Msg: fmt.Sprintf("duplicate knowledge source %q", ref)})
+		case !hasKnowledge(spec, ref):
+			errs = append(errs, &ValidationError{Code: CodeUnknownKnowledge, Field: fmt.Sprintf("%s[%d]", field, i), Msg: fmt.Sprintf("unknown knowledge source %q", ref)})
+		}
+		seen[ref] = true
+	}
+	return errs
+}
+
+func hasKnowledge(spec *Spec, name string) bool {
+	_, ok := spec.Knowledge[name]
+	return ok
+}
+
+// unusedKnowledge предупреждает об источниках, не подключённых ни к одному ассистенту.
+func unusedKnowledge(spec *Spec) []*ValidationWarning {
+	used := make(map[string]bool)
+	for _, as := range spec.Assistants {
+		for _, ref := range as.Knowledge {
+			used[ref] = true
+		}
+	}
+
+	var warnings []*ValidationWarning
+	for _, name := range sortedKeys(spec.Knowledge) {
+		if !used[name] {
+			warnings = append(warnings, &ValidationWarning{
+				Code:  CodeUnusedKnowledge,
+				Field: fmt.Sprintf("knowledge.%s", name),
+				Msg:   "knowledge source is not used by any assistant",
+			})
+		}
+	}
+	return warnings
+}
+
+func sortedKeys(m map[string]KnowledgeSpec) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// chunkSize возвращает длину фрагмента с учётом значения по умолчанию рантайма.
+func chunkSize(size int) int {
+	if size <= 0 {
+		return knowledge.DefaultChunkSize
+	}
+	return size
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func TestKnowledgeSection(t *testing.T) {
+	spec := &Spec{
+		Knowledge: map[string]KnowledgeSpec{
+			"docs":   {Paths: []string{"./docs"}, ChunkSize: 500, ChunkOverlap: aiwf.Int(0), TopK: 3},
+			"unused": {Paths: []string{"./other"}},
+		},
+		Assistants: map[string]AssistantSpec{
+			"support": {Use: "coretest", Model: "m", Knowledge: []string{"docs"}},
+		},
+	}
+
+	ir, err := BuildIR(spec)
+	merr, ok := err.(*MultiError)
+	if ir == nil || (err != nil && (!ok || merr.HasErrors())) {
+		t.Fatalf("BuildIR: %v", err)
+	}
+	if k := ir.Knowledge["docs"]; k.ChunkSize != 500 || k.TopK != 3 || len(k.Paths) != 1 {
+		t.Fatalf("unexpected knowledge IR: %+v", k)
+	}
+	// chunk_overlap: 0 отключает перекрытие, а незаданное значение остаётся nil
+	if k := ir.Knowledge["docs"]; k.ChunkOverlap == nil || *k.ChunkOverlap != 0 {
+		t.Fatalf("explicit zero overlap must be kept, got %v", k.ChunkOverlap)
+	}
+	if k := ir.Knowledge["unused"]; k.ChunkOverlap != nil {
+		t.Fatalf("unset overlap must stay nil, got %v", *k.ChunkOverlap)
+	}
+	if got := ir.Assistants["support"].Knowledge; len(got) != 1 || got[0] != "docs" {
+		t.Fatalf("unexpected assistant knowledge: %v", got)
+	}
+	if merr == nil || len(merr.Warnings) != 1 || merr.Warnings[0].Field != "knowledge.unused" {
+		t.Fatalf("expected warning for unused source, got %v", err)
+	}
+}
+
+func TestKnowledgeValidation(t *testing.T) {
+	spec := &Spec{
+		Knowledge: map[string]KnowledgeSpec{
+			"docs": {ChunkSize: 100, ChunkOverlap: aiwf.Int(100), Include: []string{"[md"}, Embedding: "writer"},
+		},
+		Assistants: map[string]AssistantSpec{
+			"writer": {Use: "coretest", Model: "m", Knowledge: []string{"docs", "faq"}},
+		},
+	}
+
+	_, err := BuildIR(spec)
+	merr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	var fields []string
+	for _, e := range merr.Errors {
+		fields = append(fields, e.Field)
+	}
+	joined := strings.Join(fields, " ")
+	for _, want := range []string{
+		"knowledge.docs.paths",
+		"knowledge.docs.include[0]",
+		"knowledge.docs.chunk_overlap",
+		"knowledge.docs.embedding",
+		"assistants.writer.knowledge[1]",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected error for %s, got %v", want, fields)
+		}
+	}
+}
+
+func TestKnowledgePathsResolveAgainstSpec(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	newSpec := func(paths ...string) *Spec {
+		spec := &Spec{
+			Knowledge: map[string]KnowledgeSpec{"docs": {Paths: paths}},
+			Assistants: map[string]AssistantSpec{
+				"support": {Use: "coretest", Model: "m", Knowledge: []string{"docs"}},
+			},
+		}
+		spec.Resolved.Path = filepath.Join(dir, "spec.yaml")
+		return spec
+	}
+
+	ir, err := BuildIR(newSpec("./docs"))
+	if err != nil {
+		t.Fatalf("BuildIR: %v", err)
+	}
+	if ir.Knowledge["docs"].Dir != dir {
+		t.Fatalf("expected spec directory %s, got %q", dir, ir.Knowledge["docs"].Dir)
+	}
+
+	_, err = BuildIR(newSpec("./docs", "./missing"))
+	merr, ok := err.(*MultiError)
+	if !ok || len(merr.Errors) != 1 || merr.Errors[0].Field != "knowledge.docs.paths[1]" {
+		t.Fatalf("expected error for missing path only, got %v", err)
+	}
+}
@@ -3,20 +3,43 @@ package core
 import (
 	"fmt"
 	"os"
-
-	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"reflect"
 )
 
 // LoadSpec loads and parses YAML specification from file
 func LoadSpec(path string) (*Spec, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		return nil, &ValidationError{
+			Code: CodeReadFailed,
+			Msg:  fmt.Sprintf("failed to read file %s: %v", path, err),
+			Pos:  Position{File: path},
+		}
 	}
 
+	return ParseSpec(path, data)
+}
+
+// ParseSpec разбирает спецификацию из памяти; path используется в позициях ошибок
+// и как база для относительных путей imports:.
+func ParseSpec(path string, data []byte) (*Spec, error) {
+	// Дерево узлов сохраняется, чтобы ошибки валидации указывали на строку и столбец
+	source, err := parseSource(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := source.checkKeys(reflect.TypeOf(Spec{})); err != nil {
+		return nil, err
+	}
 	var spec Spec
-	if err := yaml.Unmarshal(data, &spec); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := source.decode(&spec); err != nil {
+		return nil, err
+	}
+
+	spec.Resolved.Source = source
+	if abs, err := filepath.Abs(path); err == nil {
+		spec.Resolved.Path = abs
 	}
 
 	return &spec, nil
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+// requiredCapabilities возвращает возможности провайдера, нужные ассистенту.
+func requiredCapabilities(as AssistantSpec) []aiwf.Capability {
+	if as.Kind == KindEmbedding {
+		return []aiwf.Capability{aiwf.CapabilityEmbeddings}
+	}
+	var caps []aiwf.Capability
+	if as.OutputType != "" && as.OutputType != "string" {
+		caps = append(caps, aiwf.CapabilitySchema)
+	}
+	if as.Thread != nil {
+		caps = append(caps, aiwf.CapabilityThreads)
+	}
+	return caps
+}
+
+// validateProvider проверяет, что use: ассистента указывает на встроенного или
+// зарегистрированного провайдера с нужными возможностями.
+func validateProvider(assistant string, as AssistantSpec) []*ValidationError {
+	if as.Use == "" {
+		return nil
+	}
+
+	field := fmt.Sprintf("assistants.%s.use", assistant)
+	info, ok := LookupProvider(as.Use)
+	if !ok {
+		return []*ValidationError{{
+			Code:  CodeUnknownProvider,
+			Field: field,
+			Msg:   fmt.Sprintf("unknown provider %q (supported: %s)", as.Use, strings.Join(ProviderNames(), ", ")),
+		}}
+	}
+
+	var errs []*ValidationError
+	for _, c := range requiredCapabilities(as) {
+		if !info.Supports(c) {
+			errs = append(errs, &ValidationError{
+				Code:  CodeUnsupportedCapability,
+				Field: field,
+				Msg:   fmt.Sprintf("provider %q does not support %s", as.Use, c),
+			})
+		}
+	}
+	return errs
+}
+
+// validatePromptCache предупреждает, если провайдер ассистента не поддерживает
+// кэширование промпта: запрос выполнится, но без кэша.
+func validatePromptCache(assistant string, as AssistantSpec) *ValidationWarning {
+	if !as.PromptCache || as.Use == "" {
+		return nil
+	}
+	info, ok := LookupProvider(as.Use)
+	if !ok || info.Supports(aiwf.CapabilityPromptCache) {
+		return nil
+	}
+	return &ValidationWarning{
+		Code:  CodeIgnoredOption,
+		Field: fmt.Sprintf("assistants.%s.prompt_cache", assistant),
+		Msg:   fmt.Sprintf("provider %q does not support prompt caching; option is ignored", as.Use),
+	}
+}
+
+// buildProvider проверяет запись раздела providers: и переводит её в IR.
+func buildProvider(name string, ps ProviderSpec) (IRProvider, []*ValidationError) {
+	field := fmt.Sprintf("providers.%s", name)
+	provider := IRProvider{
+		Name:      name,
+		Type:      ps.Type,
+		BaseURL:   ps.BaseURL,
+		APIKeyEnv: ps.APIKeyEnv,
+		Headers:   ps.Headers,
+		Org:       ps.Org,
+		Mode:      ps.Mode,
+
+		NumCtx:      ps.NumCtx,
+		KeepAlive:   ps.KeepAlive,
+		PullMissing: ps.PullMissing,
+
+		Deployments: ps.Deployments,
+	}
+
+	var errs []*ValidationError
+	if ps.Type == "" {
+		errs = append(errs, &ValidationError{Code: CodeInvalidProvider, Field: field + ".type", Msg: "provider type is required"})
+	} else if _, ok := LookupProvider(ps.Type); !ok {
+		errs = append(errs, &ValidationError{
+			Code:  CodeUnknownProvider,
+			Field: field + ".type",
+			Msg:   fmt.Sprintf("unknown provider %q (supported: %s)", ps.Type, strings.Join(ProviderNames(), ", ")),
+		})
+	}
+
+	if ps.Mode != "" && ps.Mode != "responses" && ps.Mode != "chat_completions" {
+		errs = append(errs, &ValidationError{
+			Code:  CodeInvalidProvider,
+			Field: field + ".mode",
+			Msg:   fmt.Sprintf("unknown mode %q (supported: responses, chat_completions)", ps.Mode),
+		})
+	}
+
+	if ps.NumCtx < 0 {
+		errs = append(errs, &ValidationError{
+			Code:  CodeInvalidProvider,
+			Field: field + ".num_ctx",
+			Msg:   fmt.Sprintf("num_ctx must be non-negative, got %d", ps.NumCtx),
+		})
+	}
+
+	if ps.Timeout != "" {
+		timeout, err := time.ParseDuration(ps.Timeout)
+		if err != nil || timeout <= 0 {
+			errs = append(errs, &ValidationError{
+				Code:  CodeInvalidProvider,
+				Field: field + ".timeout",
+				Msg:   fmt.Sprintf("invalid timeout %q: expected a positive duration like 30s", ps.Timeout),
+			})
+		}
+		provider.Timeout = timeout
+	}
+	return provider, errs
+}
+
+// validateModels проверяет алиасы раздела models:.
+func validateModels(spec *Spec) []*ValidationError {
+	names := make([]string, 0, len(spec.Models))
+	for name := range spec.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []*ValidationError
+	for _, name := range names {
+		m := spec.Models[name]
+		field := fmt.Sprintf("models.%s", name)
+		if m.Model == "" {
+			errs = append(errs, &ValidationError{Code: CodeInvalidModel, Field: field + ".model", Msg: "model is required"})
+		}
+		if m.Provider != "" && !isProviderRef(spec, m.Provider) {
+			errs = append(errs, &ValidationError{
+				Code:  CodeUnknownProvider,
+				Field: field + ".provider",
+				Msg:   fmt.Sprintf("unknown provider %q: not declared in providers: and not registered", m.Provider),
+			})
+		}
+	}
+	return errs
+}
+
+// isProviderRef сообщает, указывает ли ref на запись providers: или зарегистрированного провайдера.
+func isProviderRef(spec *Spec, ref string) bool {
+	if _, ok := spec.Providers[ref]; ok {
+		return true
+	}
+	_, ok := LookupProvider(ref)
+	return ok
+}
+
+// resolveAssistantProvider раскрывает алиас модели и provider: ассистента.
+// Возвращает спецификацию с итоговыми Use и Model и имя провайдера для маршрутизации.
+func resolveAssistantProvider(spec *Spec, name string, as AssistantSpec) (AssistantSpec, string, []*ValidationError) {
+	ref := as.Provider
+	if alias, ok := spec.Models[as.Model]; ok {
+		as.Model = alias.Model
+		if ref == "" {
+			ref = alias.Provider
+		}
+	}
+	if ref == "" {
+		return as, as.Use, nil
+	}
+
+	field := fmt.Sprintf("assistants.%s.provider", name)
+	typ := ref
+	if entry, ok := spec.Providers[ref]; ok {
+		typ = entry.Type
+	} else if _, ok := LookupProvider(ref); !ok {
+		return as, as.Use, []*ValidationError{{
+			Code:  CodeUnknownProvider,
+			Field: field,
+			Msg:   fmt.Sprintf("unknown provider %q: not declared in providers:", ref),
+		}}
+	}
+
+	if as.Use != "" && as.Use != typ {
+		return as, as.Use, []*ValidationError{{
+			Code:  CodeInvalidProvider,
+			Field: field,
+			Msg:   fmt.Sprintf("provider %q has type %q, but use is %q", ref, typ, as.Use),
+		}}
+	}
+	as.Use = typ
+	return as, ref, nil
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "coretest",
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema},
+		New: func(aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+			return nil, aiwf.ErrProviderNotConfigured
+		},
+	})
+}
+
+func TestValidateProvider(t *testing.T) {
+	for _, as := range []AssistantSpec{{}, {Use: "coretest"}, {Use: "coretest", OutputType: "Answer"}} {
+		if errs := validateProvider("writer", as); len(errs) != 0 {
+			t.Errorf("use %q: unexpected errors %v", as.Use, errs)
+		}
+	}
+
+	errs := validateProvider("writer", AssistantSpec{Use: "coretset"})
+	if len(errs) != 1 {
+		t.Fatalf("expected error for unknown provider, got %v", errs)
+	}
+	if errs[0].Field != "assistants.writer.use" || !strings.Contains(errs[0].Msg, "coretest") {
+		t.Errorf("unexpected error: %+v", errs[0])
+	}
+}
+
+func TestValidateProviderCapabilities(t *testing.T) {
+	errs := validateProvider("writer", AssistantSpec{Use: "coretest", Thread: &ThreadBindingSpec{Use: "main"}})
+	if len(errs) != 1 || !strings.Contains(errs[0].Msg, "threads") {
+		t.Fatalf("expected missing threads capability, got %v", errs)
+	}
+}
+
+func TestBuiltinProvidersWithoutRegistry(t *testing.T) {
+	// В тестах core реестр aiwf не содержит встроенных провайдеров: providers/all не импортирован
+	spec := &Spec{
+		Providers: map[string]ProviderSpec{"eu": {Type: "azure"}},
+		Assistants: map[string]AssistantSpec{
+			"writer": {Use: "openai", Model: "gpt-4o", OutputType: "Answer", Generation: Generation{Seed: new(int)}},
+			"critic": {Provider: "eu", Model: "gpt-4o"},
+		},
+	}
+	if errs := validateProvider("writer", spec.Assistants["writer"]); len(errs) != 0 {
+		t.Fatalf("unexpected errors for builtin provider: %v", errs)
+	}
+	if errs := validateProvider("critic", AssistantSpec{Use: "anthropic", Thread: &ThreadBindingSpec{Use: "default"}}); len(errs) != 1 {
+		t.Fatalf("expected missing threads capability for anthropic, got %v", errs)
+	}
+	if _, errs := buildProvider("eu", spec.Providers["eu"]); len(errs) != 0 {
+		t.Fatalf("unexpected errors for builtin provider type: %v", errs)
+	}
+	if !isProviderRef(spec, "grok") {
+		t.Fatalf("builtin provider must be a valid provider reference")
+	}
+	if warns := ignoredGeneration("writer", spec.Assistants["writer"], ""); len(warns) != 1 || warns[0].Field != "assistants.writer.seed" {
+		t.Fatalf("expected ignored seed for openai responses, got %v", warns)
+	}
+	if names := ProviderNames(); !strings.Contains(strings.Join(names, ","), "ollama") {
+		t.Fatalf("expected builtin providers in names, got %v", names)
+	}
+}
+
+func TestProvidersAndModelAliases(t *testing.T) {
+	spec := &Spec{
+		Providers: map[string]ProviderSpec{
+			"eu":    {Type: "coretest", BaseURL: "https://eu.example.com/v1", APIKeyEnv: "EU_KEY", Timeout: "30s", Mode: "chat_completions"},
+			"local": {Type: "coretest", NumCtx: 8192, KeepAlive: "10m", PullMissing: true},
+			"az":    {Type: "coretest", Deployments: map[string]string{"gpt-4o": "prod-gpt4o"}},
+		},
+		Models: map[string]ModelSpec{
+			"fast": {Provider: "eu", Model: "small-1"},
+		},
+		Assistants: map[string]AssistantSpec{
+			"writer": {Model: "fast"},
+			"critic": {Provider: "eu", Model: "large-1"},
+			"plain":  {Use: "coretest", Model: "large-1"},
+		},
+	}
+
+	ir, err := BuildIR(spec)
+	if err != nil {
+		t.Fatalf("BuildIR: %v", err)
+	}
+
+	if p := ir.Providers["eu"]; p.Type != "coretest" || p.Timeout != 30*time.Second || p.APIKeyEnv != "EU_KEY" || p.Mode != "chat_completions" {
+		t.Fatalf("unexpected provider: %+v", p)
+	}
+	if p := ir.Providers["local"]; p.NumCtx != 8192 || p.KeepAlive != "10m" || !p.PullMissing {
+		t.Fatalf("unexpected local provider: %+v", p)
+	}
+	if p := ir.Providers["az"]; p.Deployments["gpt-4o"] != "prod-gpt4o" {
+		t.Fatalf("unexpected azure provider: %+v", p)
+	}
+	for name, want := range map[string][3]string{
+		"writer": {"eu", "coretest", "small-1"},
+		"critic": {"eu", "coretest", "large-1"},
+		"plain":  {"coretest", "coretest", "large-1"},
+	} {
+		as := ir.Assistants[name]
+		if got := [3]string{as.Provider, as.Use, as.Model}; got != want {
+			t.Errorf("%s: got provider/use/model %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestProvidersValidation(t *testing.T) {
+	spec := &Spec{
+		Providers: map[string]ProviderSpec{
+			"eu": {Type: "coretest", Timeout: "soon", Mode: "assistants", NumCtx: -1},
+		},
+		Models: map[string]ModelSpec{
+			"fast": {Provider: "missing"},
+		},
+		Assistants: map[string]AssistantSpec{
+			"writer": {Provider: "nowhere", Model: "x"},
+		},
+	}
+
+	_, err := BuildIR(spec)
+	merr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, verr := range merr.Errors {
+		fields[verr.Field] = true
+	}
+	for _, field := range []string{"providers.eu.timeout", "providers.eu.mode", "providers.eu.num_ctx", "models.fast.model", "models.fast.provider", "assistants.writer.provider"} {
+		if !fields[field] {
+			t.Errorf("expected error for %s, got %v", field, merr)
+		}
+	}
+}
+
+func TestValidatePromptCache(t *testing.T) {
+	if warn := validatePromptCache("writer", AssistantSpec{Use: "coretest"}); warn != nil {
+		t.Fatalf("unexpected warning without prompt_cache: %+v", warn)
+	}
+	warn := validatePromptCache("writer", AssistantSpec{Use: "coretest", PromptCache: true})
+	if warn == nil || warn.Field != "assistants.writer.prompt_cache" {
+		t.Fatalf("expected prompt_cache warning, got %+v", warn)
+	}
+}
+
+func TestEmbeddingKind(t *testing.T) {
+	spec := &Spec{
+		Assistants: map[string]AssistantSpec{
+			"search": {Kind: KindEmbedding, Use: "coretest", Model: "embed-1", Dimensions: 256},
+		},
+	}
+	_, err := BuildIR(spec)
+	if err == nil || !strings.Contains(err.Error(), "does not support embeddings") {
+		t.Fatalf("expected missing embeddings capability, got %v", err)
+	}
+
+	errs := validateKind("search", AssistantSpec{Kind: KindEmbedding, OutputType: "Answer", Thread: &ThreadBindingSpec{Use: "main"}})
+	if len(errs) != 2 {
+		t.Fatalf("expected output_type and thread errors, got %v", errs)
+	}
+	if errs := validateKind("writer", AssistantSpec{Kind: "vector"}); len(errs) != 1 || errs[0].Field != "assistants.writer.kind" {
+		t.Fatalf("expected unknown kind error, got %v", errs)
+	}
+	if errs := validateKind("writer", AssistantSpec{Dimensions: 8}); len(errs) != 1 {
+		t.Fatalf("expected dimensions error for chat kind, got %v", errs)
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RecursiveTypes возвращает имена типов реестра, которые через ссылки достигают сами себя.
+func (r *TypeRegistry) RecursiveTypes() map[string]bool {
+	names := make(map[*TypeDef]string, len(r.Types))
+	for name, td := range r.Types {
+		names[td] = name
+	}
+
+	recursive := make(map[string]bool)
+	for name, td := range r.Types {
+		seen := make(map[*TypeDef]bool)
+		stack := []*TypeDef{td}
+		for len(stack) > 0 && !recursive[name] {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, ref := range typeRefs(current) {
+				target, err := r.Resolve(ref)
+				if err != nil || names[target] == "" {
+					continue
+				}
+				if target == td {
+					recursive[name] = true
+					break
+				}
+				if !seen[target] {
+					seen[target] = true
+					stack = append(stack, target)
+				}
+			}
+		}
+	}
+	return recursive
+}
+
+// typeRefs собирает ссылки внутри типа, не переходя по ним.
+func typeRefs(td *TypeDef) []string {
+	if td == nil {
+		return nil
+	}
+	switch td.Kind {
+	case KindRef:
+		return []string{td.Ref}
+	case KindArray:
+		return typeRefs(td.Items)
+	case KindMap:
+		return typeRefs(td.ValueType)
+	case KindOneOf:
+		var refs []string
+		for _, variant := range td.Variants {
+			refs = append(refs, typeRefs(variant)...)
+		}
+		return refs
+	case KindObject:
+		var refs []string
+		for _, prop := range td.Properties {
+			refs = append(refs, typeRefs(prop)...)
+		}
+		return refs
+	}
+	return nil
+}
+
+// checkRecursion сообщает о рекурсивных типах без конечного значения: цикл идёт только
+// через обязательные поля и ссылки, и любое значение типа снова содержит его самого.
+// Возвращает false, если такие типы есть: проверять значения по ним нельзя.
+func checkRecursion(registry *TypeRegistry, merr *MultiError) bool {
+	known := make(map[*TypeDef]bool, len(registry.Types))
+	for _, td := range registry.Types {
+		known[td] = true
+	}
+
+	// Неподвижная точка: тип конечен, если его значение можно собрать из уже конечных
+	finite := make(map[*TypeDef]bool, len(registry.Types))
+	for changed := true; changed; {
+		changed = false
+		for _, td := range registry.Types {
+			if !finite[td] && hasFiniteValue(td, registry, known, finite) {
+				finite[td] = true
+				changed = true
+			}
+		}
+	}
+
+	recursive := registry.RecursiveTypes()
+	names := make([]string, 0, len(recursive))
+	for name := range recursive {
+		if !finite[registry.Types[name]] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		merr.Append(&ValidationError{
+			Code:  CodeRecursiveType,
+			Field: "types." + name,
+			Msg:   fmt.Sprintf("recursive type %s has no finite value: make a field on the cycle optional or a list", name),
+		})
+	}
+	return len(names) == 0
+}
+
+func hasFiniteValue(td *TypeDef, registry *TypeRegistry, known, finite map[*TypeDef]bool) bool {
+	switch td.Kind {
+	case KindRef:
+		target, err := registry.Resolve(td.Ref)
+		if err != nil || !known[target] {
+			return true
+		}
+		return finite[target]
+	case KindArray:
+		if td.MinItems == nil || *td.MinItems == 0 {
+			return true
+		}
+		return hasFiniteValue(td.Items, registry, known, finite)
+	case KindOneOf:
+		for _, variant := range td.Variants {
+			if hasFiniteValue(variant, registry, known, finite) {
+				return true
+			}
+		}
+		return false
+	case KindObject:
+		for _, prop := range td.Properties {
+			if !prop.Optional && !hasFiniteValue(prop, registry, known, finite) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRecursiveTypes(t *testing.T) {
+	reg, err := NewTypeParser().ParseTypes(map[string]any{
+		"Comment": map[string]any{
+			"text":    "string",
+			"replies": "$Comment[]",
+		},
+		"Leaf":   map[string]any{"kind": "enum(leaf)"},
+		"Branch": map[string]any{"kind": "enum(branch)", "children": "$Tree[]"},
+		"Tree":   "oneOf($Leaf, $Branch, discriminator:kind)",
+		"Post":   map[string]any{"comments": "$Comment[]"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for name := range reg.RecursiveTypes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// Post ссылается на рекурсивный тип, но сам себя не достигает
+	if want := []string{"Branch", "Comment", "Tree"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+}
+
+func TestCheckRecursion(t *testing.T) {
+	spec := &Spec{Types: map[string]any{
+		"Node":  map[string]any{"next": "$Node"},
+		"A":     "$B",
+		"B":     "$A",
+		"Chain": map[string]any{"items": "$Chain[](min:1)"},
+		// Цикл прерывается необязательным полем или пустым списком
+		"Comment":  map[string]any{"parent?": "$Comment", "replies": "$Comment[]"},
+		"Category": map[string]any{"children": "map(string, $Category)"},
+	}}
+
+	err := ResolveSpec(spec)
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	var fields []string
+	for _, e := range merr.Errors {
+		if e.Code != CodeRecursiveType {
+			t.Fatalf("unexpected error %+v", e)
+		}
+		fields = append(fields, e.Field)
+	}
+	if want := []string{"types.A", "types.B", "types.Chain", "types.Node"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("expected errors for %v, got %v", want, fields)
+	}
+}
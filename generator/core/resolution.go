@@ -5,42 +5,54 @@ import (
 	"strings"
 )
 
-// ResolveSpec resolves all types and references in a spec
+// ResolveSpec resolves all types and references in a spec.
+// All problems are collected into *MultiError with field paths instead of
+// stopping at the first one.
 func ResolveSpec(spec *Spec) error {
 	if spec == nil {
 		return fmt.Errorf("spec is nil")
 	}
 
-	// Initialize resolution if needed
-	if spec.Resolved.TypeRegistry == nil {
-		spec.Resolved.TypeRegistry = &TypeRegistry{
-			Types: make(map[string]*TypeDef),
-		}
-	}
+	merr := &MultiError{}
 
 	// Parse all types using TypeParser
 	parser := NewTypeParser()
 	registry, err := parser.ParseTypes(spec.Types)
-	if err != nil {
-		return fmt.Errorf("failed to parse types: %w", err)
-	}
+	merr.Append(err)
 	spec.Resolved.TypeRegistry = registry
+	registry.Formats = resolveFormats(spec.Formats, merr)
+
+	// Inline-выражения input_type/output_type становятся именованными типами
+	registerInlineTypes(spec, registry, merr)
+
+	// Load imported modules and qualify $module.Type references
+	merr.Append(resolveImports(spec, registry))
 
 	// Resolve references in types
-	for _, td := range spec.Resolved.TypeRegistry.Types {
-		if err := resolveTypeRefs(td, spec.Resolved.TypeRegistry); err != nil {
-			return err
+	for name, td := range registry.Types {
+		checkTypeRefs("types."+name, td, registry, merr)
+	}
+
+	// Рекурсия допустима, только если цикл можно прервать; значения default/examples
+	// проверяются, когда бесконечных циклов нет
+	if checkRecursion(registry, merr) {
+		for name, td := range registry.Types {
+			checkTypeValues("types."+name, td, registry, merr)
 		}
 	}
 
 	// Resolve assistant input/output types
 	for name, assistant := range spec.Assistants {
+		field := "assistants." + name
+
 		// Resolve input type
 		if assistant.InputType != "" {
-			inputType, err := resolveTypeByName(assistant.InputType, spec.Resolved.TypeRegistry)
+			inputType, err := resolveTypeByName(assistant.InputType, registry)
 			if err != nil {
-				return fmt.Errorf("assistant %s: failed to resolve input type %s: %w",
-					name, assistant.InputType, err)
+				merr.Append(assistantTypeError(field+".input_type", err))
+			}
+			if inputType != nil && inputType.Kind == KindRef {
+				checkTypeRefs(field+".input_type", inputType, registry, merr)
 			}
 			assistant.Resolved.InputType = inputType
 		}
@@ -50,22 +62,31 @@ func ResolveSpec(spec *Spec) error {
 		if outputTypeName == "" {
 			outputTypeName = "string"
 		}
-		outputType, err := resolveTypeByName(outputTypeName, spec.Resolved.TypeRegistry)
+		outputType, err := resolveTypeByName(outputTypeName, registry)
 		if err != nil {
-			return fmt.Errorf("assistant %s: failed to resolve output type %s: %w",
-				name, outputTypeName, err)
+			merr.Append(assistantTypeError(field+".output_type", err))
+		}
+		if outputType != nil && outputType.Kind == KindRef {
+			checkTypeRefs(field+".output_type", outputType, registry, merr)
 		}
 		assistant.Resolved.OutputType = outputType
 
 		// Validate dialog configuration
 		if assistant.Dialog != nil && assistant.Thread == nil {
-			return fmt.Errorf("assistant %s: dialog mode requires thread configuration (add 'thread' field)", name)
+			merr.Append(&ValidationError{
+				Code:  CodeDialogWithoutThread,
+				Field: field + ".dialog",
+				Msg:   "dialog mode requires thread configuration (add 'thread' field)",
+			})
 		}
 
 		// Update the assistant in the map with resolved types
 		spec.Assistants[name] = assistant
 	}
 
+	if merr.HasErrors() {
+		return merr
+	}
 	return nil
 }
 
@@ -85,43 +106,117 @@ func resolveTypeByName(typeName string, registry *TypeRegistry) (*TypeDef, error
 	return td, nil
 }
 
-// resolveTypeRefs resolves all references within a type
-func resolveTypeRefs(td *TypeDef, registry *TypeRegistry) error {
+// registerInlineTypes регистрирует inline-выражения input_type/output_type как типы
+// <Assistant>Input и <Assistant>Output, чтобы генераторы обращались с ними так же,
+// как с объявленными в types. Ссылка $Type заменяется на имя типа; output_type: string
+// остаётся строкой.
+func registerInlineTypes(spec *Spec, registry *TypeRegistry, merr *MultiError) {
+	for name, assistant := range spec.Assistants {
+		fields := []struct {
+			key, suffix string
+			value       *string
+		}{
+			{"input_type", "Input", &assistant.InputType},
+			{"output_type", "Output", &assistant.OutputType},
+		}
+		for _, f := range fields {
+			expr := *f.value
+			if expr == "" || (f.key == "output_type" && expr == "string") || isQualifiedRef(expr) {
+				continue
+			}
+			if _, ok := registry.Types[expr]; ok {
+				continue
+			}
+			td, err := ParseTypeExpression(expr)
+			if err != nil {
+				// Ошибку с позицией сообщит разрешение типов ассистента
+				continue
+			}
+			if td.Kind == KindRef {
+				*f.value = strings.TrimPrefix(td.Ref, "$")
+				continue
+			}
+
+			typeName := inlineTypeName(name) + f.suffix
+			if _, clash := registry.Types[typeName]; clash {
+				merr.Append(&ValidationError{
+					Code:  CodeTypeNameClash,
+					Field: fmt.Sprintf("assistants.%s.%s", name, f.key),
+					Msg:   fmt.Sprintf("inline type %s conflicts with a declared type of the same name", typeName),
+				})
+				continue
+			}
+			td.Name = typeName
+			registry.Types[typeName] = td
+			*f.value = typeName
+		}
+		spec.Assistants[name] = assistant
+	}
+}
+
+// inlineTypeName переводит имя ассистента в PascalCase: data_analyst -> DataAnalyst.
+func inlineTypeName(assistant string) string {
+	parts := strings.FieldsFunc(assistant, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	})
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func assistantTypeError(field string, err error) *ValidationError {
+	verr := typeError(field, err)
+	verr.Code = CodeUnknownType
+	return verr
+}
+
+// checkTypeRefs проверяет, что все ссылки внутри типа определены; field — путь типа в спецификации.
+func checkTypeRefs(field string, td *TypeDef, registry *TypeRegistry, merr *MultiError) {
 	if td == nil {
-		return nil
+		return
 	}
 
 	switch td.Kind {
+	case KindString:
+		checkFormat(field, td, registry, merr)
+
 	case KindRef:
 		// Validate that reference exists
+		// Ссылки module.Type, оставшиеся после resolveImports, уже получили ошибку там,
+		// как и ссылки на объявленные типы, которые не удалось разобрать
 		refName := strings.TrimPrefix(td.Ref, "$")
-		if refName != "" {
+		if refName != "" && !strings.Contains(refName, ".") && !registry.invalid[refName] {
 			if _, ok := registry.Types[refName]; !ok {
-				return fmt.Errorf("reference to undefined type: %s", td.Ref)
+				merr.Append(&ValidationError{Code: CodeUndefinedReference, Field: field, Msg: fmt.Sprintf("reference to undefined type: %s", td.Ref)})
 			}
 		}
 
 	case KindArray:
-		if td.Items != nil {
-			if err := resolveTypeRefs(td.Items, registry); err != nil {
-				return err
-			}
-		}
+		checkTypeRefs(field, td.Items, registry, merr)
 
 	case KindMap:
-		if td.ValueType != nil {
-			if err := resolveTypeRefs(td.ValueType, registry); err != nil {
-				return err
-			}
+		checkTypeRefs(field, td.ValueType, registry, merr)
+
+	case KindOneOf:
+		before := len(merr.Errors)
+		for _, variant := range td.Variants {
+			checkTypeRefs(field, variant, registry, merr)
+		}
+		if len(merr.Errors) == before {
+			checkUnion(field, td, registry, merr)
 		}
 
 	case KindObject:
 		for fieldName, fieldType := range td.Properties {
-			if err := resolveTypeRefs(fieldType, registry); err != nil {
-				return fmt.Errorf("field %s: %w", fieldName, err)
-			}
+			checkTypeRefs(joinField(field, fieldName), fieldType, registry, merr)
 		}
 	}
+}
 
-	return nil
-}
\ No newline at end of file
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
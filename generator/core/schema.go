@@ -0,0 +1,649 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaDialect определяет вариант JSON Schema, который ожидает потребитель схемы.
+type SchemaDialect string
+
+const (
+	// DialectDraft2020 — обычная JSON Schema draft 2020-12; используется в TypeMetadata.
+	DialectDraft2020 SchemaDialect = "draft2020-12"
+	// DialectOpenAIStrict — strict structured outputs OpenAI: все поля обязательны,
+	// опциональные поля представлены как nullable.
+	DialectOpenAIStrict SchemaDialect = "openai-strict"
+	// DialectAnthropicTool — input_schema инструмента Anthropic Messages API.
+	DialectAnthropicTool SchemaDialect = "anthropic-tool"
+	// DialectGrok — response_format json_schema в xAI Chat API.
+	DialectGrok SchemaDialect = "grok"
+	// DialectGemini — responseSchema Gemini API (подмножество OpenAPI 3.0).
+	DialectGemini SchemaDialect = "gemini"
+)
+
+const draft2020SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// grokUnsupportedKeywords перечисляет ключевые слова, которые xAI отклоняет в структурных ответах.
+var grokUnsupportedKeywords = []string{"minLength", "maxLength", "pattern", "minItems", "maxItems", "format"}
+
+// geminiUnsupportedKeywords перечисляет ключевые слова, которых нет в Schema Gemini API.
+var geminiUnsupportedKeywords = []string{"additionalProperties", "$schema", "$defs", "$ref", "pattern", "default", "examples"}
+
+// openAIStrictUnsupportedKeywords перечисляет ключевые слова, которые strict-режим OpenAI отклоняет.
+var openAIStrictUnsupportedKeywords = []string{"default", "examples"}
+
+// SchemaCompiler компилирует TypeDef в JSON Schema выбранного диалекта.
+// Ссылки разрешаются через реестр и встраиваются в схему; рекурсивные типы
+// выносятся в $defs и подключаются через $ref.
+type SchemaCompiler struct {
+	registry *TypeRegistry
+	dialect  SchemaDialect
+}
+
+// NewSchemaCompiler создаёт компилятор; registry может быть nil только для типов
+// без ссылок: разрешить ссылку без реестра нельзя.
+func NewSchemaCompiler(registry *TypeRegistry, dialect SchemaDialect) *SchemaCompiler {
+	if dialect == "" {
+		dialect = DialectDraft2020
+	}
+	return &SchemaCompiler{registry: registry, dialect: dialect}
+}
+
+// Compile возвращает схему типа в виде map.
+func (c *SchemaCompiler) Compile(td *TypeDef) (map[string]any, error) {
+	if td == nil {
+		return nil, fmt.Errorf("schema: type is nil")
+	}
+	st := &compileState{visiting: map[*TypeDef]bool{}, defs: map[string]any{}}
+	if c.registry != nil {
+		st.recursive = c.registry.RecursiveTypes()
+		// Корень схемы должен быть самим типом, а не $ref на него
+		if td.Kind == KindRef && st.recursive[refTypeName(td.Ref)] {
+			if target, err := c.registry.Resolve(td.Ref); err == nil {
+				td = target
+			}
+		}
+		// Ссылки на корневой рекурсивный тип указывают на корень: "$ref": "#"
+		for name, t := range c.registry.Types {
+			if t == td && st.recursive[name] {
+				st.root = name
+			}
+		}
+	}
+	schema, err := c.compile(td, st)
+	if err != nil {
+		return nil, err
+	}
+	if len(st.defs) > 0 {
+		if c.dialect == DialectGemini {
+			return nil, fmt.Errorf("schema: Gemini does not support recursive types")
+		}
+		schema["$defs"] = st.defs
+	}
+	return AdaptSchema(schema, c.dialect), nil
+}
+
+// compileState — состояние одной компиляции: типы на текущем пути встраивания
+// и вынесенные в $defs рекурсивные типы.
+type compileState struct {
+	visiting  map[*TypeDef]bool
+	recursive map[string]bool
+	root      string // имя корневого типа, если он рекурсивный
+	defs      map[string]any
+}
+
+// CompileJSON возвращает схему типа в виде JSON.
+func (c *SchemaCompiler) CompileJSON(td *TypeDef) (json.RawMessage, error) {
+	schema, err := c.Compile(td)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("schema: marshal: %w", err)
+	}
+	return data, nil
+}
+
+// SchemaFromMetadata приводит TypeMetadata из ModelCall к схеме нужного диалекта.
+// Поддерживаются *TypeDef, TypeDef, map[string]any и json.RawMessage; TypeDef не должен
+// ссылаться на другие типы — для ссылок нужен реестр, см. SchemaCompiler.
+func SchemaFromMetadata(metadata any, dialect SchemaDialect) (json.RawMessage, error) {
+	var schema map[string]any
+
+	switch v := metadata.(type) {
+	case *TypeDef:
+		return NewSchemaCompiler(nil, dialect).CompileJSON(v)
+	case TypeDef:
+		return NewSchemaCompiler(nil, dialect).CompileJSON(&v)
+	case map[string]any:
+		schema = v
+	case json.RawMessage:
+		if err := json.Unmarshal(v, &schema); err != nil {
+			return nil, fmt.Errorf("schema: decode metadata: %w", err)
+		}
+	case []byte:
+		if err := json.Unmarshal(v, &schema); err != nil {
+			return nil, fmt.Errorf("schema: decode metadata: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("schema: unsupported metadata type: %T", metadata)
+	}
+
+	if dialect == DialectGemini && hasSchemaRef(schema) {
+		return nil, fmt.Errorf("schema: Gemini does not support recursive types")
+	}
+	data, err := json.Marshal(AdaptSchema(schema, dialect))
+	if err != nil {
+		return nil, fmt.Errorf("schema: marshal: %w", err)
+	}
+	return data, nil
+}
+
+// hasSchemaRef сообщает, есть ли в схеме $ref: встроенные схемы рекурсивных типов без него не обходятся.
+func hasSchemaRef(schema map[string]any) bool {
+	found := false
+	walkSchema(schema, func(sub map[string]any) {
+		if _, ok := sub["$ref"]; ok {
+			found = true
+		}
+	})
+	return found
+}
+
+// AdaptSchema приводит схему draft 2020-12 к диалекту. Исходная схема не изменяется.
+func AdaptSchema(schema map[string]any, dialect SchemaDialect) map[string]any {
+	out := cloneSchema(schema)
+	if dialect != DialectDraft2020 {
+		delete(out, "$schema")
+	}
+
+	switch dialect {
+	case DialectDraft2020:
+		if _, ok := out["$schema"]; !ok {
+			out["$schema"] = draft2020SchemaURI
+		}
+	case DialectOpenAIStrict:
+		walkSchema(out, adaptOpenAIStrict)
+	case DialectAnthropicTool:
+		walkSchema(out, closeObject)
+	case DialectGrok:
+		walkSchema(out, adaptGrok)
+	case DialectGemini:
+		walkSchema(out, adaptGemini)
+	}
+
+	return out
+}
+
+// ValueWrapperKey — поле, в которое оборачивается выходной тип с необъектным корнем.
+const ValueWrapperKey = "value"
+
+// WrapValueSchema оборачивает схему, корень которой не объект (объединение, массив,
+// примитив), в объект с единственным обязательным полем value: strict json_schema
+// OpenAI, Grok и инструменты Anthropic принимают только объектный корень. $defs
+// переносятся на новый корень, ссылки "#" — на обёрнутое значение. Для объектного
+// корня схема возвращается как есть и false.
+func WrapValueSchema(schema map[string]any) (map[string]any, bool) {
+	if schema["type"] == "object" {
+		return schema, false
+	}
+	value := cloneSchema(schema)
+	wrapper := map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{ValueWrapperKey: value},
+		"required":             []any{ValueWrapperKey},
+		"additionalProperties": false,
+	}
+	if defs, ok := value["$defs"]; ok {
+		wrapper["$defs"] = defs
+		delete(value, "$defs")
+	}
+	walkSchema(wrapper, func(sub map[string]any) {
+		if sub["$ref"] == "#" {
+			sub["$ref"] = "#/properties/" + ValueWrapperKey
+		}
+	})
+	return wrapper, true
+}
+
+// UnwrapValue извлекает значение поля value из ответа на схему, обёрнутую WrapValueSchema.
+func UnwrapValue(data []byte) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("schema: decode wrapped value: %w", err)
+	}
+	value, ok := envelope[ValueWrapperKey]
+	if !ok {
+		return nil, fmt.Errorf("schema: wrapped value has no %q field", ValueWrapperKey)
+	}
+	return value, nil
+}
+
+func (c *SchemaCompiler) compile(td *TypeDef, st *compileState) (map[string]any, error) {
+	schema := make(map[string]any)
+
+	switch td.Kind {
+	case KindString:
+		schema["type"] = "string"
+		if td.MinLength != nil {
+			schema["minLength"] = *td.MinLength
+		}
+		if td.MaxLength != nil {
+			schema["maxLength"] = *td.MaxLength
+		}
+		if td.Pattern != "" {
+			schema["pattern"] = td.Pattern
+		} else if pattern := c.registry.FormatPattern(td.Format); pattern != "" {
+			// Пользовательские форматы и phone передаются провайдерам как pattern
+			schema["pattern"] = pattern
+		}
+		if format := schemaFormat(td.Format); format != "" {
+			schema["format"] = format
+		}
+
+	case KindInt:
+		schema["type"] = "integer"
+		if td.Min != nil {
+			schema["minimum"] = int(*td.Min)
+		}
+		if td.Max != nil {
+			schema["maximum"] = int(*td.Max)
+		}
+
+	case KindNumber:
+		schema["type"] = "number"
+		if td.Min != nil {
+			schema["minimum"] = *td.Min
+		}
+		if td.Max != nil {
+			schema["maximum"] = *td.Max
+		}
+
+	case KindBool:
+		schema["type"] = "boolean"
+
+	case KindDatetime:
+		schema["type"] = "string"
+		schema["format"] = "date-time"
+
+	case KindDate:
+		schema["type"] = "string"
+		schema["format"] = "date"
+
+	case KindUUID:
+		schema["type"] = "string"
+		schema["format"] = "uuid"
+
+	case KindEnum:
+		schema["type"] = "string"
+		schema["enum"] = append([]string(nil), td.Enum...)
+
+	case KindArray:
+		schema["type"] = "array"
+		if td.Items != nil {
+			items, err := c.compile(td.Items, st)
+			if err != nil {
+				return nil, err
+			}
+			schema["items"] = items
+		}
+		if td.MinItems != nil {
+			schema["minItems"] = *td.MinItems
+		}
+		if td.MaxItems != nil {
+			schema["maxItems"] = *td.MaxItems
+		}
+
+	case KindObject:
+		schema["type"] = "object"
+		props := make(map[string]any, len(td.Properties))
+		required := make([]string, 0, len(td.Properties))
+		for _, name := range sortedPropertyNames(td) {
+			prop := td.Properties[name]
+			propSchema, err := c.compile(prop, st)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+			props[name] = propSchema
+			if !prop.Optional {
+				required = append(required, name)
+			}
+		}
+		schema["properties"] = props
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		schema["additionalProperties"] = false
+
+	case KindMap:
+		schema["type"] = "object"
+		if td.ValueType != nil && td.ValueType.Kind != KindAny {
+			value, err := c.compile(td.ValueType, st)
+			if err != nil {
+				return nil, err
+			}
+			schema["additionalProperties"] = value
+		} else {
+			schema["additionalProperties"] = true
+		}
+
+	case KindRef:
+		if c.registry == nil {
+			return nil, fmt.Errorf("schema: cannot resolve reference %s without a type registry", td.Ref)
+		}
+		target, err := c.registry.Resolve(td.Ref)
+		if err != nil {
+			return nil, err
+		}
+		name := refTypeName(td.Ref)
+		if name == st.root {
+			schema["$ref"] = "#"
+			break
+		}
+		if st.recursive[name] {
+			schema["$ref"] = "#/$defs/" + name
+			if _, ok := st.defs[name]; !ok {
+				st.defs[name] = nil // повторная ссылка внутри определения не компилирует его снова
+				def, err := c.compile(target, st)
+				if err != nil {
+					return nil, err
+				}
+				st.defs[name] = def
+			}
+			break
+		}
+		if st.visiting[target] {
+			return nil, fmt.Errorf("schema: recursive reference to %s", td.Ref)
+		}
+		st.visiting[target] = true
+		resolved, err := c.compile(target, st)
+		delete(st.visiting, target)
+		if err != nil {
+			return nil, err
+		}
+		schema = resolved
+
+	case KindOneOf:
+		var variants []UnionVariant
+		if c.registry != nil && td.Discriminator != "" {
+			var err error
+			if variants, err = c.registry.UnionVariants(td); err != nil {
+				return nil, err
+			}
+		}
+		branches := make([]any, 0, len(td.Variants))
+		for i, variant := range td.Variants {
+			branch, err := c.compile(variant, st)
+			if err != nil {
+				return nil, err
+			}
+			// Поле-дискриминатор в каждой ветке принимает только тег своего варианта
+			if variants != nil {
+				if props, ok := branch["properties"].(map[string]any); ok {
+					if disc, ok := props[td.Discriminator].(map[string]any); ok {
+						disc["enum"] = []string{variants[i].Tag}
+					}
+				}
+			}
+			branches = append(branches, branch)
+		}
+		schema["anyOf"] = branches
+
+	case KindAny:
+		// Любое JSON-значение: схема без ограничений
+
+	default:
+		schema["type"] = "string"
+	}
+
+	if td.Description != "" {
+		schema["description"] = td.Description
+	}
+	if td.Default != nil {
+		schema["default"] = td.Default
+	}
+	if len(td.Examples) > 0 {
+		schema["examples"] = append([]any(nil), td.Examples...)
+	}
+
+	return schema, nil
+}
+
+// schemaFormat переводит формат AIWF в формат JSON Schema.
+func schemaFormat(format string) string {
+	switch format {
+	case "email":
+		return "email"
+	case "url":
+		return "uri"
+	case "uuid":
+		return "uuid"
+	case "datetime":
+		return "date-time"
+	case "date":
+		return "date"
+	default:
+		return ""
+	}
+}
+
+func refTypeName(ref string) string {
+	name := ref
+	if len(name) > 0 && name[0] == '$' {
+		name = name[1:]
+	}
+	return name
+}
+
+func sortedPropertyNames(td *TypeDef) []string {
+	names := make([]string, 0, len(td.Properties))
+	for name := range td.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkSchema вызывает fn для схемы и всех вложенных подсхем.
+func walkSchema(schema map[string]any, fn func(map[string]any)) {
+	fn(schema)
+
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for _, prop := range props {
+			if sub, ok := prop.(map[string]any); ok {
+				walkSchema(sub, fn)
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		walkSchema(items, fn)
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		walkSchema(additional, fn)
+	}
+	for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+		if variants, ok := schema[key].([]any); ok {
+			for _, variant := range variants {
+				if sub, ok := variant.(map[string]any); ok {
+					walkSchema(sub, fn)
+				}
+			}
+		}
+	}
+	for _, key := range []string{"$defs", "definitions"} {
+		if defs, ok := schema[key].(map[string]any); ok {
+			for _, def := range defs {
+				if sub, ok := def.(map[string]any); ok {
+					walkSchema(sub, fn)
+				}
+			}
+		}
+	}
+}
+
+// closeObject запрещает лишние поля у объектов без явного additionalProperties.
+func closeObject(schema map[string]any) {
+	if schema["type"] != "object" {
+		return
+	}
+	if _, ok := schema["additionalProperties"]; !ok {
+		schema["additionalProperties"] = false
+	}
+}
+
+// adaptOpenAIStrict делает все поля обязательными, а необязательные — nullable.
+func adaptOpenAIStrict(schema map[string]any) {
+	closeObject(schema)
+	for _, key := range openAIStrictUnsupportedKeywords {
+		delete(schema, key)
+	}
+
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	required := make(map[string]bool)
+	for _, name := range stringList(schema["required"]) {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(props))
+	for name, prop := range props {
+		names = append(names, name)
+		if required[name] {
+			continue
+		}
+		if sub, ok := prop.(map[string]any); ok {
+			props[name] = nullable(sub)
+		}
+	}
+	sort.Strings(names)
+	schema["required"] = names
+}
+
+// nullable разрешает null в схеме поля.
+func nullable(schema map[string]any) map[string]any {
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = []any{t, "null"}
+		if enum, ok := schema["enum"]; ok {
+			values := make([]any, 0)
+			for _, v := range stringList(enum) {
+				values = append(values, v)
+			}
+			schema["enum"] = append(values, nil)
+		}
+		return schema
+	case []any:
+		for _, v := range t {
+			if v == "null" {
+				return schema
+			}
+		}
+		schema["type"] = append(t, "null")
+		return schema
+	default:
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+}
+
+func adaptGrok(schema map[string]any) {
+	closeObject(schema)
+	for _, key := range grokUnsupportedKeywords {
+		delete(schema, key)
+	}
+}
+
+// adaptGemini переводит узел в Schema Gemini: тип в верхнем регистре, null через nullable.
+func adaptGemini(schema map[string]any) {
+	for _, key := range geminiUnsupportedKeywords {
+		delete(schema, key)
+	}
+
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = strings.ToUpper(t)
+	case []any:
+		var types []string
+		for _, v := range t {
+			if v == "null" {
+				schema["nullable"] = true
+				continue
+			}
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		if len(types) > 0 {
+			schema["type"] = strings.ToUpper(types[0])
+		} else {
+			delete(schema, "type")
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		values := make([]any, 0, len(enum))
+		for _, v := range enum {
+			if v == nil {
+				schema["nullable"] = true
+				continue
+			}
+			values = append(values, v)
+		}
+		schema["enum"] = values
+	}
+
+	// Для строк Gemini поддерживает только форматы enum и date-time
+	if format, ok := schema["format"].(string); ok && schema["type"] == "STRING" && format != "date-time" && format != "enum" {
+		delete(schema, "format")
+	}
+}
+
+// stringList читает список строк, представленный как []string или []any.
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// cloneSchema выполняет глубокое копирование схемы.
+func cloneSchema(schema map[string]any) map[string]any {
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		out[k] = cloneSchemaValue(v)
+	}
+	return out
+}
+
+func cloneSchemaValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return cloneSchema(val)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = cloneSchemaValue(item)
+		}
+		return out
+	case []string:
+		return append([]string(nil), val...)
+	default:
+		return v
+	}
+}
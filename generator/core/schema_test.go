@@ -0,0 +1,231 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func schemaTestRegistry() *TypeRegistry {
+	return &TypeRegistry{Types: map[string]*TypeDef{
+		"Address": {
+			Name: "Address",
+			Kind: KindObject,
+			Properties: map[string]*TypeDef{
+				"city": {Kind: KindString},
+				"zip":  {Kind: KindString, Optional: true},
+			},
+		},
+		"User": {
+			Name: "User",
+			Kind: KindObject,
+			Properties: map[string]*TypeDef{
+				"name":    {Kind: KindString, Description: "Full name"},
+				"role":    {Kind: KindEnum, Enum: []string{"admin", "user"}, Optional: true},
+				"address": {Kind: KindRef, Ref: "$Address"},
+			},
+		},
+	}}
+}
+
+func TestSchemaCompilerDraft2020(t *testing.T) {
+	reg := schemaTestRegistry()
+	schema, err := NewSchemaCompiler(reg, DialectDraft2020).Compile(reg.Types["User"])
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	if schema["$schema"] != draft2020SchemaURI {
+		t.Errorf("expected $schema, got %v", schema["$schema"])
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"address", "name"}) {
+		t.Errorf("unexpected required: %v", schema["required"])
+	}
+	props := schema["properties"].(map[string]any)
+	if props["name"].(map[string]any)["description"] != "Full name" {
+		t.Errorf("description is lost: %v", props["name"])
+	}
+	address := props["address"].(map[string]any)
+	if address["type"] != "object" || address["additionalProperties"] != false {
+		t.Errorf("reference is not inlined: %v", address)
+	}
+}
+
+func TestSchemaCompilerOpenAIStrict(t *testing.T) {
+	reg := schemaTestRegistry()
+	data, err := NewSchemaCompiler(reg, DialectOpenAIStrict).CompileJSON(reg.Types["User"])
+	if err != nil {
+		t.Fatalf("CompileJSON: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := schema["$schema"]; ok {
+		t.Errorf("strict schema must not contain $schema")
+	}
+	if got := schema["required"].([]any); len(got) != 3 {
+		t.Errorf("expected all fields required, got %v", got)
+	}
+
+	props := schema["properties"].(map[string]any)
+	role := props["role"].(map[string]any)
+	if !reflect.DeepEqual(role["type"], []any{"string", "null"}) {
+		t.Errorf("optional enum must be nullable, got %v", role["type"])
+	}
+	if enum := role["enum"].([]any); enum[len(enum)-1] != nil {
+		t.Errorf("optional enum must allow null, got %v", enum)
+	}
+
+	zip := props["address"].(map[string]any)["properties"].(map[string]any)["zip"].(map[string]any)
+	if !reflect.DeepEqual(zip["type"], []any{"string", "null"}) {
+		t.Errorf("nested optional field must be nullable, got %v", zip["type"])
+	}
+}
+
+func TestAdaptSchemaGrokDropsUnsupportedKeywords(t *testing.T) {
+	source := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"tags": map[string]any{"type": "array", "minItems": 1, "items": map[string]any{"type": "string", "maxLength": 10}},
+		},
+	}
+
+	adapted := AdaptSchema(source, DialectGrok)
+	tags := adapted["properties"].(map[string]any)["tags"].(map[string]any)
+	if _, ok := tags["minItems"]; ok {
+		t.Errorf("minItems must be removed: %v", tags)
+	}
+	if _, ok := tags["items"].(map[string]any)["maxLength"]; ok {
+		t.Errorf("maxLength must be removed: %v", tags)
+	}
+	if adapted["additionalProperties"] != false {
+		t.Errorf("objects must be closed")
+	}
+	if _, ok := source["properties"].(map[string]any)["tags"].(map[string]any)["minItems"]; !ok {
+		t.Errorf("source schema must not be modified")
+	}
+}
+
+func TestSchemaCompilerRecursiveTypes(t *testing.T) {
+	reg := &TypeRegistry{Types: map[string]*TypeDef{
+		"Node": {Kind: KindObject, Properties: map[string]*TypeDef{
+			"next": {Kind: KindRef, Ref: "$Node", Optional: true},
+		}},
+		"List": {Kind: KindObject, Properties: map[string]*TypeDef{
+			"head": {Kind: KindRef, Ref: "$Node"},
+		}},
+	}}
+
+	// Ссылка корневого типа на себя — "#"
+	node, err := NewSchemaCompiler(reg, DialectDraft2020).Compile(reg.Types["Node"])
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	next := node["properties"].(map[string]any)["next"].(map[string]any)
+	if next["$ref"] != "#" || node["$defs"] != nil {
+		t.Errorf("expected reference to the root, got %v", node)
+	}
+
+	// Другой рекурсивный тип выносится в $defs
+	list, err := NewSchemaCompiler(reg, DialectDraft2020).Compile(reg.Types["List"])
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	head := list["properties"].(map[string]any)["head"].(map[string]any)
+	defs, _ := list["$defs"].(map[string]any)
+	if head["$ref"] != "#/$defs/Node" || defs["Node"] == nil {
+		t.Errorf("expected Node in $defs, got %v", list)
+	}
+	def := defs["Node"].(map[string]any)
+	if ref := def["properties"].(map[string]any)["next"].(map[string]any)["$ref"]; ref != "#/$defs/Node" {
+		t.Errorf("definition must reference itself, got %v", ref)
+	}
+
+	if _, err := NewSchemaCompiler(reg, DialectGemini).Compile(reg.Types["List"]); err == nil {
+		t.Error("expected error for recursive schema in Gemini dialect")
+	}
+	if _, err := SchemaFromMetadata(list, DialectGemini); err == nil {
+		t.Error("expected error for recursive metadata in Gemini dialect")
+	}
+}
+
+func TestSchemaFromMetadataTypeDefRefs(t *testing.T) {
+	data, err := SchemaFromMetadata(&TypeDef{Kind: KindArray, Items: &TypeDef{Kind: KindString}}, DialectGrok)
+	if err != nil {
+		t.Fatalf("SchemaFromMetadata: %v", err)
+	}
+	if string(data) != `{"items":{"type":"string"},"type":"array"}` {
+		t.Errorf("unexpected schema: %s", data)
+	}
+
+	// Без реестра ссылку не во что раскрыть: вместо $ref на несуществующие $defs — ошибка
+	user := &TypeDef{Kind: KindObject, Properties: map[string]*TypeDef{"address": {Kind: KindRef, Ref: "$Address"}}}
+	if _, err := SchemaFromMetadata(user, DialectOpenAIStrict); err == nil || !strings.Contains(err.Error(), "$Address") {
+		t.Errorf("expected unresolved reference error, got %v", err)
+	}
+}
+
+func TestAdaptSchemaGemini(t *testing.T) {
+	reg := schemaTestRegistry()
+	strict, err := NewSchemaCompiler(reg, DialectOpenAIStrict).Compile(reg.Types["User"])
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	schema := AdaptSchema(strict, DialectGemini)
+	if schema["type"] != "OBJECT" {
+		t.Errorf("expected OBJECT type, got %v", schema["type"])
+	}
+	if _, ok := schema["additionalProperties"]; ok {
+		t.Errorf("additionalProperties is not supported by Gemini")
+	}
+	role := schema["properties"].(map[string]any)["role"].(map[string]any)
+	if role["type"] != "STRING" || role["nullable"] != true {
+		t.Errorf("expected nullable STRING, got %v", role)
+	}
+	if !reflect.DeepEqual(role["enum"], []any{"admin", "user"}) {
+		t.Errorf("null must be removed from enum, got %v", role["enum"])
+	}
+}
+
+func TestWrapValueSchema(t *testing.T) {
+	object := map[string]any{"type": "object"}
+	if got, wrapped := WrapValueSchema(object); wrapped || !reflect.DeepEqual(got, object) {
+		t.Fatalf("object root must stay as is, got %v", got)
+	}
+
+	// Рекурсивное объединение: $defs поднимаются на новый корень, "#" указывает на value
+	union := map[string]any{
+		"anyOf": []any{
+			map[string]any{"$ref": "#/$defs/Leaf"},
+			map[string]any{"type": "array", "items": map[string]any{"$ref": "#"}},
+		},
+		"$defs": map[string]any{"Leaf": map[string]any{"type": "string"}},
+	}
+	got, wrapped := WrapValueSchema(union)
+	if !wrapped || got["type"] != "object" || got["$defs"] == nil {
+		t.Fatalf("unexpected wrapper: %v", got)
+	}
+	value := got["properties"].(map[string]any)[ValueWrapperKey].(map[string]any)
+	if _, ok := value["$defs"]; ok {
+		t.Fatalf("$defs must move to the wrapper root: %v", value)
+	}
+	items := value["anyOf"].([]any)[1].(map[string]any)["items"].(map[string]any)
+	if items["$ref"] != "#/properties/value" {
+		t.Fatalf("root reference must point to the wrapped value, got %v", items["$ref"])
+	}
+	if union["anyOf"].([]any)[1].(map[string]any)["items"].(map[string]any)["$ref"] != "#" {
+		t.Fatal("source schema must not change")
+	}
+
+	data, err := UnwrapValue([]byte(`{"value":["a"]}`))
+	if err != nil || string(data) != `["a"]` {
+		t.Fatalf("UnwrapValue: %s, %v", data, err)
+	}
+	if _, err := UnwrapValue([]byte(`{"other":1}`)); err == nil {
+		t.Fatal("expected error for missing value field")
+	}
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position — место в YAML-файле; строки и столбцы считаются с 1.
+type Position struct {
+	File   string
+	Line   int
+	Column int // 0 — столбец неизвестен
+}
+
+// IsValid сообщает, известна ли позиция.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return p.File
+	}
+	s := fmt.Sprintf("%s:%d", p.File, p.Line)
+	if p.Column > 0 {
+		s += fmt.Sprintf(":%d", p.Column)
+	}
+	return s
+}
+
+// Source хранит дерево узлов YAML файла спецификации для поиска позиций по пути поля.
+type Source struct {
+	File string
+	root *yaml.Node
+}
+
+// parseSource разбирает YAML в дерево узлов. Синтаксические ошибки возвращаются
+// как *MultiError с позициями.
+func parseSource(file string, data []byte) (*Source, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlErrors(file, err)
+	}
+	src := &Source{File: file, root: &doc}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		src.root = doc.Content[0]
+	}
+	return src, nil
+}
+
+// decode заполняет v из дерева узлов; ошибки типов возвращаются как *MultiError со всеми
+// найденными несоответствиями.
+func (s *Source) decode(v interface{}) error {
+	if s.root.Kind == 0 {
+		return nil // пустой файл
+	}
+	if err := s.root.Decode(v); err != nil {
+		return yamlErrors(s.File, err)
+	}
+	return nil
+}
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlErrors переводит ошибки yaml.v3 («line N: ...») в ValidationError с позициями.
+func yamlErrors(file string, err error) error {
+	msgs := []string{err.Error()}
+	code := CodeYAMLSyntax
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+		code = CodeYAMLType
+	}
+
+	merr := &MultiError{}
+	for _, msg := range msgs {
+		verr := &ValidationError{Code: code, Msg: strings.TrimPrefix(msg, "yaml: "), Pos: Position{File: file}}
+		if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+			verr.Pos.Line, _ = strconv.Atoi(m[1])
+			verr.Msg = m[2]
+		}
+		merr.Append(verr)
+	}
+	return merr
+}
+
+// Lookup возвращает позицию поля по пути вида assistants.writer.knowledge[1].
+// Если поля в файле нет, возвращается позиция ближайшего существующего предка.
+func (s *Source) Lookup(field string) Position {
+	pos, _, _ := s.lookup(field)
+	return pos
+}
+
+// lookup возвращает позицию поля, последний найденный узел и признак того, что
+// путь найден целиком.
+func (s *Source) lookup(field string) (Position, *yaml.Node, bool) {
+	if s == nil || s.root == nil || s.root.Kind == 0 {
+		return Position{}, nil, false
+	}
+	pos := Position{File: s.File}
+	tokens := splitFieldPath(field)
+
+	node := s.root
+	i := 0
+	for i < len(tokens) {
+		var next, at *yaml.Node
+		consumed := 1
+		if idx, ok := tokens[i].index(); ok {
+			if node.Kind == yaml.SequenceNode && idx < len(node.Content) {
+				next, at = node.Content[idx], node.Content[idx]
+			}
+		} else if node.Kind == yaml.MappingNode {
+			next, at, consumed = lookupKey(node, tokens[i:])
+		}
+		if next == nil {
+			break
+		}
+		node = next
+		i += consumed
+		// Скаляры указываются по значению, составные узлы — по ключу
+		if node.Kind == yaml.ScalarNode {
+			at = node
+		}
+		pos.Line, pos.Column = at.Line, at.Column
+	}
+	return pos, node, i == len(tokens)
+}
+
+// exprPosition возвращает позицию символа со смещением offset (в байтах) внутри
+// скалярного значения поля; для выражений типов указывает на место ошибки.
+func (s *Source) exprPosition(field string, offset int) Position {
+	pos, node, found := s.lookup(field)
+	if !found || offset <= 0 || node.Kind != yaml.ScalarNode || offset > len(node.Value) ||
+		node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(node.Value, "\n") {
+		return pos
+	}
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		pos.Column++
+	}
+	pos.Column += utf8.RuneCountInString(node.Value[:offset])
+	return pos
+}
+
+// lookupKey ищет в отображении ключ, совпадающий с наибольшим числом сегментов пути:
+// имена вроде gpt-4.1 сами содержат точку. Ключи опциональных полей записаны с суффиксом ?.
+func lookupKey(node *yaml.Node, tokens []fieldToken) (value, key *yaml.Node, consumed int) {
+	for n := len(tokens); n > 0; n-- {
+		parts := make([]string, 0, n)
+		for _, t := range tokens[:n] {
+			if _, isIndex := t.index(); isIndex {
+				break
+			}
+			parts = append(parts, string(t))
+		}
+		if len(parts) != n {
+			continue
+		}
+		name := strings.Join(parts, ".")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i]
+			if k.Value == name || k.Value == name+"?" {
+				return node.Content[i+1], k, n
+			}
+		}
+	}
+	return nil, nil, 0
+}
+
+// fieldToken — сегмент пути поля: имя ключа или индекс вида [n].
+type fieldToken string
+
+func (t fieldToken) index() (int, bool) {
+	if !strings.HasPrefix(string(t), "[") || !strings.HasSuffix(string(t), "]") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(t[1 : len(t)-1]))
+	return n, err == nil
+}
+
+func splitFieldPath(field string) []fieldToken {
+	var tokens []fieldToken
+	for _, part := range strings.Split(field, ".") {
+		for part != "" {
+			open := strings.Index(part, "[")
+			if open < 0 {
+				tokens = append(tokens, fieldToken(part))
+				break
+			}
+			if open > 0 {
+				tokens = append(tokens, fieldToken(part[:open]))
+			}
+			end := strings.Index(part[open:], "]")
+			if end < 0 {
+				tokens = append(tokens, fieldToken(part[open:]))
+				break
+			}
+			tokens = append(tokens, fieldToken(part[open:open+end+1]))
+			part = part[open+end+1:]
+		}
+	}
+	return tokens
+}
+
+// annotate проставляет позиции ошибкам и предупреждениям, у которых их ещё нет.
+func (s *Source) annotate(merr *MultiError) {
+	if s == nil || merr == nil {
+		return
+	}
+	for _, e := range merr.Errors {
+		if !e.Pos.IsValid() && e.Field != "" {
+			e.Pos = s.exprPosition(e.Field, e.exprOffset)
+		}
+	}
+	for _, w := range merr.Warnings {
+		if !w.Pos.IsValid() && w.Field != "" {
+			w.Pos = s.Lookup(w.Field)
+		}
+	}
+}
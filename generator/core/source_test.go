@@ -0,0 +1,107 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceLookup(t *testing.T) {
+	src, err := parseSource("spec.yaml", []byte(`types:
+  Order:
+    total?: number
+models:
+  gpt-4.1:
+    provider: openai
+assistants:
+  writer:
+    knowledge: [docs, faq]
+    dialog:
+      max_rounds: 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]Position{
+		"types.Order.total":                  {File: "spec.yaml", Line: 3, Column: 13},
+		"models.gpt-4.1.provider":            {File: "spec.yaml", Line: 6, Column: 15},
+		"assistants.writer.knowledge[1]":     {File: "spec.yaml", Line: 9, Column: 23},
+		"assistants.writer.dialog":           {File: "spec.yaml", Line: 10, Column: 5},
+		"assistants.writer.dialog.max_turns": {File: "spec.yaml", Line: 10, Column: 5}, // ближайший предок
+		"assistants.reader":                  {File: "spec.yaml", Line: 7, Column: 1},
+	}
+	for field, want := range cases {
+		if got := src.Lookup(field); got != want {
+			t.Errorf("Lookup(%s) = %v, want %v", field, got, want)
+		}
+	}
+}
+
+func TestLoadSpecReportsYAMLPositions(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"spec.yaml": "assistants:\n  w:\n    max_tokens: lots\n    model: [1]\n",
+	})
+
+	_, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	merr, ok := err.(*MultiError)
+	if !ok || len(merr.Errors) != 2 {
+		t.Fatalf("expected both decode errors, got %v", err)
+	}
+	if line := merr.Errors[0].Pos.Line; line != 3 {
+		t.Fatalf("unexpected line %d", line)
+	}
+	if line := merr.Errors[1].Pos.Line; line != 4 {
+		t.Fatalf("unexpected line %d", line)
+	}
+}
+
+func TestBuildIRCollectsPositionedErrors(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"spec.yaml": `types:
+  Order:
+    customer: $Customer
+assistants:
+  writer:
+    use: coretest
+    model: m
+    input_type: Order
+    dialog:
+      max_rounds: 3
+`,
+	})
+	spec, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = BuildIR(spec)
+	merr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	got := make(map[string]Position)
+	codes := make(map[string]Code)
+	for _, e := range merr.Errors {
+		got[e.Field] = e.Pos
+		codes[e.Field] = e.Code
+	}
+	if codes["types.Order.customer"] != CodeUndefinedReference || codes["assistants.writer.dialog"] != CodeDialogWithoutThread {
+		t.Fatalf("unexpected codes: %v", codes)
+	}
+	if pos := got["types.Order.customer"]; pos.Line != 3 || pos.Column != 15 {
+		t.Fatalf("undefined ref position: %v (errors %v)", pos, got)
+	}
+	if pos := got["assistants.writer.dialog"]; pos.Line != 9 || pos.Column != 5 {
+		t.Fatalf("dialog position: %v (errors %v)", pos, got)
+	}
+}
+
+func TestCodesAreUnique(t *testing.T) {
+	seen := make(map[Code]bool)
+	for _, info := range Codes {
+		if seen[info.Code] || info.Name == "" || info.Description == "" {
+			t.Fatalf("invalid code entry %+v", info)
+		}
+		seen[info.Code] = true
+	}
+}
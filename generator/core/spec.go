@@ -5,7 +5,11 @@ type Spec struct {
 	Version    string                   `yaml:"version"`
 	Imports    []ImportSpec             `yaml:"imports"`
 	Types      map[string]interface{}   `yaml:"types"`
+	Formats    map[string]string        `yaml:"formats"` // пользовательские форматы строк: имя → regex
+	Providers  map[string]ProviderSpec  `yaml:"providers"`
+	Models     map[string]ModelSpec     `yaml:"models"`
 	Threads    map[string]ThreadSpec    `yaml:"threads"`
+	Knowledge  map[string]KnowledgeSpec `yaml:"knowledge"`
 	Assistants map[string]AssistantSpec `yaml:"assistants"`
 	Resolved   SpecResolution           `yaml:"-"`
 }
@@ -16,26 +20,81 @@ type ImportSpec struct {
 	Path string `yaml:"path"`
 }
 
+// ProviderSpec описывает именованную настройку провайдера в разделе providers:.
+type ProviderSpec struct {
+	Type      string            `yaml:"type"`        // зарегистрированный провайдер (openai, grok, ...)
+	BaseURL   string            `yaml:"base_url"`
+	APIKeyEnv string            `yaml:"api_key_env"` // переменная окружения с ключом
+	Timeout   string            `yaml:"timeout"`     // длительность, например 30s
+	Headers   map[string]string `yaml:"headers"`
+	Org       string            `yaml:"org"`
+	Mode      string            `yaml:"mode"` // API OpenAI-совместимого провайдера: responses или chat_completions
+
+	// Параметры Ollama
+	NumCtx      int    `yaml:"num_ctx"`      // размер контекстного окна; 0 — значение модели
+	KeepAlive   string `yaml:"keep_alive"`   // сколько модель остаётся в памяти, например 10m
+	PullMissing bool   `yaml:"pull_missing"` // скачивать отсутствующую модель
+
+	// Параметры Azure OpenAI
+	Deployments map[string]string `yaml:"deployments"` // модель → имя деплоймента
+}
+
+// ModelSpec описывает алиас модели в разделе models:.
+type ModelSpec struct {
+	Provider string `yaml:"provider"` // запись providers: или имя зарегистрированного провайдера
+	Model    string `yaml:"model"`
+}
+
+// KnowledgeSpec описывает локальный источник знаний в разделе knowledge:.
+type KnowledgeSpec struct {
+	Paths        []string `yaml:"paths"`         // каталоги с markdown, текстом и JSON
+	Include      []string `yaml:"include"`       // шаблоны имён файлов (*.md, ...)
+	ChunkSize    int      `yaml:"chunk_size"`    // длина фрагмента в символах
+	ChunkOverlap *int     `yaml:"chunk_overlap"` // перекрытие фрагментов в символах; 0 — без перекрытия
+	TopK         int      `yaml:"top_k"`         // число фрагментов в промпте
+	Embedding    string   `yaml:"embedding"`     // ассистент kind: embedding для векторного поиска
+}
+
 // SpecResolution содержит вспомогательные структуры, полученные при загрузке.
 type SpecResolution struct {
+	Path         string  // абсолютный путь к файлу спецификации; от него считаются пути imports:
+	Source       *Source // узлы YAML для позиций в диагностике
 	TypeRegistry *TypeRegistry
 }
 
 // AssistantSpec описывает агента в YAML.
 type AssistantSpec struct {
+	Kind         string   `yaml:"kind"` // chat (по умолчанию) или embedding
 	Use          string   `yaml:"use"`
-	Model        string   `yaml:"model"`
+	Provider     string   `yaml:"provider"` // запись из раздела providers:
+	Model        string   `yaml:"model"`    // идентификатор модели или алиас из models:
+	Deployment   string   `yaml:"deployment"` // имя деплоймента Azure OpenAI (use: azure)
 	SystemPrompt string   `yaml:"system_prompt"`
 	InputType    string   `yaml:"input_type"`
 	OutputType   string   `yaml:"output_type"`
 	MaxTokens    int      `yaml:"max_tokens"`
-	Temperature  float64  `yaml:"temperature"`
+	Dimensions   int      `yaml:"dimensions"` // размерность вектора (kind: embedding)
+	Generation   `yaml:",inline"`
+	PromptCache  bool     `yaml:"prompt_cache"` // кэширование стабильного префикса промпта
+	Knowledge    []string `yaml:"knowledge"`    // источники из раздела knowledge:
 	DependsOn    []string `yaml:"depends_on"`
 	Thread       *ThreadBindingSpec `yaml:"thread"`
 	Dialog       *DialogSpec        `yaml:"dialog"`
 	Resolved     AssistantResolution `yaml:"-"`
 }
 
+// Generation описывает параметры генерации ассистента.
+// Указатели отличают незаданное значение от нуля: temperature: 0 передаётся провайдеру.
+type Generation struct {
+	Temperature      *float64 `yaml:"temperature"`
+	TopP             *float64 `yaml:"top_p"`
+	Seed             *int     `yaml:"seed"`
+	Stop             []string `yaml:"stop"`
+	PresencePenalty  *float64 `yaml:"presence_penalty"`
+	FrequencyPenalty *float64 `yaml:"frequency_penalty"`
+	ReasoningEffort  string   `yaml:"reasoning_effort"` // minimal, low, medium, high
+}
+
 // AssistantResolution содержит разрешённые типы.
 type AssistantResolution struct {
 	InputType  *TypeDef
@@ -66,16 +125,29 @@ type DialogSpec struct {
 
 // ValidationError описывает ошибку загрузки.
 type ValidationError struct {
+	Code  Code
 	Field string
 	Msg   string
+	Pos   Position // место в файле спецификации, если известно
+
+	exprOffset int // смещение ошибки внутри значения-выражения типа, в байтах
 }
 
 func (e *ValidationError) Error() string {
-	return e.Field + ": " + e.Msg
+	msg := e.Msg
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if e.Pos.IsValid() {
+		msg = e.Pos.String() + ": " + msg
+	}
+	return msg
 }
 
 // ValidationWarning описывает предупреждение.
 type ValidationWarning struct {
+	Code  Code
 	Field string
 	Msg   string
+	Pos   Position
 }
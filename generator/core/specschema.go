@@ -0,0 +1,392 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecVersion — текущая версия формата спецификации (поле version:).
+const SpecVersion = "0.3"
+
+// SchemaURL — адрес опубликованной JSON Schema текущей версии (файл schema/<версия>/aiwf.schema.json).
+const SchemaURL = "https://raw.githubusercontent.com/andranikuz/aiwf/main/schema/" + SpecVersion + "/aiwf.schema.json"
+
+// TypeExpressionPattern описывает грамматику выражений типов: примитив с ограничениями
+// (в string(...) — и шаблон /regex/ со скобками внутри), enum(...), map(...) с вложенностью
+// скобок до двух уровней, oneOf(...), ссылку [$]Type
+// или [$]module.Type и суффиксы массива. Точную проверку выполняет ParseTypeExpression.
+const TypeExpressionPattern = `^\s*(?:string(?:\((?:[^()/]|/(?:[^/\\]|\\.)*/)*\))?|(?:int|number)(?:\([^()]*\))?|bool|datetime|date|uuid|any|enum\([^()]+\)|map\((?:[^()]|\((?:[^()]|\([^()]*\))*\))+\)|oneOf\([^()]+\)|\$?[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)?)(?:\s*\[\](?:\([^()]*\))?)*\s*$`
+
+// fieldDescriptions — описания полей в JSON Schema; ключ — тип Go и ключ YAML.
+var fieldDescriptions = map[string]string{
+	"Spec.version":                   "Spec format version",
+	"Spec.imports":                   "YAML files with shared types, referenced as $alias.Type",
+	"Spec.types":                     "Named types: a type expression or an object of fields (optional fields end with ?)",
+	"Spec.formats":                   "Custom string formats: name → regular expression, used as string(name)",
+	"Spec.providers":                 "Named provider settings",
+	"Spec.models":                    "Model aliases usable in assistants.*.model",
+	"Spec.threads":                   "Thread policies",
+	"Spec.knowledge":                 "Local knowledge sources for retrieval",
+	"Spec.assistants":                "Agents",
+	"ImportSpec.as":                  "Module alias",
+	"ImportSpec.path":                "Path relative to the file that declares the import",
+	"ProviderSpec.type":              "Registered provider (openai, anthropic, grok, ...)",
+	"ProviderSpec.api_key_env":       "Environment variable with the API key",
+	"ProviderSpec.timeout":           "Request timeout, e.g. 30s",
+	"ProviderSpec.mode":              "API of an OpenAI-compatible provider: responses or chat_completions",
+	"ProviderSpec.num_ctx":           "Context window size for Ollama; 0 keeps the model default",
+	"ProviderSpec.keep_alive":        "How long Ollama keeps the model loaded, e.g. 10m",
+	"ProviderSpec.pull_missing":      "Pull a missing Ollama model instead of failing",
+	"ProviderSpec.deployments":       "Azure OpenAI deployment names by model",
+	"ModelSpec.provider":             "Entry of providers: or a registered provider",
+	"AssistantSpec.kind":             "chat (default) or embedding",
+	"AssistantSpec.use":              "Registered provider",
+	"AssistantSpec.provider":         "Entry of providers:",
+	"AssistantSpec.model":            "Model identifier or alias from models:",
+	"AssistantSpec.deployment":       "Azure OpenAI deployment name (use: azure)",
+	"AssistantSpec.input_type":       "Input type: a type name or a type expression",
+	"AssistantSpec.output_type":      "Output type: a type name or a type expression (default string)",
+	"AssistantSpec.dimensions":       "Embedding vector size (kind: embedding)",
+	"AssistantSpec.prompt_cache":     "Cache the stable prompt prefix",
+	"AssistantSpec.knowledge":        "Knowledge sources from knowledge:",
+	"AssistantSpec.thread":           "Thread policy binding; required by dialog",
+	"AssistantSpec.reasoning_effort": "Reasoning effort for reasoning models",
+	"KnowledgeSpec.paths":            "Directories with markdown, text and JSON, relative to the spec file",
+	"KnowledgeSpec.include":          "File name patterns (*.md, ...)",
+	"KnowledgeSpec.embedding":        "Assistant of kind: embedding for vector search",
+	"ThreadBindingSpec.use":          "Entry of threads:",
+}
+
+// fieldEnums — допустимые значения строковых полей.
+var fieldEnums = map[string][]string{
+	"AssistantSpec.kind":             {KindChat, KindEmbedding},
+	"AssistantSpec.reasoning_effort": ReasoningEfforts,
+}
+
+// yamlField — поле структуры спецификации под ключом YAML.
+type yamlField struct {
+	key   string
+	owner string // имя структуры, объявившей поле
+	typ   reflect.Type
+}
+
+// yamlFields возвращает поля структуры с учётом `yaml:",inline"` в порядке объявления.
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if opts == "inline" || (f.Anonymous && name == "") {
+			// Поля встроенной структуры описываются от имени внешней
+			for _, inner := range yamlFields(f.Type) {
+				inner.owner = t.Name()
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields = append(fields, yamlField{key: name, owner: t.Name(), typ: f.Type})
+	}
+	return fields
+}
+
+// SpecSchema возвращает JSON Schema (draft 2020-12) формата спецификации.
+func SpecSchema() map[string]any {
+	defs := map[string]any{
+		"typeExpression": map[string]any{
+			"type":        "string",
+			"pattern":     TypeExpressionPattern,
+			"description": "Type expression: string(1..100), int(0..), enum(a, b), map(string, int), oneOf($A, $B, discriminator:kind), $User, $common.Address, $Item[](min:1)",
+		},
+		"objectType": map[string]any{
+			"type":                 "object",
+			"minProperties":        1,
+			"additionalProperties": map[string]any{"$ref": "#/$defs/fieldType"},
+		},
+		"fieldSpec": map[string]any{
+			"type":     "object",
+			"required": []string{"$type"},
+			"properties": map[string]any{
+				"$type":       map[string]any{"$ref": "#/$defs/typeExpression"},
+				"description": map[string]any{"type": "string"},
+				"default":     map[string]any{"description": "Value used when the field is missing"},
+				"examples":    map[string]any{"type": "array"},
+			},
+			"additionalProperties": false,
+		},
+		// Форма {$type: ..., description: ...} подходит и под objectType, поэтому anyOf
+		"fieldType": map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/typeExpression"},
+				map[string]any{"$ref": "#/$defs/fieldSpec"},
+				map[string]any{"$ref": "#/$defs/objectType"},
+				map[string]any{
+					"type":     "array",
+					"minItems": 1,
+					"maxItems": 1,
+					"items":    map[string]any{"$ref": "#/$defs/fieldType"},
+				},
+			},
+		},
+		"typeDefinition": map[string]any{
+			"anyOf": []any{
+				map[string]any{"$ref": "#/$defs/typeExpression"},
+				map[string]any{"$ref": "#/$defs/fieldSpec"},
+				map[string]any{"$ref": "#/$defs/objectType"},
+			},
+		},
+	}
+
+	root := structSchema(reflect.TypeOf(Spec{}), defs)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaURL
+	root["title"] = "aiwf specification " + SpecVersion
+	root["$defs"] = defs
+
+	props := root["properties"].(map[string]any)
+	// version: 0.3 в YAML — число, поэтому допускается и строка, и число
+	props["version"] = map[string]any{
+		"description": fmt.Sprintf("Spec format version (current: %s)", SpecVersion),
+		"type":        []string{"string", "number"},
+		"examples":    []string{SpecVersion},
+	}
+	props["types"] = map[string]any{
+		"description":          fieldDescriptions["Spec.types"],
+		"type":                 "object",
+		"additionalProperties": map[string]any{"$ref": "#/$defs/typeDefinition"},
+	}
+	return root
+}
+
+// structSchema описывает структуру спецификации; вложенные структуры попадают в defs.
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	props := make(map[string]any)
+	for _, f := range yamlFields(t) {
+		s := typeSchema(f.typ, defs)
+		id := f.owner + "." + f.key
+		if desc, ok := fieldDescriptions[id]; ok {
+			s = withKey(s, "description", desc)
+		}
+		if values, ok := fieldEnums[id]; ok {
+			s = withKey(s, "enum", values)
+		}
+		props[f.key] = s
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := defs[name]; !ok {
+			defs[name] = nil // защита от рекурсии
+			defs[name] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+// withKey возвращает копию схемы с дополнительным ключом; в draft 2020-12 $ref
+// сочетается с соседними ключами.
+func withKey(s map[string]any, key string, value any) map[string]any {
+	out := make(map[string]any, len(s)+1)
+	for k, v := range s {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// checkKeys сообщает о ключах YAML, которых нет в структурах спецификации,
+// с подсказкой ближайшего допустимого ключа.
+func (s *Source) checkKeys(t reflect.Type) error {
+	if s == nil || s.root == nil || s.root.Kind == 0 {
+		return nil
+	}
+	merr := &MultiError{}
+	s.checkNode(s.root, t, "", merr)
+	if merr.HasErrors() {
+		return merr
+	}
+	return nil
+}
+
+func (s *Source) checkNode(node *yaml.Node, t reflect.Type, path string, merr *MultiError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		known := make(map[string]reflect.Type, len(fields))
+		keys := make([]string, 0, len(fields))
+		for _, f := range fields {
+			known[f.key] = f.typ
+			keys = append(keys, f.key)
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue // merge key YAML
+			}
+			field := joinField(path, key.Value)
+			ft, ok := known[key.Value]
+			if !ok {
+				msg := fmt.Sprintf("unknown key %q", key.Value)
+				if hint := nearestKey(key.Value, keys); hint != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", hint)
+				}
+				merr.Append(&ValidationError{
+					Code:  CodeUnknownKey,
+					Field: field,
+					Msg:   msg,
+					Pos:   Position{File: s.File, Line: key.Line, Column: key.Column},
+				})
+				continue
+			}
+			s.checkNode(value, ft, field, merr)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			s.checkNode(node.Content[i+1], t.Elem(), joinField(path, node.Content[i].Value), merr)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			s.checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), merr)
+		}
+	}
+}
+
+// nearestKey возвращает ключ с наименьшим редакционным расстоянием, если оно достаточно мало.
+func nearestKey(key string, candidates []string) string {
+	best, bestDist := "", len(key)/3+2
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	for _, c := range sorted {
+		if d := editDistance(key, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance — расстояние Левенштейна.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// SpecKey — допустимый ключ спецификации для подсказок редактора.
+type SpecKey struct {
+	Key         string
+	Description string
+	Values      []string // допустимые значения, если поле — перечисление
+}
+
+// SpecKeys возвращает ключи отображения по пути в спецификации, например
+// ["assistants", "writer"] → ключи AssistantSpec. Сегменты разделов-словарей
+// (имена ассистентов, провайдеров) могут быть любыми. Для путей вне структур
+// спецификации (types:, неизвестные ключи) возвращается nil.
+func SpecKeys(path []string) []SpecKey {
+	t := reflect.TypeOf(Spec{})
+	for _, segment := range path {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			var next reflect.Type
+			for _, f := range yamlFields(t) {
+				if f.key == segment {
+					next = f.typ
+					break
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			t = next
+		default:
+			return nil
+		}
+	}
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := yamlFields(t)
+	keys := make([]SpecKey, 0, len(fields))
+	for _, f := range fields {
+		id := f.owner + "." + f.key
+		keys = append(keys, SpecKey{Key: f.key, Description: fieldDescriptions[id], Values: fieldEnums[id]})
+	}
+	return keys
+}
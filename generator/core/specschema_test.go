@@ -0,0 +1,121 @@
+package core
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestLoadSpecRejectsUnknownKeys(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"spec.yaml": `version: 0.3
+knowlege: {}
+assistants:
+  writer:
+    model: m
+    ouput_type: string
+    temprature: 0.2
+    thread:
+      usee: main
+`,
+	})
+
+	_, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	merr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	want := map[string]string{
+		"knowlege":                      `unknown key "knowlege" (did you mean "knowledge"?)`,
+		"assistants.writer.ouput_type":  `unknown key "ouput_type" (did you mean "output_type"?)`,
+		"assistants.writer.temprature":  `unknown key "temprature" (did you mean "temperature"?)`,
+		"assistants.writer.thread.usee": `unknown key "usee" (did you mean "use"?)`,
+	}
+	if len(merr.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), merr.Errors)
+	}
+	for _, e := range merr.Errors {
+		if e.Code != CodeUnknownKey || want[e.Field] != e.Msg || !e.Pos.IsValid() {
+			t.Fatalf("unexpected error %+v", e)
+		}
+	}
+}
+
+func TestNearestKeyWithoutSuggestion(t *testing.T) {
+	if hint := nearestKey("workflows", []string{"types", "models", "assistants"}); hint != "" {
+		t.Fatalf("unexpected hint %q", hint)
+	}
+}
+
+func TestTypeExpressionPattern(t *testing.T) {
+	re := regexp.MustCompile(TypeExpressionPattern)
+	for _, expr := range []string{
+		"string", "string(1..100)", "string(2)", "string(email)", "int(0..)", "number(0..1)", "bool", "uuid",
+		"enum(draft, published)", "map(string, int)", "User", "$User", "$common.Address",
+		"$Item[]", "string[]", "$Item[](min:1, max:10)", "string[][]", "enum(a, b)[]",
+		"map(string, int(0..))", "map(string, map(string, $X[](max:3)))",
+		"oneOf($Refund, $Escalation)", "oneOf($A, $B, discriminator:kind)[]",
+		"string(/^[A-Z]{3}$/)", `string(1..9, /^(a|b)\/[)(]$/)[]`, "string(sku)",
+	} {
+		if !re.MatchString(expr) {
+			t.Errorf("expression %q must match", expr)
+		}
+	}
+	for _, expr := range []string{"", "string((1)", "$a.b.c", "1abc", "User[]]", "string(/(a)"} {
+		if re.MatchString(expr) {
+			t.Errorf("expression %q must not match", expr)
+		}
+	}
+}
+
+func TestSpecSchema(t *testing.T) {
+	schema := SpecSchema()
+	if schema["$id"] != SchemaURL || schema["additionalProperties"] != false {
+		t.Fatalf("unexpected root: %v", schema["$id"])
+	}
+	defs := schema["$defs"].(map[string]any)
+	assistant, ok := defs["AssistantSpec"].(map[string]any)
+	if !ok {
+		t.Fatalf("AssistantSpec is missing in $defs")
+	}
+	props := assistant["properties"].(map[string]any)
+	// Поля Generation встроены в ассистента
+	for _, key := range []string{"output_type", "temperature", "reasoning_effort", "thread"} {
+		if _, ok := props[key]; !ok {
+			t.Fatalf("assistant property %s is missing", key)
+		}
+	}
+	if _, ok := props["Resolved"]; ok {
+		t.Fatalf("resolved fields must not be in the schema")
+	}
+}
+
+func TestSpecKeys(t *testing.T) {
+	keys := func(path ...string) map[string]SpecKey {
+		out := make(map[string]SpecKey)
+		for _, k := range SpecKeys(path) {
+			out[k.Key] = k
+		}
+		return out
+	}
+
+	if _, ok := keys()["assistants"]; !ok {
+		t.Fatalf("root keys must include assistants")
+	}
+	assistant := keys("assistants", "writer")
+	if assistant["kind"].Values == nil || assistant["input_type"].Description == "" {
+		t.Fatalf("unexpected assistant keys: %+v", assistant)
+	}
+	if _, ok := assistant["temperature"]; !ok {
+		t.Fatalf("inline generation keys are missing")
+	}
+	if _, ok := keys("imports")["as"]; !ok {
+		t.Fatalf("import item keys are missing")
+	}
+	if _, ok := keys("assistants", "writer", "thread")["use"]; !ok {
+		t.Fatalf("thread binding keys are missing")
+	}
+	if SpecKeys([]string{"types", "User"}) != nil || SpecKeys([]string{"assistants", "writer", "bogus"}) != nil {
+		t.Fatalf("free-form and unknown paths must have no keys")
+	}
+}
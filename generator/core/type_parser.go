@@ -1,8 +1,9 @@
 package core
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"sort"
 	"strings"
 )
 
@@ -21,15 +22,26 @@ func NewTypeParser() *TypeParser {
 	}
 }
 
-// ParseTypes парсит секцию types из YAML
+// ParseTypes парсит секцию types из YAML. Ошибки всех типов собираются в *MultiError;
+// реестр с успешно разобранными типами возвращается и при ошибках.
 func (p *TypeParser) ParseTypes(types map[string]interface{}) (*TypeRegistry, error) {
+	merr := &MultiError{}
 	for typeName, typeData := range types {
 		typeDef, err := p.parseTypeDefinition(typeName, typeData)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse type %s: %w", typeName, err)
+			merr.Append(typeError("types."+typeName, err))
+			// Ссылки на такой тип не считаются неопределёнными: ошибка уже выдана
+			if p.registry.invalid == nil {
+				p.registry.invalid = make(map[string]bool)
+			}
+			p.registry.invalid[typeName] = true
+			continue
 		}
 		p.registry.Types[typeName] = typeDef
 	}
+	if merr.HasErrors() {
+		return p.registry, merr
+	}
 	return p.registry, nil
 }
 
@@ -46,6 +58,17 @@ func (p *TypeParser) parseTypeDefinition(name string, data interface{}) (*TypeDe
 		return td, nil
 	}
 
+	// Расширенная форма: выражение в type: и метаданные
+	if spec, ok := fieldSpec(data); ok {
+		td, err := parseFieldSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		td.Name = name
+		td.ID = fmt.Sprintf("aiwf://%s", name)
+		return td, nil
+	}
+
 	// Если это map, то это объект с полями
 	if obj, ok := data.(map[interface{}]interface{}); ok {
 		return p.parseObjectType(name, obj)
@@ -84,7 +107,7 @@ func (p *TypeParser) parseObjectType(name string, obj map[interface{}]interface{
 
 		fieldType, err := p.parseFieldType(actualFieldName, value)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse field %s: %w", fieldName, err)
+			return nil, wrapFieldError(actualFieldName, err)
 		}
 		fieldType.Optional = isOptional
 		typeDef.Properties[actualFieldName] = fieldType
@@ -113,7 +136,7 @@ func (p *TypeParser) parseObjectTypeString(name string, obj map[string]interface
 
 		fieldType, err := p.parseFieldType(actualFieldName, value)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse field %s: %w", fieldName, err)
+			return nil, wrapFieldError(actualFieldName, err)
 		}
 		fieldType.Optional = isOptional
 		typeDef.Properties[actualFieldName] = fieldType
@@ -129,6 +152,11 @@ func (p *TypeParser) parseFieldType(fieldName string, value interface{}) (*TypeD
 		return ParseTypeExpression(expr)
 	}
 
+	// Расширенная форма поля: type, description, default, examples
+	if spec, ok := fieldSpec(value); ok {
+		return parseFieldSpec(spec)
+	}
+
 	// Если это вложенный объект
 	if obj, ok := value.(map[interface{}]interface{}); ok {
 		return p.parseObjectType(fieldName, obj)
@@ -146,7 +174,7 @@ func (p *TypeParser) parseFieldType(fieldName string, value interface{}) (*TypeD
 		// Парсим первый элемент как тип элемента массива
 		itemType, err := p.parseFieldType(fieldName+"_item", arr[0])
 		if err != nil {
-			return nil, err
+			return nil, wrapFieldError("[0]", err)
 		}
 		return &TypeDef{
 			Kind:  KindArray,
@@ -157,149 +185,132 @@ func (p *TypeParser) parseFieldType(fieldName string, value interface{}) (*TypeD
 	return nil, fmt.Errorf("unexpected field type for %s: %T", fieldName, value)
 }
 
-// ParseTypeExpressionFull парсит полное выражение типа с ограничениями
-func ParseTypeExpressionFull(expr string) (*TypeDef, error) {
-	expr = strings.TrimSpace(expr)
-
-	// Проверяем на массив с ограничениями: Type[](min:1, max:10)
-	if idx := strings.Index(expr, "[]("); idx > 0 && strings.HasSuffix(expr, ")") {
-		baseExpr := expr[:idx]
-		constraints := expr[idx+3 : len(expr)-1]
-
-		itemType, err := ParseTypeExpression(baseExpr)
-		if err != nil {
-			return nil, err
-		}
-
-		arrayType := &TypeDef{
-			Kind:  KindArray,
-			Items: itemType,
+// fieldSpecMarker — ключ расширенной формы поля. Имя поля не может начинаться
+// с $, поэтому объект с полем type и форма с $type не путаются.
+const fieldSpecMarker = "$type"
+
+// fieldSpecKeys — ключи расширенной формы поля.
+var fieldSpecKeys = map[string]bool{fieldSpecMarker: true, "description": true, "default": true, "examples": true}
+
+// fieldSpec распознаёт расширенную форму поля: отображение с ключом $type.
+// Остальные отображения — вложенные объекты.
+func fieldSpec(value interface{}) (map[string]interface{}, bool) {
+	spec := make(map[string]interface{})
+	switch obj := value.(type) {
+	case map[string]interface{}:
+		for k, v := range obj {
+			spec[k] = v
 		}
-
-		// Парсим ограничения массива
-		for _, constraint := range strings.Split(constraints, ",") {
-			constraint = strings.TrimSpace(constraint)
-			if strings.HasPrefix(constraint, "min:") {
-				val, err := strconv.Atoi(strings.TrimPrefix(constraint, "min:"))
-				if err != nil {
-					return nil, fmt.Errorf("invalid min value: %s", constraint)
-				}
-				arrayType.MinItems = &val
-			} else if strings.HasPrefix(constraint, "max:") {
-				val, err := strconv.Atoi(strings.TrimPrefix(constraint, "max:"))
-				if err != nil {
-					return nil, fmt.Errorf("invalid max value: %s", constraint)
-				}
-				arrayType.MaxItems = &val
+	case map[interface{}]interface{}:
+		for k, v := range obj {
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
 			}
+			spec[key] = v
 		}
-
-		return arrayType, nil
+	default:
+		return nil, false
 	}
-
-	// Проверяем на enum и map до проверки на скобки
-	if strings.HasPrefix(expr, "enum(") || strings.HasPrefix(expr, "map(") {
-		return ParseTypeExpression(expr)
+	if _, ok := spec[fieldSpecMarker]; !ok {
+		return nil, false
 	}
+	return spec, true
+}
 
-	// Проверяем на тип с ограничениями: string(1..100) или int(0..999)
-	if idx := strings.Index(expr, "("); idx > 0 && strings.HasSuffix(expr, ")") {
-		baseType := expr[:idx]
-		constraints := expr[idx+1 : len(expr)-1]
-
-		td := &TypeDef{}
-
-		switch baseType {
-		case "string":
-			td.Kind = KindString
-			if err := parseStringConstraints(td, constraints); err != nil {
-				return nil, err
-			}
-		case "int":
-			td.Kind = KindInt
-			if err := parseNumberConstraints(td, constraints); err != nil {
-				return nil, err
-			}
-		case "number":
-			td.Kind = KindNumber
-			if err := parseNumberConstraints(td, constraints); err != nil {
-				return nil, err
-			}
-		default:
-			// Treat as reference with constraints (shouldn't happen for refs, but be safe)
-			td = &TypeDef{
-				Kind: KindRef,
-				Ref:  baseType,
-			}
+// parseFieldSpec разбирает расширенную форму поля; ошибки выражения относятся к ключу $type.
+func parseFieldSpec(spec map[string]interface{}) (*TypeDef, error) {
+	var unknown []string
+	for key := range spec {
+		if !fieldSpecKeys[key] {
+			unknown = append(unknown, key)
 		}
-
-		return td, nil
 	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, wrapFieldError(unknown[0], fmt.Errorf("unknown key %q (allowed: $type, description, default, examples)", unknown[0]))
+	}
+	expr, ok := spec[fieldSpecMarker].(string)
+	if !ok {
+		return nil, wrapFieldError(fieldSpecMarker, fmt.Errorf("$type must be a type expression"))
+	}
+	td, err := ParseTypeExpression(expr)
+	if err != nil {
+		return nil, wrapFieldError(fieldSpecMarker, err)
+	}
+	if v, ok := spec["description"]; ok {
+		description, ok := v.(string)
+		if !ok {
+			return nil, wrapFieldError("description", fmt.Errorf("description must be a string"))
+		}
+		td.Description = strings.TrimSpace(description)
+	}
+	if v, ok := spec["default"]; ok {
+		td.Default = v
+	}
+	if v, ok := spec["examples"]; ok {
+		examples, ok := v.([]interface{})
+		if !ok {
+			return nil, wrapFieldError("examples", fmt.Errorf("examples must be a list"))
+		}
+		td.Examples = examples
+	}
+	return td, nil
+}
 
-	// Остальное обрабатывается базовой функцией
-	return ParseTypeExpression(expr)
+// fieldError — ошибка в поле объектного типа; path — имена полей от типа до
+// ошибки (без суффикса ?, как в остальных путях полей).
+type fieldError struct {
+	path []string
+	err  error
 }
 
-// parseStringConstraints парсит ограничения для строк
-func parseStringConstraints(td *TypeDef, constraints string) error {
-	// Проверяем диапазон длины: 1..100
-	if strings.Contains(constraints, "..") {
-		parts := strings.Split(constraints, "..")
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid range format: %s", constraints)
-		}
+func (e *fieldError) Error() string {
+	return fmt.Sprintf("failed to parse field %s: %v", joinFieldPath("", e.path), e.err)
+}
 
-		if parts[0] != "" {
-			min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
-			if err != nil {
-				return fmt.Errorf("invalid min length: %s", parts[0])
-			}
-			td.MinLength = &min
-		}
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
 
-		if parts[1] != "" {
-			max, err := strconv.Atoi(strings.TrimSpace(parts[1]))
-			if err != nil {
-				return fmt.Errorf("invalid max length: %s", parts[1])
-			}
-			td.MaxLength = &max
-		}
-	} else {
-		// Это может быть формат: email, url, phone
-		td.Format = constraints
+func wrapFieldError(field string, err error) error {
+	if fe, ok := err.(*fieldError); ok {
+		return &fieldError{path: append([]string{field}, fe.path...), err: fe.err}
 	}
-
-	return nil
+	return &fieldError{path: []string{field}, err: err}
 }
 
-// parseNumberConstraints парсит ограничения для чисел
-func parseNumberConstraints(td *TypeDef, constraints string) error {
-	// Проверяем диапазон: 0..100 или 1..
-	if strings.Contains(constraints, "..") {
-		parts := strings.Split(constraints, "..")
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid range format: %s", constraints)
-		}
-
-		if parts[0] != "" {
-			min, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
-			if err != nil {
-				return fmt.Errorf("invalid min value: %s", parts[0])
-			}
-			td.Min = &min
-		}
+// typeError переводит ошибку разбора типа в ValidationError с путём до поля и
+// смещением внутри выражения, чтобы позиция указывала на место ошибки.
+func typeError(field string, err error) *ValidationError {
+	verr := &ValidationError{Code: CodeInvalidType, Field: field, Msg: err.Error()}
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		verr.Field = joinFieldPath(field, fe.path)
+		verr.Msg = fe.err.Error()
+	}
+	var te *TypeExprError
+	if errors.As(err, &te) {
+		verr.exprOffset = te.Offset
+	}
+	return verr
+}
 
-		if parts[1] != "" {
-			max, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-			if err != nil {
-				return fmt.Errorf("invalid max value: %s", parts[1])
-			}
-			td.Max = &max
+// joinFieldPath добавляет к пути ключи полей; индексы [n] пишутся без точки.
+func joinFieldPath(field string, path []string) string {
+	for _, seg := range path {
+		if strings.HasPrefix(seg, "[") {
+			field += seg
+			continue
 		}
-	} else {
-		// Одно число - точное значение?
-		return fmt.Errorf("single number constraint not supported: %s", constraints)
+		field = joinField(field, seg)
 	}
+	return field
+}
 
-	return nil
-}
\ No newline at end of file
+// ParseTypeExpressionFull парсит полное выражение типа с ограничениями.
+//
+// Deprecated: ParseTypeExpression разбирает те же выражения.
+func ParseTypeExpressionFull(expr string) (*TypeDef, error) {
+	return ParseTypeExpression(expr)
+}
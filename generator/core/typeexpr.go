@@ -0,0 +1,755 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Грамматика выражений типов (пробелы между лексемами допускаются):
+//
+//	expr       = base { "[]" [ "(" arrayArgs ")" ] } .
+//	base       = "string" [ "(" stringArg { "," stringArg } ")" ]
+//	           | ( "int" | "number" ) [ "(" range ")" ]
+//	           | "bool" | "datetime" | "date" | "uuid" | "any"
+//	           | "enum" "(" value { "," value } ")"
+//	           | "map" "(" "string" "," expr ")"
+//	           | "oneOf" "(" ref "," ref { "," ref } [ "," "discriminator" ":" ident ] ")"
+//	           | ref .
+//	range      = [ number ] ".." [ number ] .      // хотя бы одна граница
+//	stringArg  = range | format | pattern .        // формат и шаблон не сочетаются
+//	format     = ident .                           // email, url, phone, ... или из formats:
+//	pattern    = "/" regex "/" .                   // "/" внутри шаблона пишется как \/
+//	arrayArgs  = arrayArg { "," arrayArg } .
+//	arrayArg   = ( "min" | "max" ) ":" integer .
+//	ref        = [ "$" ] ident [ "." ident ] .     // User, $User, $common.Address
+//	value      = любые символы, кроме "," и ")" .   // пробелы по краям отбрасываются
+//
+// Имена примитивов без $ — всегда примитивы; enum, map и oneOf — ключевые слова
+// только перед "(". Всё остальное — ссылка на тип.
+
+// TypeExprError — ошибка разбора выражения типа с позицией внутри выражения.
+type TypeExprError struct {
+	Expr   string
+	Offset int // смещение в байтах от начала выражения
+	Msg    string
+}
+
+// Column возвращает номер символа ошибки в выражении, считая с 1.
+func (e *TypeExprError) Column() int {
+	return utf8.RuneCountInString(e.Expr[:min(e.Offset, len(e.Expr))]) + 1
+}
+
+func (e *TypeExprError) Error() string {
+	return fmt.Sprintf("invalid type expression %q: %s (column %d)", e.Expr, e.Msg, e.Column())
+}
+
+// TypeExprRef — ссылка на тип в выражении.
+type TypeExprRef struct {
+	Offset int    // смещение в байтах, включая $
+	Len    int    // длина в байтах, включая $
+	Name   string // без $: User, common.Address
+}
+
+// TypeExpressionRefs возвращает ссылки на типы в выражении в порядке появления.
+func TypeExpressionRefs(expr string) ([]TypeExprRef, error) {
+	p := newExprParser(expr)
+	if _, err := p.parse(); err != nil {
+		return p.refs, err
+	}
+	return p.refs, nil
+}
+
+func parseTypeExpr(expr string) (*TypeDef, error) {
+	return newExprParser(expr).parse()
+}
+
+// tokenKind — вид лексемы выражения типа.
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIdent            // string, User, email
+	tokNumber           // 10, -1.5
+	tokDollar           // $
+	tokDot              // .
+	tokRange            // ..
+	tokLParen           // (
+	tokRParen           // )
+	tokArray            // []
+	tokComma            // ,
+	tokColon            // :
+)
+
+var tokenNames = map[tokenKind]string{
+	tokEOF:    "end of expression",
+	tokIdent:  "name",
+	tokNumber: "number",
+	tokDollar: `"$"`,
+	tokDot:    `"."`,
+	tokRange:  `".."`,
+	tokLParen: `"("`,
+	tokRParen: `")"`,
+	tokArray:  `"[]"`,
+	tokComma:  `","`,
+	tokColon:  `":"`,
+}
+
+var singleTokens = map[byte]tokenKind{'$': tokDollar, '(': tokLParen, ')': tokRParen, ',': tokComma, ':': tokColon}
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokIdent, tokNumber:
+		return strconv.Quote(t.text)
+	}
+	return tokenNames[t.kind]
+}
+
+// lexer разбивает выражение на лексемы по запросу парсера: значения enum(...)
+// читаются отдельно методом raw.
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case singleTokens[c] != 0:
+		l.pos++
+		return token{kind: singleTokens[c], text: l.src[start:l.pos], pos: start}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "..") {
+			l.pos += 2
+			return token{kind: tokRange, text: "..", pos: start}, nil
+		}
+		l.pos++
+		return token{kind: tokDot, text: ".", pos: start}, nil
+	case c == '[':
+		if strings.HasPrefix(l.src[l.pos:], "[]") {
+			l.pos += 2
+			return token{kind: tokArray, text: "[]", pos: start}, nil
+		}
+		return token{}, &TypeExprError{Expr: l.src, Offset: start, Msg: `expected "[]"`}
+	case isIdentStart(c):
+		for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	case isDigit(c) || c == '-':
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		// Дробная часть: точка, за которой цифра (1..10 — это диапазон)
+		if l.pos+1 < len(l.src) && l.src[l.pos] == '.' && isDigit(l.src[l.pos+1]) {
+			l.pos++
+			for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+				l.pos++
+			}
+		}
+		text := l.src[start:l.pos]
+		if text == "-" {
+			return token{}, &TypeExprError{Expr: l.src, Offset: start, Msg: "expected number after \"-\""}
+		}
+		return token{kind: tokNumber, text: text, pos: start}, nil
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, &TypeExprError{Expr: l.src, Offset: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+}
+
+// raw читает значение enum до "," или ")" без учёта лексем.
+func (l *lexer) raw() (string, int) {
+	l.skipSpace()
+	start := l.pos
+	for l.pos < len(l.src) && l.src[l.pos] != ',' && l.src[l.pos] != ')' {
+		l.pos++
+	}
+	return strings.TrimSpace(l.src[start:l.pos]), start
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// exprParser — разбор выражения рекурсивным спуском с одной лексемой предпросмотра.
+type exprParser struct {
+	lex  lexer
+	tok  token
+	err  error
+	refs []TypeExprRef
+}
+
+func newExprParser(expr string) *exprParser {
+	p := &exprParser{lex: lexer{src: expr}}
+	p.advance()
+	return p
+}
+
+func (p *exprParser) advance() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+}
+
+func (p *exprParser) fail(pos int, format string, args ...any) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.err = &TypeExprError{Expr: p.lex.src, Offset: pos, Msg: fmt.Sprintf(format, args...)}
+	return p.err
+}
+
+func (p *exprParser) expect(kind tokenKind) (token, error) {
+	tok, err := p.expectCurrent(kind)
+	if err != nil {
+		return tok, err
+	}
+	p.advance()
+	return tok, p.err
+}
+
+func (p *exprParser) parse() (*TypeDef, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, p.fail(0, "empty type expression")
+	}
+	td, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.fail(p.tok.pos, "unexpected %s", p.tok)
+	}
+	return td, nil
+}
+
+func (p *exprParser) parseExpr() (*TypeDef, error) {
+	td, err := p.parseBase()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokArray {
+		p.advance()
+		td = &TypeDef{Kind: KindArray, Items: td}
+		if p.tok.kind == tokLParen {
+			if err := p.parseArrayArgs(td); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return td, p.err
+}
+
+func (p *exprParser) parseBase() (*TypeDef, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokDollar:
+		p.advance()
+		return p.parseRef(tok.pos, true)
+	case tokIdent:
+	default:
+		return nil, p.fail(tok.pos, "expected type, got %s", tok)
+	}
+
+	switch tok.text {
+	case "string", "int", "number":
+		p.advance()
+		td := &TypeDef{Kind: TypeKind(tok.text)}
+		if td.Kind == KindString && p.tok.kind == tokLParen {
+			return td, p.parseStringArgs(td)
+		}
+		if p.tok.kind == tokLParen {
+			p.advance()
+			if err := p.parseConstraint(td); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokRParen); err != nil {
+				return nil, err
+			}
+		}
+		return td, p.err
+	case "bool", "datetime", "date", "uuid", "any":
+		p.advance()
+		if p.tok.kind == tokLParen {
+			return nil, p.fail(p.tok.pos, "%s does not take constraints", tok.text)
+		}
+		return &TypeDef{Kind: TypeKind(tok.text)}, p.err
+	case "enum", "map", "oneOf":
+		// Ключевое слово только перед скобкой, иначе это имя типа
+		if p.lex.peekByte() == '(' {
+			p.advance()
+			switch tok.text {
+			case "enum":
+				return p.parseEnum()
+			case "map":
+				return p.parseMap()
+			}
+			return p.parseOneOf(tok.pos)
+		}
+	}
+	return p.parseRef(tok.pos, false)
+}
+
+// peekByte возвращает следующий непробельный символ после текущей лексемы.
+func (l *lexer) peekByte() byte {
+	l.skipSpace()
+	if l.pos < len(l.src) {
+		return l.src[l.pos]
+	}
+	return 0
+}
+
+// parseRef разбирает ссылку: текущая лексема — имя (после $, если dollar).
+func (p *exprParser) parseRef(start int, dollar bool) (*TypeDef, error) {
+	name, err := p.expect(tokIdent)
+	if err != nil {
+		return nil, err
+	}
+	ref := name.text
+	end := name.pos + len(name.text)
+	if p.tok.kind == tokDot {
+		p.advance()
+		typeName, err := p.expect(tokIdent)
+		if err != nil {
+			return nil, err
+		}
+		ref += "." + typeName.text
+		end = typeName.pos + len(typeName.text)
+	}
+	p.refs = append(p.refs, TypeExprRef{Offset: start, Len: end - start, Name: ref})
+	if dollar {
+		ref = "$" + ref
+	}
+	return &TypeDef{Kind: KindRef, Ref: ref}, p.err
+}
+
+// parseStringArgs разбирает аргументы string(...); текущая лексема — "(".
+// Шаблон /regex/ читается лексером как есть, поэтому перед каждым аргументом
+// проверяется следующий символ.
+func (p *exprParser) parseStringArgs(td *TypeDef) error {
+	hasRange := false
+	for {
+		if p.lex.peekByte() == '/' {
+			if err := p.parsePattern(td); err != nil {
+				return err
+			}
+		} else {
+			p.advance()
+			switch {
+			case p.err != nil:
+				return p.err
+			case p.tok.kind == tokIdent:
+				if td.Format != "" || td.Pattern != "" {
+					return p.fail(p.tok.pos, "string takes either one format or one pattern")
+				}
+				td.Format = p.tok.text
+				p.advance()
+			default:
+				if hasRange {
+					return p.fail(p.tok.pos, "string takes only one length range")
+				}
+				hasRange = true
+				if err := p.parseConstraint(td); err != nil {
+					return err
+				}
+			}
+		}
+		if p.err != nil {
+			return p.err
+		}
+		if p.tok.kind != tokComma {
+			_, err := p.expect(tokRParen)
+			return err
+		}
+	}
+}
+
+// parsePattern разбирает шаблон /regex/ и проверяет, что он компилируется.
+func (p *exprParser) parsePattern(td *TypeDef) error {
+	pattern, start, ok := p.lex.pattern()
+	switch {
+	case !ok:
+		return p.fail(start, `unterminated pattern, expected closing "/"`)
+	case pattern == "":
+		return p.fail(start, "empty pattern")
+	case td.Format != "" || td.Pattern != "":
+		return p.fail(start, "string takes either one format or one pattern")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return p.fail(start, "invalid pattern: %v", err)
+	}
+	td.Pattern = pattern
+	p.advance()
+	return p.err
+}
+
+// pattern читает шаблон между "/" без учёта лексем; \/ заменяется на /.
+func (l *lexer) pattern() (string, int, bool) {
+	l.skipSpace()
+	start := l.pos
+	var b strings.Builder
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		switch c := l.src[l.pos]; {
+		case c == '/':
+			l.pos++
+			return b.String(), start, true
+		case c == '\\' && l.pos+1 < len(l.src):
+			l.pos++
+			if l.src[l.pos] != '/' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(l.src[l.pos])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", start, false
+}
+
+// parseConstraint разбирает диапазон в скобках string(...), int(...), number(...).
+// Для строк одно число задаёт точную длину: string(2) — то же, что string(2..2).
+func (p *exprParser) parseConstraint(td *TypeDef) error {
+	start := p.tok.pos
+	lo, hi, err := p.parseRange(td.Kind == KindString)
+	if err != nil {
+		return err
+	}
+	if td.Kind == KindString {
+		minLen, err := p.lengthBound(lo, start)
+		if err != nil {
+			return err
+		}
+		maxLen, err := p.lengthBound(hi, start)
+		if err != nil {
+			return err
+		}
+		td.MinLength, td.MaxLength = minLen, maxLen
+		return nil
+	}
+	if td.Kind == KindInt {
+		for _, b := range []*float64{lo, hi} {
+			if b != nil && *b != math.Trunc(*b) {
+				return p.fail(start, "int bounds must be integers")
+			}
+		}
+	}
+	td.Min, td.Max = lo, hi
+	return nil
+}
+
+// parseRange разбирает [число] ".." [число]; при exact допускается одно число
+// без "..", оно становится обеими границами.
+func (p *exprParser) parseRange(exact bool) (lo, hi *float64, err error) {
+	start := p.tok.pos
+	if p.tok.kind == tokNumber {
+		if lo, err = p.number(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if p.tok.kind != tokRange {
+		if lo != nil && exact {
+			return lo, lo, p.err
+		}
+		if lo == nil {
+			return nil, nil, p.fail(p.tok.pos, "expected range like 1..100, got %s", p.tok)
+		}
+		return nil, nil, p.fail(p.tok.pos, `expected "..", got %s`, p.tok)
+	}
+	p.advance()
+	if p.tok.kind == tokNumber {
+		if hi, err = p.number(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if lo == nil && hi == nil {
+		return nil, nil, p.fail(start, "range needs at least one bound")
+	}
+	if lo != nil && hi != nil && *lo > *hi {
+		return nil, nil, p.fail(start, "range minimum %s is greater than maximum %s", formatNumber(*lo), formatNumber(*hi))
+	}
+	return lo, hi, p.err
+}
+
+func (p *exprParser) number() (*float64, error) {
+	tok := p.tok
+	v, err := strconv.ParseFloat(tok.text, 64)
+	if err != nil {
+		return nil, p.fail(tok.pos, "invalid number %q", tok.text)
+	}
+	p.advance()
+	return &v, p.err
+}
+
+func (p *exprParser) lengthBound(v *float64, pos int) (*int, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if *v < 0 || *v != math.Trunc(*v) || *v > math.MaxInt32 {
+		return nil, p.fail(pos, "string length must be a non-negative integer")
+	}
+	n := int(*v)
+	return &n, nil
+}
+
+// parseEnum разбирает значения enum; текущая лексема — "(", после неё лексер
+// читает сырые значения.
+func (p *exprParser) parseEnum() (*TypeDef, error) {
+	if _, err := p.expectCurrent(tokLParen); err != nil {
+		return nil, err
+	}
+	td := &TypeDef{Kind: KindEnum}
+	for {
+		value, pos := p.lex.raw()
+		if value == "" {
+			return nil, p.fail(pos, "empty enum value")
+		}
+		td.Enum = append(td.Enum, value)
+		if p.lex.pos >= len(p.lex.src) {
+			return nil, p.fail(p.lex.pos, `expected ")" to close enum`)
+		}
+		sep := p.lex.src[p.lex.pos]
+		p.lex.pos++
+		if sep == ')' {
+			break
+		}
+	}
+	p.advance()
+	return td, p.err
+}
+
+// expectCurrent проверяет текущую лексему, не читая следующую.
+func (p *exprParser) expectCurrent(kind tokenKind) (token, error) {
+	if p.err != nil {
+		return token{}, p.err
+	}
+	if p.tok.kind != kind {
+		return p.tok, p.fail(p.tok.pos, "expected %s, got %s", tokenNames[kind], p.tok)
+	}
+	return p.tok, nil
+}
+
+func (p *exprParser) parseMap() (*TypeDef, error) {
+	if _, err := p.expect(tokLParen); err != nil {
+		return nil, err
+	}
+	key, err := p.expect(tokIdent)
+	if err != nil {
+		return nil, err
+	}
+	if key.text != "string" {
+		return nil, p.fail(key.pos, "map keys must be string, got %q", key.text)
+	}
+	if _, err := p.expect(tokComma); err != nil {
+		return nil, err
+	}
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokRParen); err != nil {
+		return nil, err
+	}
+	return &TypeDef{Kind: KindMap, ValueType: value}, nil
+}
+
+// parseOneOf разбирает варианты объединения и необязательный discriminator:поле.
+func (p *exprParser) parseOneOf(start int) (*TypeDef, error) {
+	if _, err := p.expect(tokLParen); err != nil {
+		return nil, err
+	}
+	td := &TypeDef{Kind: KindOneOf}
+	for {
+		if p.tok.kind == tokIdent && p.tok.text == "discriminator" && p.lex.peekByte() == ':' {
+			p.advance()
+			p.advance()
+			field, err := p.expect(tokIdent)
+			if err != nil {
+				return nil, err
+			}
+			td.Discriminator = field.text
+			break
+		}
+		pos := p.tok.pos
+		variant, err := p.parseBase()
+		if err != nil {
+			return nil, err
+		}
+		if variant.Kind != KindRef {
+			return nil, p.fail(pos, "oneOf variants must be type references like $Refund")
+		}
+		td.Variants = append(td.Variants, variant)
+		if p.tok.kind != tokComma {
+			break
+		}
+		p.advance()
+	}
+	if len(td.Variants) < 2 {
+		return nil, p.fail(start, "oneOf needs at least two variants")
+	}
+	if _, err := p.expect(tokRParen); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+func (p *exprParser) parseArrayArgs(td *TypeDef) error {
+	if _, err := p.expect(tokLParen); err != nil {
+		return err
+	}
+	for {
+		name, err := p.expect(tokIdent)
+		if err != nil {
+			return err
+		}
+		if name.text != "min" && name.text != "max" {
+			return p.fail(name.pos, "unknown array constraint %q (expected min or max)", name.text)
+		}
+		if _, err := p.expect(tokColon); err != nil {
+			return err
+		}
+		tok, err := p.expect(tokNumber)
+		if err != nil {
+			return err
+		}
+		n, convErr := strconv.Atoi(tok.text)
+		if convErr != nil || n < 0 {
+			return p.fail(tok.pos, "array %s must be a non-negative integer", name.text)
+		}
+		if name.text == "min" {
+			td.MinItems = &n
+		} else {
+			td.MaxItems = &n
+		}
+		if p.tok.kind != tokComma {
+			break
+		}
+		p.advance()
+	}
+	if td.MinItems != nil && td.MaxItems != nil && *td.MinItems > *td.MaxItems {
+		return p.fail(p.tok.pos, "array min %d is greater than max %d", *td.MinItems, *td.MaxItems)
+	}
+	_, err := p.expect(tokRParen)
+	return err
+}
+
+// FormatTypeExpression записывает тип в каноническом виде выражения: ссылки с $,
+// пробел после запятых, границы без лишних нулей. Результат разбирается обратно
+// в тот же TypeDef. Для объектов возвращается "object".
+func FormatTypeExpression(td *TypeDef) string {
+	if td == nil {
+		return "any"
+	}
+	switch td.Kind {
+	case KindRef:
+		return "$" + strings.TrimPrefix(td.Ref, "$")
+	case KindString:
+		var args []string
+		switch {
+		case td.MinLength != nil && td.MaxLength != nil && *td.MinLength == *td.MaxLength:
+			args = append(args, strconv.Itoa(*td.MinLength))
+		case td.MinLength != nil || td.MaxLength != nil:
+			args = append(args, formatRange(intPtrToFloat(td.MinLength), intPtrToFloat(td.MaxLength)))
+		}
+		if td.Format != "" {
+			args = append(args, td.Format)
+		}
+		if td.Pattern != "" {
+			args = append(args, "/"+strings.ReplaceAll(td.Pattern, "/", `\/`)+"/")
+		}
+		if len(args) > 0 {
+			return "string(" + strings.Join(args, ", ") + ")"
+		}
+	case KindInt, KindNumber:
+		if td.Min != nil || td.Max != nil {
+			return string(td.Kind) + "(" + formatRange(td.Min, td.Max) + ")"
+		}
+	case KindEnum:
+		return "enum(" + strings.Join(td.Enum, ", ") + ")"
+	case KindMap:
+		return "map(string, " + FormatTypeExpression(td.ValueType) + ")"
+	case KindOneOf:
+		args := make([]string, 0, len(td.Variants)+1)
+		for _, variant := range td.Variants {
+			args = append(args, FormatTypeExpression(variant))
+		}
+		if td.Discriminator != "" {
+			args = append(args, "discriminator:"+td.Discriminator)
+		}
+		return "oneOf(" + strings.Join(args, ", ") + ")"
+	case KindArray:
+		s := FormatTypeExpression(td.Items) + "[]"
+		var args []string
+		if td.MinItems != nil {
+			args = append(args, fmt.Sprintf("min:%d", *td.MinItems))
+		}
+		if td.MaxItems != nil {
+			args = append(args, fmt.Sprintf("max:%d", *td.MaxItems))
+		}
+		if len(args) > 0 {
+			s += "(" + strings.Join(args, ", ") + ")"
+		}
+		return s
+	}
+	return string(td.Kind)
+}
+
+func formatRange(lo, hi *float64) string {
+	var s string
+	if lo != nil {
+		s = formatNumber(*lo)
+	}
+	s += ".."
+	if hi != nil {
+		s += formatNumber(*hi)
+	}
+	return s
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func intPtrToFloat(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
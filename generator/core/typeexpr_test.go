@@ -0,0 +1,271 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTypeExpressionForms(t *testing.T) {
+	tests := []struct {
+		expr      string
+		canonical string
+	}{
+		{"string", "string"},
+		{"string(1..100)", "string(1..100)"},
+		{"string(10..)", "string(10..)"},
+		{"string(..500)", "string(..500)"},
+		{"string(2)", "string(2)"},
+		{"string(3..3)", "string(3)"},
+		{"string(2, /^[a-z]+$/)", "string(2, /^[a-z]+$/)"},
+		{"string(email)", "string(email)"},
+		{"string(/^[A-Z]{3}$/)", "string(/^[A-Z]{3}$/)"},
+		{"string( email , 3.. )", "string(3.., email)"},
+		{`string(/^a\/(b|c),d$/, 1..9)[]`, `string(1..9, /^a\/(b|c),d$/)[]`},
+		{"int(0..150)", "int(0..150)"},
+		{"int(-10..)", "int(-10..)"},
+		{"number(0..1)", "number(0..1)"},
+		{"number(0.5 .. 2.25)", "number(0.5..2.25)"},
+		{"bool", "bool"},
+		{"enum(active,inactive , pending)", "enum(active, inactive, pending)"},
+		{"enum(a, b)[]", "enum(a, b)[]"},
+		{"string[]", "string[]"},
+		{"User[]", "$User[]"},
+		{"$Finding[](min:1, max:10)", "$Finding[](min:1, max:10)"},
+		{"string[](max:10)", "string[](max:10)"},
+		{"string[][]", "string[][]"},
+		{"map(string, any)", "map(string, any)"},
+		{"map(string, $X[])", "map(string, $X[])"},
+		{"map( string , map(string, int(0..)) )", "map(string, map(string, int(0..)))"},
+		{"$common.Address", "$common.Address"},
+		{"enum", "$enum"},
+		{"$string", "$string"},
+		{"oneOf($Refund, Escalation)", "oneOf($Refund, $Escalation)"},
+		{"oneOf( $A,$B , discriminator : kind )", "oneOf($A, $B, discriminator:kind)"},
+		{"oneOf($A, $discriminator)[]", "oneOf($A, $discriminator)[]"},
+		{"oneOf", "$oneOf"},
+	}
+	for _, tt := range tests {
+		td, err := ParseTypeExpression(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := FormatTypeExpression(td); got != tt.canonical {
+			t.Errorf("%s: canonical %q, want %q", tt.expr, got, tt.canonical)
+		}
+	}
+
+	td, _ := ParseTypeExpression("$Finding[](min:1, max:10)")
+	if td.Kind != KindArray || *td.MinItems != 1 || *td.MaxItems != 10 || td.Items.Ref != "$Finding" {
+		t.Fatalf("unexpected array: %+v", td)
+	}
+	td, _ = ParseTypeExpression(`string(/^a\/b$/)`)
+	if td.Pattern != "^a/b$" {
+		t.Fatalf("unexpected pattern: %q", td.Pattern)
+	}
+	td, _ = ParseTypeExpression("string(..500)")
+	if td.MinLength != nil || *td.MaxLength != 500 {
+		t.Fatalf("unexpected string bounds: %+v", td)
+	}
+	td, _ = ParseTypeExpression("string(2)")
+	if *td.MinLength != 2 || *td.MaxLength != 2 {
+		t.Fatalf("expected exact length, got %+v", td)
+	}
+	td, _ = ParseTypeExpression("map(string, $X[])")
+	if td.ValueType.Kind != KindArray || td.ValueType.Items.Ref != "$X" {
+		t.Fatalf("unexpected map value: %+v", td.ValueType)
+	}
+}
+
+func TestParseTypeExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		offset int
+	}{
+		{"", 0},
+		{"string(1..x)", 10},
+		{"string(..)", 7},
+		{"int(5..1)", 4},
+		{"int(0.5..1)", 4},
+		{"bool(1..2)", 4},
+		{"map(int, string)", 4},
+		{"map(string string)", 11},
+		{"enum()", 5},
+		{"enum(a, b", 9},
+		{"$User[](min:1, size:2)", 15},
+		{"$User[](min:3, max:1)", 20},
+		{"$User]", 5},
+		{"User extra", 5},
+		{"$a.b.c", 4},
+		{"string(1..100", 13},
+		{"oneOf($A)", 0},
+		{"oneOf(string, $B)", 6},
+		{"oneOf($A, $B, discriminator:)", 28},
+		{"oneOf($A, $B, discriminator:kind, $C)", 32},
+		{"string(/abc", 7},
+		{"string(//)", 7},
+		{"string(/[/)", 7},
+		{"string(email, /a/)", 14},
+		{"string(/a/, url)", 12},
+		{"string(1..2, 3..4)", 13},
+		{"int(5)", 5},
+		{"string(-1)", 7},
+	}
+	for _, tt := range tests {
+		_, err := ParseTypeExpression(tt.expr)
+		var te *TypeExprError
+		if !errors.As(err, &te) {
+			t.Errorf("%q: expected *TypeExprError, got %v", tt.expr, err)
+			continue
+		}
+		if te.Offset != tt.offset {
+			t.Errorf("%q: offset %d, want %d (%v)", tt.expr, te.Offset, tt.offset, err)
+		}
+	}
+}
+
+func TestTypeExpressionRefs(t *testing.T) {
+	refs, err := TypeExpressionRefs("map(string, $common.Address[])")
+	if err != nil || len(refs) != 1 {
+		t.Fatalf("unexpected refs: %+v, %v", refs, err)
+	}
+	if refs[0].Name != "common.Address" || refs[0].Offset != 12 || refs[0].Len != len("$common.Address") {
+		t.Fatalf("unexpected ref: %+v", refs[0])
+	}
+	if refs, _ := TypeExpressionRefs("enum(User, Admin)"); len(refs) != 0 {
+		t.Fatalf("enum values are not refs: %+v", refs)
+	}
+}
+
+func TestTypeExpressionErrorPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	data := "types:\n  User:\n    name: string(1..x)\nassistants:\n  a:\n    model: m\n    input_type: \"map(int, string)\"\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = BuildIR(spec)
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+
+	found := map[string]Position{}
+	for _, e := range merr.Errors {
+		found[e.Field] = e.Pos
+	}
+	if pos := found["types.User.name"]; pos.Line != 3 || pos.Column != 21 {
+		t.Fatalf("types.User.name: unexpected position %v (errors: %v)", pos, merr.Errors)
+	}
+	// Значение в кавычках: столбец считается после кавычки
+	if pos := found["assistants.a.input_type"]; pos.Line != 7 || pos.Column != 22 {
+		t.Fatalf("input_type: unexpected position %v", pos)
+	}
+}
+
+func FuzzParseTypeExpression(f *testing.F) {
+	for _, seed := range []string{
+		"string(1..100)", "int(0..)", "number(-1.5..2)", "enum(a, b)[]", "map(string, $X[])",
+		"$Item[](min:1, max:10)", "$common.Address", "string(email)[][]", "bool", "enum( x ,y)",
+		"oneOf($A, $B, discriminator:kind)",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, expr string) {
+		td, err := ParseTypeExpression(expr)
+		if err != nil {
+			var te *TypeExprError
+			if !errors.As(err, &te) || te.Offset < 0 || te.Offset > len(expr) {
+				t.Fatalf("%q: bad error %v", expr, err)
+			}
+			return
+		}
+		canonical := FormatTypeExpression(td)
+		again, err := ParseTypeExpression(canonical)
+		if err != nil {
+			t.Fatalf("%q: canonical form %q does not parse: %v", expr, canonical, err)
+		}
+		if got := FormatTypeExpression(again); got != canonical {
+			t.Fatalf("%q: round trip %q -> %q", expr, canonical, got)
+		}
+	})
+}
+
+func TestInlineAssistantTypes(t *testing.T) {
+	spec := &Spec{
+		Types: map[string]any{"User": map[string]any{"name": "string"}},
+		Assistants: map[string]AssistantSpec{
+			"data_analyst": {InputType: "string(1..1000)", OutputType: "$User[](max:3)"},
+			"profiler":     {InputType: "$User", OutputType: "string"},
+		},
+	}
+	if err := ResolveSpec(spec); err != nil {
+		t.Fatal(err)
+	}
+	registry := spec.Resolved.TypeRegistry
+
+	analyst := spec.Assistants["data_analyst"]
+	if analyst.InputType != "DataAnalystInput" || analyst.OutputType != "DataAnalystOutput" {
+		t.Fatalf("inline types are not named: %q, %q", analyst.InputType, analyst.OutputType)
+	}
+	if td := registry.Types["DataAnalystInput"]; td == nil || td.Name != "DataAnalystInput" || *td.MaxLength != 1000 {
+		t.Fatalf("unexpected input type: %+v", td)
+	}
+	if td := registry.Types["DataAnalystOutput"]; td == nil || td.Kind != KindArray || td.Items.Ref != "$User" {
+		t.Fatalf("unexpected output type: %+v", td)
+	}
+
+	profiler := spec.Assistants["profiler"]
+	if profiler.InputType != "User" || profiler.Resolved.InputType != registry.Types["User"] {
+		t.Fatalf("$User should resolve to the declared type: %q", profiler.InputType)
+	}
+	if profiler.OutputType != "string" || registry.Types["ProfilerOutput"] != nil {
+		t.Fatalf("string output must stay a plain string")
+	}
+}
+
+func TestInlineAssistantTypeClash(t *testing.T) {
+	spec := &Spec{
+		Types:      map[string]any{"BotInput": "string"},
+		Assistants: map[string]AssistantSpec{"bot": {InputType: "int(0..10)", OutputType: "$Missing"}},
+	}
+	err := ResolveSpec(spec)
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	codes := map[string]Code{}
+	for _, e := range merr.Errors {
+		codes[e.Field] = e.Code
+	}
+	if codes["assistants.bot.input_type"] != CodeTypeNameClash {
+		t.Fatalf("expected name clash, got %v", merr.Errors)
+	}
+	if codes["assistants.bot.output_type"] != CodeUndefinedReference {
+		t.Fatalf("expected undefined reference, got %v", merr.Errors)
+	}
+}
+
+func TestInvalidTypeIsNotReportedAsUndefined(t *testing.T) {
+	spec := &Spec{
+		Types: map[string]any{
+			"Request": map[string]any{"code": "string(1..2..3)"},
+			"Either":  "oneOf($Request, $Other)",
+			"Other":   map[string]any{"kind": "string"},
+		},
+		Assistants: map[string]AssistantSpec{"bot": {InputType: "Request", OutputType: "$Request[]"}},
+	}
+	err := ResolveSpec(spec)
+	var merr *MultiError
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	if len(merr.Errors) != 1 || merr.Errors[0].Field != "types.Request.code" || merr.Errors[0].Code != CodeInvalidType {
+		t.Fatalf("expected only the parse error, got %v", merr.Errors)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnionVariant — разрешённый вариант объединения oneOf.
+type UnionVariant struct {
+	Name string   // имя объектного типа варианта
+	Type *TypeDef // объектный тип варианта
+	Tag  string   // значение дискриминатора; пусто, если дискриминатор не задан
+}
+
+// UnionVariants разрешает варианты объединения через реестр. Вариант должен быть
+// объектом; при заданном дискриминаторе у каждого варианта есть обязательное поле
+// string или enum из одного значения. Тег варианта — это значение enum, а для
+// string — имя типа.
+func (r *TypeRegistry) UnionVariants(td *TypeDef) ([]UnionVariant, error) {
+	if td == nil || td.Kind != KindOneOf {
+		return nil, fmt.Errorf("not a oneOf type")
+	}
+
+	variants := make([]UnionVariant, 0, len(td.Variants))
+	names := make(map[string]bool, len(td.Variants))
+	tags := make(map[string]string, len(td.Variants))
+	for _, ref := range td.Variants {
+		target, err := r.Resolve(ref.Ref)
+		if err != nil {
+			return nil, err
+		}
+		name := target.Name
+		if name == "" {
+			name = refTypeName(ref.Ref)
+		}
+		if target.Kind != KindObject {
+			return nil, fmt.Errorf("oneOf variant %s must be an object type, got %s", name, target.Kind)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("oneOf variant %s is listed twice", name)
+		}
+		names[name] = true
+
+		variant := UnionVariant{Name: name, Type: target}
+		if td.Discriminator != "" {
+			tag, err := discriminatorTag(name, target, td.Discriminator)
+			if err != nil {
+				return nil, err
+			}
+			if other, ok := tags[tag]; ok {
+				return nil, fmt.Errorf("oneOf variants %s and %s share discriminator value %q", other, name, tag)
+			}
+			tags[tag] = name
+			variant.Tag = tag
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+func discriminatorTag(name string, variant *TypeDef, field string) (string, error) {
+	prop, ok := variant.Properties[field]
+	if !ok {
+		return "", fmt.Errorf("oneOf variant %s has no discriminator field %q", name, field)
+	}
+	if prop.Optional {
+		return "", fmt.Errorf("discriminator field %s.%s must be required", name, field)
+	}
+	switch {
+	case prop.Kind == KindEnum && len(prop.Enum) == 1:
+		return prop.Enum[0], nil
+	case prop.Kind == KindString:
+		return name, nil
+	}
+	return "", fmt.Errorf("discriminator field %s.%s must be string or a single-value enum", name, field)
+}
+
+// checkUnion проверяет объединение после того, как его ссылки признаны определёнными.
+func checkUnion(field string, td *TypeDef, registry *TypeRegistry, merr *MultiError) {
+	for _, variant := range td.Variants {
+		ref := strings.TrimPrefix(variant.Ref, "$")
+		if registry.invalid[ref] {
+			// Неразобранный тип уже получил ошибку при разборе types:
+			return
+		}
+		if _, err := registry.Resolve(ref); err != nil {
+			// Ссылка module.Type, которую не удалось разрешить, уже получила ошибку в resolveImports
+			return
+		}
+	}
+	if _, err := registry.UnionVariants(td); err != nil {
+		merr.Append(&ValidationError{Code: CodeInvalidType, Field: field, Msg: err.Error()})
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func unionSpec(resolution string) *Spec {
+	return &Spec{
+		Types: map[string]any{
+			"Refund": map[string]any{
+				"kind":   "enum(refund)",
+				"amount": "number(0..)",
+			},
+			"Escalation": map[string]any{
+				"kind": "string",
+				"team": "enum(billing, tech)",
+			},
+			"Note":       "string",
+			"Resolution": resolution,
+		},
+	}
+}
+
+func TestUnionVariants(t *testing.T) {
+	spec := unionSpec("oneOf($Refund, $Escalation, discriminator:kind)")
+	if err := ResolveSpec(spec); err != nil {
+		t.Fatal(err)
+	}
+	registry := spec.Resolved.TypeRegistry
+	variants, err := registry.UnionVariants(registry.Types["Resolution"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variants) != 2 || variants[0].Name != "Refund" || variants[1].Name != "Escalation" {
+		t.Fatalf("unexpected variants: %+v", variants)
+	}
+	// enum из одного значения задаёт тег, поле string — имя типа
+	if variants[0].Tag != "refund" || variants[1].Tag != "Escalation" {
+		t.Fatalf("unexpected tags: %q, %q", variants[0].Tag, variants[1].Tag)
+	}
+}
+
+func TestUnionValidation(t *testing.T) {
+	tests := []struct {
+		expr string
+		msg  string
+	}{
+		{"oneOf($Refund, $Note)", "must be an object type"},
+		{"oneOf($Refund, $Refund)", "listed twice"},
+		{"oneOf($Escalation, $Refund, discriminator:team)", "must be string or a single-value enum"},
+		{"oneOf($Refund, $Escalation, discriminator:amount)", "must be string or a single-value enum"},
+		{"oneOf($Refund, $Escalation, discriminator:id)", `no discriminator field "id"`},
+	}
+	for _, tt := range tests {
+		err := ResolveSpec(unionSpec(tt.expr))
+		var merr *MultiError
+		if !errors.As(err, &merr) || len(merr.Errors) != 1 {
+			t.Errorf("%s: expected one error, got %v", tt.expr, err)
+			continue
+		}
+		verr := merr.Errors[0]
+		if verr.Code != CodeInvalidType || verr.Field != "types.Resolution" || !strings.Contains(verr.Msg, tt.msg) {
+			t.Errorf("%s: unexpected error %+v", tt.expr, verr)
+		}
+	}
+
+	err := ResolveSpec(unionSpec("oneOf($Refund, $Missing)"))
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 1 || merr.Errors[0].Code != CodeUndefinedReference {
+		t.Fatalf("expected only undefined reference, got %v", err)
+	}
+}
+
+func TestUnionImportedVariants(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"spec.yaml": `
+imports:
+  - as: ev
+    path: events.yaml
+types:
+  Event: oneOf($ev.Created, $ev.Deleted, discriminator:kind)
+`,
+		"events.yaml": `
+types:
+  Created:
+    kind: enum(created)
+  Deleted:
+    kind: enum(created)
+`,
+	})
+	spec, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	err = ResolveSpec(spec)
+	var merr *MultiError
+	if !errors.As(err, &merr) || !strings.Contains(merr.Error(), "share discriminator value") {
+		t.Fatalf("expected discriminator clash for imported variants, got %v", err)
+	}
+
+	// Ссылка module.Type, не переписанная на плоское имя, разрешается через Imports
+	module := &TypeRegistry{Types: map[string]*TypeDef{
+		"Created": {Kind: KindObject, Properties: map[string]*TypeDef{"kind": {Kind: KindEnum, Enum: []string{"created"}}}},
+		"Note":    {Kind: KindString},
+	}}
+	registry := &TypeRegistry{Types: map[string]*TypeDef{}, Imports: map[string]*TypeRegistry{"ev": module}}
+	merr = &MultiError{}
+	union := &TypeDef{Kind: KindOneOf, Variants: []*TypeDef{{Kind: KindRef, Ref: "$ev.Created"}, {Kind: KindRef, Ref: "$ev.Note"}}}
+	checkUnion("types.Event", union, registry, merr)
+	if len(merr.Errors) != 1 || !strings.Contains(merr.Errors[0].Msg, "must be an object type") {
+		t.Fatalf("expected namespaced variants to be checked, got %v", merr.Errors)
+	}
+}
+
+func TestUnionSchema(t *testing.T) {
+	spec := unionSpec("oneOf($Refund, $Escalation, discriminator:kind)")
+	if err := ResolveSpec(spec); err != nil {
+		t.Fatal(err)
+	}
+	registry := spec.Resolved.TypeRegistry
+	data, err := NewSchemaCompiler(registry, DialectOpenAIStrict).CompileJSON(registry.Types["Resolution"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		AnyOf []struct {
+			Type       string `json:"type"`
+			Properties map[string]struct {
+				Enum []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"anyOf"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.AnyOf) != 2 || schema.AnyOf[0].Type != "object" {
+		t.Fatalf("expected anyOf with two objects: %s", data)
+	}
+	if got := schema.AnyOf[1].Properties["kind"].Enum; len(got) != 1 || got[0] != "Escalation" {
+		t.Fatalf("discriminator is not pinned in the branch: %s", data)
+	}
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+)
+
+// checkTypeValues проверяет default и examples во всех полях типа; field — путь типа в спецификации.
+func checkTypeValues(field string, td *TypeDef, registry *TypeRegistry, merr *MultiError) {
+	if td == nil {
+		return
+	}
+	if td.Default != nil {
+		if err := checkValue(td, td.Default, registry); err != nil {
+			merr.Append(&ValidationError{Code: CodeInvalidValue, Field: field + ".default", Msg: "default " + err.Error()})
+		}
+	}
+	for i, example := range td.Examples {
+		if err := checkValue(td, example, registry); err != nil {
+			merr.Append(&ValidationError{Code: CodeInvalidValue, Field: fmt.Sprintf("%s.examples[%d]", field, i), Msg: "example " + err.Error()})
+		}
+	}
+
+	switch td.Kind {
+	case KindObject:
+		for name, prop := range td.Properties {
+			checkTypeValues(joinField(field, name), prop, registry, merr)
+		}
+	case KindArray:
+		checkTypeValues(field+"[0]", td.Items, registry, merr)
+	}
+}
+
+// checkValue проверяет, что значение из YAML подходит под тип. Неразрешённые ссылки
+// пропускаются: о них сообщает checkTypeRefs.
+func checkValue(td *TypeDef, v any, registry *TypeRegistry) error {
+	switch td.Kind {
+	case KindString, KindDatetime, KindDate, KindUUID:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("must be a string, got %s", valueKind(v))
+		}
+		n := utf8.RuneCountInString(s)
+		if td.MinLength != nil && n < *td.MinLength {
+			return fmt.Errorf("%q is shorter than %d characters", s, *td.MinLength)
+		}
+		if td.MaxLength != nil && n > *td.MaxLength {
+			return fmt.Errorf("%q is longer than %d characters", s, *td.MaxLength)
+		}
+		if td.Pattern != "" {
+			if re, err := regexp.Compile(td.Pattern); err == nil && !re.MatchString(s) {
+				return fmt.Errorf("%q does not match /%s/", s, td.Pattern)
+			}
+		}
+		// uuid, date и datetime проверяются как одноимённые форматы
+		format := td.Format
+		if td.Kind != KindString {
+			format = string(td.Kind)
+		}
+		if IsBuiltinFormat(format) {
+			if !ValidFormat(format, s) {
+				return fmt.Errorf("%q is not a valid %s", s, format)
+			}
+		} else if pattern := registry.FormatPattern(format); pattern != "" {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(s) {
+				return fmt.Errorf("%q is not a valid %s", s, format)
+			}
+		}
+
+	case KindInt, KindNumber:
+		n, ok := numberValue(v)
+		if !ok {
+			return fmt.Errorf("must be a number, got %s", valueKind(v))
+		}
+		if td.Kind == KindInt && n != math.Trunc(n) {
+			return fmt.Errorf("must be an integer, got %s", formatNumber(n))
+		}
+		if (td.Min != nil && n < *td.Min) || (td.Max != nil && n > *td.Max) {
+			return fmt.Errorf("%s is out of range %s", formatNumber(n), formatRange(td.Min, td.Max))
+		}
+
+	case KindBool:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("must be a boolean, got %s", valueKind(v))
+		}
+
+	case KindEnum:
+		s, ok := v.(string)
+		if !ok || !slices.Contains(td.Enum, s) {
+			return fmt.Errorf("must be one of %v, got %v", td.Enum, v)
+		}
+
+	case KindArray:
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("must be a list, got %s", valueKind(v))
+		}
+		if td.MinItems != nil && len(items) < *td.MinItems {
+			return fmt.Errorf("must have at least %d items", *td.MinItems)
+		}
+		if td.MaxItems != nil && len(items) > *td.MaxItems {
+			return fmt.Errorf("must have at most %d items", *td.MaxItems)
+		}
+		for i, item := range items {
+			if err := checkValue(td.Items, item, registry); err != nil {
+				return fmt.Errorf("item %d %w", i, err)
+			}
+		}
+
+	case KindMap:
+		values, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("must be an object, got %s", valueKind(v))
+		}
+		for key, value := range values {
+			if err := checkValue(td.ValueType, value, registry); err != nil {
+				return fmt.Errorf("key %q %w", key, err)
+			}
+		}
+
+	case KindObject:
+		values, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("must be an object, got %s", valueKind(v))
+		}
+		for _, name := range sortedPropertyNames(td) {
+			prop := td.Properties[name]
+			value, present := values[name]
+			if !present {
+				if !prop.Optional && prop.Default == nil {
+					return fmt.Errorf("is missing field %q", name)
+				}
+				continue
+			}
+			if err := checkValue(prop, value, registry); err != nil {
+				return fmt.Errorf("field %q %w", name, err)
+			}
+		}
+		for key := range values {
+			if _, ok := td.Properties[key]; !ok {
+				return fmt.Errorf("has unknown field %q", key)
+			}
+		}
+
+	case KindRef:
+		if registry == nil {
+			return nil
+		}
+		target, err := registry.Resolve(td.Ref)
+		if err != nil {
+			return nil
+		}
+		return checkValue(target, v, registry)
+
+	case KindOneOf:
+		if registry == nil {
+			return nil
+		}
+		// Варианты — объекты, поэтому проверка не зацикливается на рекурсивных объединениях
+		variants, err := registry.UnionVariants(td)
+		if err != nil {
+			return nil
+		}
+		for _, variant := range variants {
+			if checkValue(variant.Type, v, registry) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("matches none of %s", FormatTypeExpression(td))
+	}
+	return nil
+}
+
+func numberValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// valueKind называет вид значения в терминах JSON.
+func valueKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64, float64:
+		return "number"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldSpec(t *testing.T) {
+	reg, err := NewTypeParser().ParseTypes(map[string]any{
+		"Priority": map[string]any{
+			"$type":       "enum(low, high)",
+			"description": "Ticket priority",
+			"default":     "low",
+		},
+		"Ticket": map[string]any{
+			"title": map[string]any{
+				"$type":       "string(1..10)",
+				"description": "Short title",
+				"examples":    []any{"Login"},
+			},
+			"retries": map[string]any{"$type": "int(0..5)", "default": 3},
+			// Без $type отображение остаётся объектом, даже если в нём есть поле type
+			"meta": map[string]any{"type": "string", "owner": "string"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priority := reg.Types["Priority"]
+	if priority.Kind != KindEnum || priority.Description != "Ticket priority" || priority.Default != "low" {
+		t.Fatalf("unexpected Priority: %+v", priority)
+	}
+	ticket := reg.Types["Ticket"]
+	title := ticket.Properties["title"]
+	if title.Kind != KindString || title.Description != "Short title" || !reflect.DeepEqual(title.Examples, []any{"Login"}) {
+		t.Fatalf("unexpected title: %+v", title)
+	}
+	if retries := ticket.Properties["retries"]; retries.Kind != KindInt || retries.Default != 3 {
+		t.Fatalf("unexpected retries: %+v", retries)
+	}
+	if meta := ticket.Properties["meta"]; meta.Kind != KindObject || len(meta.Properties) != 2 {
+		t.Fatalf("meta must stay an object: %+v", meta)
+	}
+}
+
+func TestFieldNamedTypeStaysObject(t *testing.T) {
+	reg, err := NewTypeParser().ParseTypes(map[string]any{
+		"Event": map[string]any{"type": "string", "description": "string"},
+		"Note":  map[string]any{"$type": "string", "description": "Free text", "owner": "string"},
+	})
+	merr, ok := err.(*MultiError)
+	if !ok || len(merr.Errors) != 1 || merr.Errors[0].Field != "types.Note.owner" {
+		t.Fatalf("expected unknown key error for Note only, got %v", err)
+	}
+	event := reg.Types["Event"]
+	if event.Kind != KindObject || len(event.Properties) != 2 || event.Properties["description"].Kind != KindString {
+		t.Fatalf("Event must be an object with type and description fields: %+v", event)
+	}
+}
+
+func TestCheckTypeValues(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"spec.yaml": `version: 0.3
+types:
+  Ticket:
+    retries:
+      $type: int(0..5)
+      default: 9
+    title:
+      $type: string(1..5)
+      examples: ["too long", "ok"]
+    tags:
+      $type: string[]
+      default: [a, 1]
+    owner:
+      $type: $Owner
+      default: {name: bob, age: 3}
+  Owner:
+    name: string
+    team?: string
+`,
+	})
+
+	spec, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = BuildIR(spec)
+	merr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+	want := map[string]string{
+		"types.Ticket.retries.default":   "default 9 is out of range 0..5",
+		"types.Ticket.title.examples[0]": `example "too long" is longer than 5 characters`,
+		"types.Ticket.tags.default":      "default item 1 must be a string, got number",
+		"types.Ticket.owner.default":     `default has unknown field "age"`,
+	}
+	if len(merr.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), merr.Errors)
+	}
+	for _, e := range merr.Errors {
+		if e.Code != CodeInvalidValue || want[e.Field] != e.Msg || !e.Pos.IsValid() {
+			t.Fatalf("unexpected error %+v", e)
+		}
+	}
+}
+
+func TestSchemaDefaultsAndExamples(t *testing.T) {
+	reg := &TypeRegistry{Types: map[string]*TypeDef{
+		"Ticket": {
+			Name: "Ticket",
+			Kind: KindObject,
+			Properties: map[string]*TypeDef{
+				"retries": {Kind: KindInt, Default: 3, Examples: []any{1, 2}},
+			},
+		},
+	}}
+
+	schema, err := NewSchemaCompiler(reg, DialectDraft2020).Compile(reg.Types["Ticket"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	retries := schema["properties"].(map[string]any)["retries"].(map[string]any)
+	if retries["default"] != 3 || !reflect.DeepEqual(retries["examples"], []any{1, 2}) {
+		t.Fatalf("default and examples are lost: %v", retries)
+	}
+
+	data, err := NewSchemaCompiler(reg, DialectOpenAIStrict).CompileJSON(reg.Types["Ticket"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var strict map[string]any
+	if err := json.Unmarshal(data, &strict); err != nil {
+		t.Fatal(err)
+	}
+	retries = strict["properties"].(map[string]any)["retries"].(map[string]any)
+	if _, ok := retries["default"]; ok {
+		t.Errorf("strict schema must not contain default: %v", retries)
+	}
+	if _, ok := retries["examples"]; ok {
+		t.Errorf("strict schema must not contain examples: %v", retries)
+	}
+}
@@ -33,7 +33,7 @@ func NewYamlGeneratorAgent(client aiwf.ModelClient) *YamlGeneratorAgent {
 				InputTypeName:  "GenerationInput",
 				OutputTypeName: "GeneratedConfig",
 				MaxTokens:      4500,
-				Temperature:    0.2,
+				Temperature:    aiwf.Float64(0.2),
 			},
 			Client: client,
 		},
@@ -108,7 +108,7 @@ func NewTaskAnalyzerAgent(client aiwf.ModelClient) *TaskAnalyzerAgent {
 				InputTypeName:  "string",
 				OutputTypeName: "TaskAnalysis",
 				MaxTokens:      2500,
-				Temperature:    0.4,
+				Temperature:    aiwf.Float64(0.4),
 			},
 			Client: client,
 		},
@@ -0,0 +1,14 @@
+// Package all регистрирует все встроенные провайдеры в реестре aiwf.
+//
+//	import _ "github.com/andranikuz/aiwf/providers/all"
+package all
+
+import (
+	_ "github.com/andranikuz/aiwf/providers/anthropic"
+	_ "github.com/andranikuz/aiwf/providers/azure"
+	_ "github.com/andranikuz/aiwf/providers/gemini"
+	_ "github.com/andranikuz/aiwf/providers/grok"
+	_ "github.com/andranikuz/aiwf/providers/local"
+	_ "github.com/andranikuz/aiwf/providers/ollama"
+	_ "github.com/andranikuz/aiwf/providers/openai"
+)
@@ -0,0 +1,40 @@
+package all
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+// Описания встроенных провайдеров в core должны совпадать с их регистрацией.
+func TestBuiltinProvidersMatchRegistry(t *testing.T) {
+	builtin := core.BuiltinProviders()
+	if len(builtin) != len(aiwf.Providers()) {
+		t.Fatalf("core lists %d builtin providers, registry has %d", len(builtin), len(aiwf.Providers()))
+	}
+
+	for _, want := range builtin {
+		got, ok := aiwf.LookupProvider(want.Name)
+		if !ok {
+			t.Errorf("%s: not registered", want.Name)
+			continue
+		}
+		if got.ImportPath != want.ImportPath || !reflect.DeepEqual(got.EnvKeys, want.EnvKeys) || !reflect.DeepEqual(got.Capabilities, want.Capabilities) {
+			t.Errorf("%s: registry %+v, core %+v", want.Name, got, want)
+		}
+		for _, mode := range []string{"", "responses", "chat_completions"} {
+			if g, w := ignoredOptions(got, mode), ignoredOptions(want, mode); !reflect.DeepEqual(g, w) {
+				t.Errorf("%s: ignored options in mode %q: registry %v, core %v", want.Name, mode, g, w)
+			}
+		}
+	}
+}
+
+func ignoredOptions(info aiwf.ProviderInfo, mode string) []string {
+	if info.IgnoredOptions == nil {
+		return nil
+	}
+	return info.IgnoredOptions(mode)
+}
@@ -10,12 +10,16 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/andranikuz/aiwf/generator/core"
 	"github.com/andranikuz/aiwf/runtime/go/aiwf"
 )
 
 const (
 	defaultBaseURL   = "https://api.anthropic.com/v1"
 	anthropicVersion = "2023-06-01"
+
+	// outputToolName — имя единственного инструмента, через который модель возвращает структурированный ответ.
+	outputToolName = "emit_output"
 )
 
 // ClientConfig определяет параметры доступа к Anthropic API.
@@ -73,45 +77,99 @@ func (c *Client) WithThreadManager(tm aiwf.ThreadManager) *Client {
 
 // CallJSONSchema выполняет запрос к Anthropic API.
 func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
-	req, err := c.newMessageRequest(ctx, call)
+	result, err := c.Call(ctx, call)
+	if err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
+	return result.Data, result.Usage, nil
+}
+
+// Call выполняет запрос к Anthropic API и сообщает причину остановки генерации.
+// Для структурированных выходов модель принудительно вызывает инструмент,
+// и результатом становится input блока tool_use.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	tool, wrapped, err := buildOutputTool(call)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: failed to build output schema: %w", err)
+	}
+
+	req, err := c.newMessageRequest(ctx, call, tool)
 	if err != nil {
-		return nil, aiwf.Tokens{}, fmt.Errorf("anthropic: failed to create request: %w", err)
+		return nil, fmt.Errorf("anthropic: failed to create request: %w", err)
 	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, aiwf.Tokens{}, fmt.Errorf("anthropic: request failed: %w", err)
+		return nil, fmt.Errorf("anthropic: request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
 		buf, _ := io.ReadAll(resp.Body)
-		return nil, aiwf.Tokens{}, fmt.Errorf("anthropic: unexpected status %d: %s", resp.StatusCode, string(buf))
+		return nil, fmt.Errorf("anthropic: unexpected status %d: %s", resp.StatusCode, string(buf))
 	}
 
 	var parsed Message
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, aiwf.Tokens{}, fmt.Errorf("anthropic: failed to decode response: %w", err)
+		return nil, fmt.Errorf("anthropic: failed to decode response: %w", err)
 	}
 
-	if len(parsed.Content) == 0 {
-		return nil, aiwf.Tokens{}, errors.New("anthropic: empty response content")
+	stopReason := mapStopReason(parsed.StopReason)
+	if len(parsed.Content) == 0 && stopReason != aiwf.StopReasonMaxTokens {
+		return nil, errors.New("anthropic: empty response content")
 	}
 
-	content := ""
-	for _, block := range parsed.Content {
-		if block.Type == "text" {
-			content += block.Text
+	var data []byte
+	if tool != nil {
+		data, err = toolOutput(parsed.Content, wrapped)
+		if err != nil && stopReason != aiwf.StopReasonMaxTokens {
+			return nil, err
+		}
+		// Принудительный вызов инструмента — штатное завершение генерации
+		if stopReason == aiwf.StopReasonToolUse {
+			stopReason = aiwf.StopReasonEnd
 		}
+	} else {
+		content := ""
+		for _, block := range parsed.Content {
+			if block.Type == "text" {
+				content += block.Text
+			}
+		}
+		data = []byte(content)
 	}
 
-	usage := aiwf.Tokens{
-		Prompt:     parsed.Usage.InputTokens,
-		Completion: parsed.Usage.OutputTokens,
-		Total:      parsed.Usage.InputTokens + parsed.Usage.OutputTokens,
+	requestID := resp.Header.Get("request-id")
+	if requestID == "" {
+		requestID = parsed.ID
 	}
 
-	return []byte(content), usage, nil
+	return &aiwf.CallResult{
+		Data:          data,
+		Usage:         parsed.Usage.tokens(),
+		StopReason:    stopReason,
+		RawStopReason: parsed.StopReason,
+		RequestID:     requestID,
+		Model:         parsed.Model,
+	}, nil
+}
+
+// mapStopReason переводит stop_reason Messages API в aiwf.StopReason.
+func mapStopReason(reason string) aiwf.StopReason {
+	switch reason {
+	case "end_turn":
+		return aiwf.StopReasonEnd
+	case "max_tokens":
+		return aiwf.StopReasonMaxTokens
+	case "stop_sequence":
+		return aiwf.StopReasonStopSequence
+	case "tool_use":
+		return aiwf.StopReasonToolUse
+	case "refusal":
+		return aiwf.StopReasonContentFilter
+	default:
+		return aiwf.StopReasonUnknown
+	}
 }
 
 // CallJSONSchemaStream не реализовано для Anthropic
@@ -119,16 +177,68 @@ func (c *Client) CallJSONSchemaStream(ctx context.Context, call aiwf.ModelCall)
 	return nil, aiwf.Tokens{}, errors.New("anthropic: streaming not implemented")
 }
 
-// newMessageRequest создаёт HTTP запрос для Messages API.
-func (c *Client) newMessageRequest(ctx context.Context, call aiwf.ModelCall) (*http.Request, error) {
-	// Используем Payload как входные данные (уже типизированные)
-	userContent := call.UserPrompt
-	if call.Payload != nil {
-		inputJSON, err := json.Marshal(call.Payload)
+// isTextOutput сообщает, ожидается ли от модели обычный текст.
+func isTextOutput(call aiwf.ModelCall) bool {
+	return call.OutputTypeName == "" || call.OutputTypeName == "string"
+}
+
+// buildOutputTool описывает выходной тип как input_schema инструмента.
+// Если корень схемы не объект, схема оборачивается в объект с полем value.
+func buildOutputTool(call aiwf.ModelCall) (*Tool, bool, error) {
+	if isTextOutput(call) {
+		return nil, false, nil
+	}
+
+	schema := map[string]any{"type": "object"}
+	if call.TypeMetadata != nil {
+		raw, err := core.SchemaFromMetadata(call.TypeMetadata, core.DialectAnthropicTool)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+		schema = nil
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, false, err
+		}
+	}
+
+	schema, wrapped := core.WrapValueSchema(schema)
+
+	return &Tool{
+		Name:        outputToolName,
+		Description: fmt.Sprintf("Return the result as %s.", call.OutputTypeName),
+		InputSchema: schema,
+	}, wrapped, nil
+}
+
+// toolOutput извлекает input вызова выходного инструмента.
+func toolOutput(blocks []ContentBlock, wrapped bool) ([]byte, error) {
+	for _, block := range blocks {
+		if block.Type != "tool_use" || block.Name != outputToolName {
+			continue
+		}
+		if !wrapped {
+			return block.Input, nil
+		}
+		value, err := core.UnwrapValue(block.Input)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: %w", err)
 		}
-		userContent = string(inputJSON)
+		return value, nil
+	}
+	return nil, errors.New("anthropic: response has no tool_use block")
+}
+
+// newMessageRequest создаёт HTTP запрос для Messages API.
+func (c *Client) newMessageRequest(ctx context.Context, call aiwf.ModelCall, tool *Tool) (*http.Request, error) {
+	var messages []MessageParam
+	for _, msg := range call.History {
+		messages = append(messages, MessageParam{Role: msg.Role, Content: msg.Content})
+	}
+
+	// Промпт с найденными знаниями и типизированные входные данные идут одним сообщением
+	userContent, err := aiwf.UserContent(call)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: %w", err)
 	}
 
 	maxTokens := call.MaxTokens
@@ -136,22 +246,25 @@ func (c *Client) newMessageRequest(ctx context.Context, call aiwf.ModelCall) (*h
 		maxTokens = 2000
 	}
 
-	temperature := call.Temperature
-	if temperature == 0 {
-		temperature = 0.7
-	}
-
 	payload := MessageRequest{
 		Model: call.Model,
-		Messages: []MessageParam{
-			{
-				Role:    "user",
-				Content: userContent,
-			},
-		},
-		System:      call.SystemPrompt,
-		MaxTokens:   maxTokens,
-		Temperature: temperature,
+		Messages: append(messages, MessageParam{
+			Role:    "user",
+			Content: userContent,
+		}),
+		System:        systemBlocks(call.SystemPrompt, call.PromptCache),
+		MaxTokens:     maxTokens,
+		Temperature:   call.Temperature,
+		TopP:          call.TopP,
+		StopSequences: call.Stop,
+	}
+	if tool != nil {
+		cached := *tool
+		if call.PromptCache {
+			cached.CacheControl = ephemeralCache()
+		}
+		payload.Tools = []Tool{cached}
+		payload.ToolChoice = &ToolChoice{Type: "tool", Name: tool.Name}
 	}
 
 	body, err := json.Marshal(payload)
@@ -172,15 +285,62 @@ func (c *Client) newMessageRequest(ctx context.Context, call aiwf.ModelCall) (*h
 	return req, nil
 }
 
+// systemBlocks превращает системный промпт в блоки запроса. При включённом
+// кэшировании блок помечается точкой кэша: префикс из инструментов и системного
+// промпта переиспользуется между вызовами.
+func systemBlocks(prompt string, cache bool) []SystemBlock {
+	if prompt == "" {
+		return nil
+	}
+	block := SystemBlock{Type: "text", Text: prompt}
+	if cache {
+		block.CacheControl = ephemeralCache()
+	}
+	return []SystemBlock{block}
+}
+
+func ephemeralCache() *CacheControl {
+	return &CacheControl{Type: "ephemeral"}
+}
+
 // MessageRequest - структура запроса к Anthropic Messages API
 type MessageRequest struct {
-	Model       string         `json:"model"`
-	Messages    []MessageParam `json:"messages"`
-	System      string         `json:"system,omitempty"`
-	MaxTokens   int            `json:"max_tokens"`
-	Temperature float64        `json:"temperature,omitempty"`
-	TopK        int            `json:"top_k,omitempty"`
-	TopP        float64        `json:"top_p,omitempty"`
+	Model         string         `json:"model"`
+	Messages      []MessageParam `json:"messages"`
+	System        []SystemBlock  `json:"system,omitempty"`
+	MaxTokens     int            `json:"max_tokens"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	TopK          int            `json:"top_k,omitempty"`
+	TopP          *float64       `json:"top_p,omitempty"`
+	StopSequences []string       `json:"stop_sequences,omitempty"`
+	Tools         []Tool         `json:"tools,omitempty"`
+	ToolChoice    *ToolChoice    `json:"tool_choice,omitempty"`
+}
+
+// Tool - описание инструмента с JSON Schema входа
+type Tool struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	InputSchema  map[string]any `json:"input_schema"`
+	CacheControl *CacheControl  `json:"cache_control,omitempty"`
+}
+
+// SystemBlock - текстовый блок системного промпта
+type SystemBlock struct {
+	Type         string        `json:"type"`
+	Text         string        `json:"text"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+// CacheControl - точка кэширования префикса запроса
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// ToolChoice - выбор инструмента моделью
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 // MessageParam - параметр сообщения в запросе
@@ -203,12 +363,30 @@ type Message struct {
 
 // ContentBlock - блок контента в ответе
 type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
 }
 
 // Usage - информация об использованных токенах
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+}
+
+// tokens переводит usage в aiwf.Tokens. input_tokens не включает кэшированные
+// токены, поэтому Prompt складывается из всех трёх входных счётчиков.
+func (u Usage) tokens() aiwf.Tokens {
+	prompt := u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+	return aiwf.Tokens{
+		Prompt:       prompt,
+		Completion:   u.OutputTokens,
+		Total:        prompt + u.OutputTokens,
+		CachedPrompt: u.CacheReadInputTokens,
+		CacheWrite:   u.CacheCreationInputTokens,
+	}
 }
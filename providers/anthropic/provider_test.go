@@ -0,0 +1,215 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "key"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client
+}
+
+func TestCallForcesOutputTool(t *testing.T) {
+	var payload map[string]any
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": []any{
+				map[string]any{"type": "tool_use", "id": "toolu_1", "name": outputToolName, "input": map[string]any{"answer": "42"}},
+			},
+			"stop_reason": "tool_use",
+			"usage":       map[string]any{"input_tokens": 10, "output_tokens": 5},
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:          "claude-sonnet-4-5",
+		Payload:        map[string]string{"question": "?"},
+		OutputTypeName: "Answer",
+		TypeMetadata: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"answer": map[string]any{"type": "string"}},
+			"required":   []any{"answer"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	if string(result.Data) != `{"answer":"42"}` {
+		t.Fatalf("unexpected data: %s", result.Data)
+	}
+	if result.StopReason != aiwf.StopReasonEnd {
+		t.Fatalf("expected end stop reason, got %s", result.StopReason)
+	}
+
+	choice, ok := payload["tool_choice"].(map[string]any)
+	if !ok || choice["type"] != "tool" || choice["name"] != outputToolName {
+		t.Fatalf("expected forced tool_choice, got %v", payload["tool_choice"])
+	}
+	tools := payload["tools"].([]any)
+	schema := tools[0].(map[string]any)["input_schema"].(map[string]any)
+	if schema["additionalProperties"] != false {
+		t.Fatalf("expected closed input_schema, got %v", schema)
+	}
+}
+
+func TestCallSendsPromptWithPayload(t *testing.T) {
+	var request MessageRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content":     []any{map[string]any{"type": "text", "text": "ok"}},
+			"stop_reason": "end_turn",
+		})
+	})
+
+	_, err := client.Call(context.Background(), aiwf.ModelCall{
+		UserPrompt:     "Справка: отпуск 28 дней",
+		Payload:        map[string]string{"question": "?"},
+		OutputTypeName: "string",
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	content := request.Messages[len(request.Messages)-1].Content
+	if !strings.Contains(content, "отпуск 28 дней") || !strings.Contains(content, `{"question":"?"}`) {
+		t.Fatalf("expected prompt and payload in user message, got %q", content)
+	}
+}
+
+func TestCallWrapsNonObjectOutput(t *testing.T) {
+	var payload map[string]any
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": []any{
+				map[string]any{"type": "tool_use", "name": outputToolName, "input": map[string]any{"value": []any{"a", "b"}}},
+			},
+			"stop_reason": "tool_use",
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{
+		OutputTypeName: "Tags",
+		TypeMetadata:   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if string(result.Data) != `["a","b"]` {
+		t.Fatalf("expected unwrapped value, got %s", result.Data)
+	}
+
+	schema := payload["tools"].([]any)[0].(map[string]any)["input_schema"].(map[string]any)
+	if _, ok := schema["properties"].(map[string]any)["value"]; !ok {
+		t.Fatalf("expected wrapped schema, got %v", schema)
+	}
+}
+
+func TestCallStringOutputIsPlainText(t *testing.T) {
+	var payload map[string]any
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content":     []any{map[string]any{"type": "text", "text": "hello"}},
+			"stop_reason": "end_turn",
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{UserPrompt: "hi", OutputTypeName: "string"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if string(result.Data) != "hello" {
+		t.Fatalf("unexpected data: %s", result.Data)
+	}
+	if _, ok := payload["tools"]; ok {
+		t.Fatalf("string output must not use tools")
+	}
+	if _, ok := payload["temperature"]; ok {
+		t.Fatalf("unset temperature must be omitted, got %v", payload["temperature"])
+	}
+}
+
+func TestCallReportsCacheUsage(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_abc")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":          "msg_1",
+			"model":       "claude-sonnet-4-5-20250929",
+			"content":     []any{map[string]any{"type": "text", "text": "hi"}},
+			"stop_reason": "end_turn",
+			"usage": map[string]any{
+				"input_tokens":                10,
+				"output_tokens":               5,
+				"cache_read_input_tokens":     200,
+				"cache_creation_input_tokens": 50,
+			},
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{Model: "claude-sonnet-4-5", UserPrompt: "ping"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	want := aiwf.Tokens{Prompt: 260, Completion: 5, Total: 265, CachedPrompt: 200, CacheWrite: 50}
+	if result.Usage != want {
+		t.Fatalf("unexpected usage: %+v", result.Usage)
+	}
+	if result.RequestID != "req_abc" || result.Model != "claude-sonnet-4-5-20250929" {
+		t.Fatalf("unexpected request id/model: %q %q", result.RequestID, result.Model)
+	}
+}
+
+func TestCallMarksCacheBreakpoints(t *testing.T) {
+	var body MessageRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": []any{map[string]any{
+				"type": "tool_use", "name": outputToolName, "input": map[string]any{"answer": "42"},
+			}},
+			"stop_reason": "tool_use",
+		})
+	})
+
+	_, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:          "claude-sonnet-4-5",
+		SystemPrompt:   "You are a helpful assistant.",
+		UserPrompt:     "?",
+		OutputTypeName: "Answer",
+		TypeMetadata:   map[string]any{"type": "object", "properties": map[string]any{"answer": map[string]any{"type": "string"}}},
+		PromptCache:    true,
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	if len(body.System) != 1 || body.System[0].Text != "You are a helpful assistant." {
+		t.Fatalf("unexpected system blocks: %+v", body.System)
+	}
+	if cc := body.System[0].CacheControl; cc == nil || cc.Type != "ephemeral" {
+		t.Fatalf("expected ephemeral cache_control on system block, got %+v", cc)
+	}
+	if len(body.Tools) != 1 || body.Tools[0].CacheControl == nil {
+		t.Fatalf("expected cache_control on output tool, got %+v", body.Tools)
+	}
+}
@@ -0,0 +1,26 @@
+package anthropic
+
+import "github.com/andranikuz/aiwf/runtime/go/aiwf"
+
+// Регистрирует провайдера под именем use: anthropic.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "anthropic",
+		ImportPath:   "github.com/andranikuz/aiwf/providers/anthropic",
+		EnvKeys:      []string{"ANTHROPIC_API_KEY"},
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityTools, aiwf.CapabilityPromptCache},
+		New:          newFromConfig,
+		IgnoredOptions: func(string) []string {
+			return []string{"seed", "presence_penalty", "frequency_penalty", "reasoning_effort"}
+		},
+	})
+}
+
+// newFromConfig создаёт клиента Anthropic из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	apiKey := cfg.APIKey("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, aiwf.ErrProviderNotConfigured
+	}
+	return NewClient(ClientConfig{BaseURL: cfg.BaseURL, APIKey: apiKey, HTTPClient: cfg.HTTPClient(), Timeout: cfg.Timeout})
+}
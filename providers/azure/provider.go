@@ -0,0 +1,131 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/aiwf/providers/openai"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+const (
+	// DefaultAPIVersion — GA-версия API для Chat Completions по деплойментам.
+	DefaultAPIVersion = "2024-10-21"
+	// DefaultResponsesAPIVersion — версия API для Responses API.
+	DefaultResponsesAPIVersion = "2025-04-01-preview"
+)
+
+// ClientConfig определяет параметры доступа к Azure OpenAI.
+type ClientConfig struct {
+	// Endpoint ресурса, например https://my-resource.openai.azure.com
+	Endpoint   string
+	APIKey     string
+	APIVersion string
+	HTTPClient *http.Client
+	Timeout    time.Duration
+
+	// Deployments сопоставляет значение model: имени деплоймента.
+	// Если модели нет в списке, её имя используется как имя деплоймента.
+	Deployments map[string]string
+	// Mode выбирает API; по умолчанию openai.ModeChatCompletions.
+	Mode openai.Mode
+}
+
+// Client реализует aiwf.ModelClient для Azure OpenAI поверх openai.Client.
+type Client struct {
+	upstream    *openai.Client
+	deployments map[string]string
+}
+
+// NewClient создаёт клиента для Azure OpenAI.
+func NewClient(cfg ClientConfig) (*Client, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("azure: api key is required")
+	}
+	if cfg.Endpoint == "" {
+		return nil, errors.New("azure: endpoint is required")
+	}
+
+	mode := cfg.Mode
+	if mode == "" {
+		mode = openai.ModeChatCompletions
+	}
+
+	version := cfg.APIVersion
+	if version == "" {
+		version = DefaultAPIVersion
+		if mode == openai.ModeResponses {
+			version = DefaultResponsesAPIVersion
+		}
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	upstream, err := openai.NewClient(openai.ClientConfig{
+		BaseURL:    endpoint + "/openai",
+		APIKey:     cfg.APIKey,
+		HTTPClient: cfg.HTTPClient,
+		Timeout:    cfg.Timeout,
+		Mode:       mode,
+		AuthHeader: "api-key",
+		URLBuilder: func(deployment, path string) string {
+			return buildURL(endpoint, version, mode, deployment, path)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{upstream: upstream, deployments: cfg.Deployments}, nil
+}
+
+// buildURL строит адрес запроса: Chat Completions адресуется через деплоймент
+// в пути, Responses API принимает деплоймент в поле model.
+func buildURL(endpoint, version string, mode openai.Mode, deployment, path string) string {
+	query := "?api-version=" + url.QueryEscape(version)
+	if mode == openai.ModeChatCompletions && deployment != "" {
+		return endpoint + "/openai/deployments/" + url.PathEscape(deployment) + path + query
+	}
+	return endpoint + "/openai" + path + query
+}
+
+// Deployment возвращает имя деплоймента для модели.
+func (c *Client) Deployment(model string) string {
+	if deployment, ok := c.deployments[model]; ok {
+		return deployment
+	}
+	return model
+}
+
+// WithThreadManager устанавливает менеджер тредов для диалогов
+func (c *Client) WithThreadManager(tm aiwf.ThreadManager) *Client {
+	c.upstream.WithThreadManager(tm)
+	return c
+}
+
+// CallJSONSchema выполняет запрос к деплойменту Azure OpenAI.
+func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
+	call.Model = c.Deployment(call.Model)
+	return c.upstream.CallJSONSchema(ctx, call)
+}
+
+// Call выполняет запрос и сообщает причину остановки генерации.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	call.Model = c.Deployment(call.Model)
+	return c.upstream.Call(ctx, call)
+}
+
+// CallJSONSchemaStream делегирует потоковый вызов.
+func (c *Client) CallJSONSchemaStream(ctx context.Context, call aiwf.ModelCall) (<-chan aiwf.StreamChunk, aiwf.Tokens, error) {
+	call.Model = c.Deployment(call.Model)
+	return c.upstream.CallJSONSchemaStream(ctx, call)
+}
+
+// Embed запрашивает эмбеддинги у деплоймента Azure OpenAI.
+func (c *Client) Embed(ctx context.Context, call aiwf.EmbeddingCall) (*aiwf.EmbeddingResult, error) {
+	call.Model = c.Deployment(call.Model)
+	return c.upstream.Embed(ctx, call)
+}
@@ -0,0 +1,114 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andranikuz/aiwf/providers/openai"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func TestChatCompletionsDeployment(t *testing.T) {
+	var recorded struct {
+		path    string
+		version string
+		key     string
+		auth    string
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded.path = r.URL.Path
+		recorded.version = r.URL.Query().Get("api-version")
+		recorded.key = r.Header.Get("api-key")
+		recorded.auth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": `{"answer":"42"}`},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]any{"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{
+		Endpoint:    srv.URL,
+		APIKey:      "azure-key",
+		Deployments: map[string]string{"gpt-4o": "prod-gpt4o"},
+	})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	raw, usage, err := client.CallJSONSchema(context.Background(), aiwf.ModelCall{
+		Model:          "gpt-4o",
+		UserPrompt:     "?",
+		OutputTypeName: "Answer",
+		TypeMetadata:   map[string]any{"type": "object", "properties": map[string]any{"answer": map[string]any{"type": "string"}}},
+	})
+	if err != nil {
+		t.Fatalf("CallJSONSchema: %v", err)
+	}
+
+	if recorded.path != "/openai/deployments/prod-gpt4o/chat/completions" {
+		t.Fatalf("unexpected path: %s", recorded.path)
+	}
+	if recorded.version != DefaultAPIVersion {
+		t.Fatalf("unexpected api-version: %s", recorded.version)
+	}
+	if recorded.key != "azure-key" || recorded.auth != "" {
+		t.Fatalf("expected api-key header only, got api-key=%q authorization=%q", recorded.key, recorded.auth)
+	}
+	if string(raw) != `{"answer":"42"}` || usage.Total != 5 {
+		t.Fatalf("unexpected result: %s %+v", raw, usage)
+	}
+}
+
+func TestResponsesMode(t *testing.T) {
+	var path, version, model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		version = r.URL.Query().Get("api-version")
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		model, _ = body["model"].(string)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "completed",
+			"output": []any{map[string]any{"content": []any{map[string]any{"type": "output_text", "text": `{"answer":"42"}`}}}},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{Endpoint: srv.URL, APIKey: "azure-key", Mode: openai.ModeResponses})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.Call(context.Background(), aiwf.ModelCall{Model: "my-deployment", UserPrompt: "?", OutputTypeName: "Answer"}); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if path != "/openai/responses" || version != DefaultResponsesAPIVersion || model != "my-deployment" {
+		t.Fatalf("unexpected request: path=%s version=%s model=%s", path, version, model)
+	}
+}
+
+func TestNewFromConfigDeployments(t *testing.T) {
+	mc, err := newFromConfig(aiwf.ProviderConfig{
+		BaseURL:     "https://my-resource.openai.azure.com",
+		Deployments: map[string]string{"gpt-4o": "prod-gpt4o"},
+		Getenv:      func(key string) string { return map[string]string{"AZURE_OPENAI_API_KEY": "azure-key"}[key] },
+	})
+	if err != nil {
+		t.Fatalf("newFromConfig: %v", err)
+	}
+	client := mc.(*Client)
+	if got := client.Deployment("gpt-4o"); got != "prod-gpt4o" {
+		t.Fatalf("expected deployment from providers:, got %q", got)
+	}
+	if got := client.Deployment("gpt-4o-mini"); got != "gpt-4o-mini" {
+		t.Fatalf("unlisted model must be used as deployment, got %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package azure
+
+import (
+	"github.com/andranikuz/aiwf/providers/openai"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+// Регистрирует провайдера под именем use: azure.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "azure",
+		ImportPath:   "github.com/andranikuz/aiwf/providers/azure",
+		EnvKeys:      []string{"AZURE_OPENAI_API_KEY", "AZURE_OPENAI_ENDPOINT"},
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityThreads, aiwf.CapabilityPromptCache, aiwf.CapabilityEmbeddings},
+		New:          newFromConfig,
+		// По умолчанию используется Chat Completions, где поддержаны все параметры
+		IgnoredOptions: func(mode string) []string { return openai.IgnoredOptions(openai.Mode(mode)) },
+	})
+}
+
+// newFromConfig создаёт клиента Azure OpenAI из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	endpoint := cfg.BaseURL
+	if endpoint == "" {
+		endpoint = cfg.Env("AZURE_OPENAI_ENDPOINT")
+	}
+	apiKey := cfg.APIKey("AZURE_OPENAI_API_KEY")
+	if apiKey == "" || endpoint == "" {
+		return nil, aiwf.ErrProviderNotConfigured
+	}
+	return NewClient(ClientConfig{
+		Endpoint:    endpoint,
+		APIKey:      apiKey,
+		APIVersion:  cfg.Env("AZURE_OPENAI_API_VERSION"),
+		HTTPClient:  cfg.HTTPClient(),
+		Timeout:     cfg.Timeout,
+		Mode:        openai.Mode(cfg.Mode),
+		Deployments: cfg.Deployments,
+	})
+}
@@ -0,0 +1,429 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/andranikuz/aiwf/providers/internal/sse"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+const (
+	defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+// ClientConfig определяет параметры доступа к Gemini API.
+type ClientConfig struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	Timeout    time.Duration
+}
+
+// Client реализует aiwf.ModelClient для Google Gemini.
+type Client struct {
+	baseURL   string
+	apiKey    string
+	http      *http.Client
+	threadMgr aiwf.ThreadManager
+}
+
+// NewClient создаёт клиента для Gemini.
+func NewClient(cfg ClientConfig) (*Client, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("gemini: api key is required")
+	}
+
+	base := cfg.BaseURL
+	if base == "" {
+		base = defaultBaseURL
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	} else if httpClient.Timeout == 0 {
+		httpClient.Timeout = timeout
+	}
+
+	return &Client{
+		baseURL: strings.TrimRight(base, "/"),
+		apiKey:  cfg.APIKey,
+		http:    httpClient,
+	}, nil
+}
+
+// WithThreadManager устанавливает менеджер тредов.
+// Если он реализует aiwf.ThreadHistory, предыдущие реплики треда передаются модели.
+func (c *Client) WithThreadManager(tm aiwf.ThreadManager) *Client {
+	c.threadMgr = tm
+	return c
+}
+
+// CallJSONSchema выполняет запрос к Gemini API.
+func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
+	result, err := c.Call(ctx, call)
+	if err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
+	return result.Data, result.Usage, nil
+}
+
+// Call выполняет generateContent и сообщает причину остановки генерации.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	history, err := c.threadHistory(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, call, history, false)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: failed to create request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("gemini: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	var parsed GenerateContentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("gemini: failed to decode response: %w", err)
+	}
+
+	if len(parsed.Candidates) == 0 {
+		if parsed.PromptFeedback != nil && parsed.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("gemini: prompt blocked: %s", parsed.PromptFeedback.BlockReason)
+		}
+		return nil, errors.New("gemini: empty response candidates")
+	}
+
+	candidate := parsed.Candidates[0]
+	result := &aiwf.CallResult{
+		Data:          []byte(candidate.Content.text()),
+		Usage:         parsed.UsageMetadata.tokens(),
+		StopReason:    mapFinishReason(candidate.FinishReason),
+		RawStopReason: candidate.FinishReason,
+	}
+
+	if err := c.recordTurn(ctx, call, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CallJSONSchemaStream выполняет streamGenerateContent (SSE).
+// Расход токенов передаётся в финальном чанке.
+func (c *Client) CallJSONSchemaStream(ctx context.Context, call aiwf.ModelCall) (<-chan aiwf.StreamChunk, aiwf.Tokens, error) {
+	history, err := c.threadHistory(ctx, call)
+	if err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
+
+	req, err := c.newRequest(ctx, call, history, true)
+	if err != nil {
+		return nil, aiwf.Tokens{}, fmt.Errorf("gemini: failed to create request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, aiwf.Tokens{}, fmt.Errorf("gemini: request failed: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, aiwf.Tokens{}, fmt.Errorf("gemini: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	ch := make(chan aiwf.StreamChunk)
+	go c.readStream(ctx, call, resp.Body, ch)
+	return ch, aiwf.Tokens{}, nil
+}
+
+// readStream разбирает SSE-поток: каждое событие — частичный GenerateContentResponse.
+func (c *Client) readStream(ctx context.Context, call aiwf.ModelCall, body io.ReadCloser, ch chan<- aiwf.StreamChunk) {
+	defer close(ch)
+	defer body.Close()
+
+	var (
+		content      []byte
+		usage        aiwf.Tokens
+		finishReason string
+	)
+
+	reader := sse.NewReader(body)
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+
+		var chunk GenerateContentResponse
+		if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+			continue
+		}
+		if chunk.UsageMetadata.TotalTokenCount > 0 {
+			usage = chunk.UsageMetadata.tokens()
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		candidate := chunk.Candidates[0]
+		if candidate.FinishReason != "" {
+			finishReason = candidate.FinishReason
+		}
+		text := candidate.Content.text()
+		if text == "" {
+			continue
+		}
+		content = append(content, text...)
+
+		select {
+		case ch <- aiwf.StreamChunk{Data: []byte(text)}:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	result := &aiwf.CallResult{Data: content, Usage: usage, StopReason: mapFinishReason(finishReason), RawStopReason: finishReason}
+	err := c.recordTurn(ctx, call, result)
+
+	select {
+	case ch <- aiwf.StreamChunk{Done: true, Usage: &usage, Err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// mapFinishReason переводит finishReason Gemini API в aiwf.StopReason.
+func mapFinishReason(reason string) aiwf.StopReason {
+	switch reason {
+	case "STOP":
+		return aiwf.StopReasonEnd
+	case "MAX_TOKENS":
+		return aiwf.StopReasonMaxTokens
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return aiwf.StopReasonContentFilter
+	default:
+		return aiwf.StopReasonUnknown
+	}
+}
+
+// threadHistory возвращает реплики треда, если менеджер тредов их хранит.
+func (c *Client) threadHistory(ctx context.Context, call aiwf.ModelCall) ([]aiwf.Message, error) {
+	store, ok := c.threadMgr.(aiwf.ThreadHistory)
+	if !ok || call.ThreadID == "" {
+		return nil, nil
+	}
+	history, err := store.History(ctx, call.ThreadID)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: failed to load thread history: %w", err)
+	}
+	return history, nil
+}
+
+// recordTurn сохраняет завершённый ход в тред. Обрезанные ответы пропускаются:
+// рантайм допишет их продолжениями и сохранит итог через RecordTurn.
+func (c *Client) recordTurn(ctx context.Context, call aiwf.ModelCall, result *aiwf.CallResult) error {
+	if result.StopReason == aiwf.StopReasonMaxTokens {
+		return nil
+	}
+	return c.RecordTurn(ctx, call, result)
+}
+
+// RecordTurn сохраняет запрос и ответ в тред (aiwf.TurnRecorder). Вызовы
+// с явной историей — продолжения рантайма — не сохраняются.
+func (c *Client) RecordTurn(ctx context.Context, call aiwf.ModelCall, result *aiwf.CallResult) error {
+	store, ok := c.threadMgr.(aiwf.ThreadHistory)
+	if !ok || call.ThreadID == "" || len(call.History) > 0 {
+		return nil
+	}
+
+	userMessage, err := userContent(call)
+	if err != nil {
+		return err
+	}
+	if err := store.Append(ctx, call.ThreadID,
+		aiwf.Message{Role: "user", Content: userMessage},
+		aiwf.Message{Role: "assistant", Content: string(result.Data)},
+	); err != nil {
+		return fmt.Errorf("gemini: failed to save thread history: %w", err)
+	}
+	return nil
+}
+
+// userContent собирает текст пользовательского сообщения из промпта и входных данных.
+func userContent(call aiwf.ModelCall) (string, error) {
+	content, err := aiwf.UserContent(call)
+	if err != nil {
+		return "", fmt.Errorf("gemini: %w", err)
+	}
+	return content, nil
+}
+
+// newRequest создаёт HTTP запрос generateContent или streamGenerateContent.
+func (c *Client) newRequest(ctx context.Context, call aiwf.ModelCall, history []aiwf.Message, stream bool) (*http.Request, error) {
+	var contents []Content
+	for _, msg := range append(history, call.History...) {
+		contents = append(contents, Content{Role: geminiRole(msg.Role), Parts: []Part{{Text: msg.Content}}})
+	}
+
+	userMessage, err := userContent(call)
+	if err != nil {
+		return nil, err
+	}
+	contents = append(contents, Content{Role: "user", Parts: []Part{{Text: userMessage}}})
+
+	config := &GenerationConfig{
+		Temperature:      call.Temperature,
+		TopP:             call.TopP,
+		Seed:             call.Seed,
+		StopSequences:    call.Stop,
+		PresencePenalty:  call.PresencePenalty,
+		FrequencyPenalty: call.FrequencyPenalty,
+		MaxOutputTokens:  call.MaxTokens,
+	}
+	if call.OutputTypeName != "" && call.OutputTypeName != "string" {
+		config.ResponseMimeType = "application/json"
+		if call.TypeMetadata != nil {
+			schema, err := core.SchemaFromMetadata(call.TypeMetadata, core.DialectGemini)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build output schema: %w", err)
+			}
+			config.ResponseSchema = schema
+		}
+	}
+
+	payload := GenerateContentRequest{
+		Contents:         contents,
+		GenerationConfig: config,
+	}
+	if call.SystemPrompt != "" {
+		payload.SystemInstruction = &Content{Parts: []Part{{Text: call.SystemPrompt}}}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, url.PathEscape(call.Model))
+	if stream {
+		endpoint = fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", c.baseURL, url.PathEscape(call.Model))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-goog-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// geminiRole переводит роль aiwf в роль Gemini (assistant → model).
+func geminiRole(role string) string {
+	if role == "assistant" {
+		return "model"
+	}
+	return "user"
+}
+
+// GenerateContentRequest - структура запроса к Gemini API
+type GenerateContentRequest struct {
+	Contents          []Content         `json:"contents"`
+	SystemInstruction *Content          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *GenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// Content - сообщение диалога
+type Content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []Part `json:"parts"`
+}
+
+func (c Content) text() string {
+	var sb strings.Builder
+	for _, part := range c.Parts {
+		sb.WriteString(part.Text)
+	}
+	return sb.String()
+}
+
+// Part - часть сообщения
+type Part struct {
+	Text string `json:"text"`
+}
+
+// GenerationConfig - параметры генерации
+type GenerationConfig struct {
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"topP,omitempty"`
+	Seed             *int            `json:"seed,omitempty"`
+	StopSequences    []string        `json:"stopSequences,omitempty"`
+	PresencePenalty  *float64        `json:"presencePenalty,omitempty"`
+	FrequencyPenalty *float64        `json:"frequencyPenalty,omitempty"`
+	MaxOutputTokens  int             `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType string          `json:"responseMimeType,omitempty"`
+	ResponseSchema   json.RawMessage `json:"responseSchema,omitempty"`
+}
+
+// GenerateContentResponse - структура ответа от Gemini API
+type GenerateContentResponse struct {
+	Candidates     []Candidate     `json:"candidates"`
+	UsageMetadata  UsageMetadata   `json:"usageMetadata"`
+	PromptFeedback *PromptFeedback `json:"promptFeedback,omitempty"`
+	ModelVersion   string          `json:"modelVersion,omitempty"`
+}
+
+// Candidate - вариант ответа
+type Candidate struct {
+	Content      Content `json:"content"`
+	FinishReason string  `json:"finishReason"`
+}
+
+// PromptFeedback - причина блокировки запроса
+type PromptFeedback struct {
+	BlockReason string `json:"blockReason"`
+}
+
+// UsageMetadata - информация об использованных токенах
+type UsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+func (u UsageMetadata) tokens() aiwf.Tokens {
+	return aiwf.Tokens{
+		Prompt:     u.PromptTokenCount,
+		Completion: u.CandidatesTokenCount,
+		Total:      u.TotalTokenCount,
+	}
+}
@@ -0,0 +1,254 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "gemini-key"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client
+}
+
+func TestCallStructuredOutput(t *testing.T) {
+	var (
+		path    string
+		apiKey  string
+		request GenerateContentRequest
+		schema  map[string]any
+	)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		apiKey = r.Header.Get("x-goog-api-key")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		_ = json.Unmarshal(request.GenerationConfig.ResponseSchema, &schema)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{
+				"content":      map[string]any{"role": "model", "parts": []any{map[string]any{"text": `{"answer":"42"}`}}},
+				"finishReason": "STOP",
+			}},
+			"usageMetadata": map[string]any{"promptTokenCount": 7, "candidatesTokenCount": 3, "totalTokenCount": 10},
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:          "gemini-2.5-flash",
+		SystemPrompt:   "Answer briefly",
+		Payload:        map[string]string{"question": "?"},
+		History:        []aiwf.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}},
+		OutputTypeName: "Answer",
+		TypeMetadata: map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"answer": map[string]any{"type": "string"}},
+			"required":             []any{"answer"},
+			"additionalProperties": false,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	if path != "/models/gemini-2.5-flash:generateContent" {
+		t.Fatalf("unexpected path: %s", path)
+	}
+	if apiKey != "gemini-key" {
+		t.Fatalf("expected api key header, got %q", apiKey)
+	}
+	if request.SystemInstruction == nil || request.SystemInstruction.Parts[0].Text != "Answer briefly" {
+		t.Fatalf("expected systemInstruction, got %+v", request.SystemInstruction)
+	}
+	if len(request.Contents) != 3 || request.Contents[1].Role != "model" {
+		t.Fatalf("expected multi-turn contents, got %+v", request.Contents)
+	}
+	if request.GenerationConfig.ResponseMimeType != "application/json" || schema["type"] != "OBJECT" {
+		t.Fatalf("expected JSON response schema, got %+v / %v", request.GenerationConfig, schema)
+	}
+	if _, ok := schema["additionalProperties"]; ok {
+		t.Fatalf("additionalProperties must be stripped: %v", schema)
+	}
+
+	if string(result.Data) != `{"answer":"42"}` {
+		t.Fatalf("unexpected data: %s", result.Data)
+	}
+	if result.Usage.Total != 10 || result.StopReason != aiwf.StopReasonEnd {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestCallSendsPromptWithPayload(t *testing.T) {
+	var request GenerateContentRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{
+				"content":      map[string]any{"parts": []any{map[string]any{"text": "ok"}}},
+				"finishReason": "STOP",
+			}},
+		})
+	})
+
+	_, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:      "gemini-2.5-flash",
+		UserPrompt: "Справка: отпуск 28 дней",
+		Payload:    map[string]string{"question": "?"},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	content := request.Contents[len(request.Contents)-1].Parts[0].Text
+	if !strings.Contains(content, "отпуск 28 дней") || !strings.Contains(content, `{"question":"?"}`) {
+		t.Fatalf("expected prompt and payload in user message, got %q", content)
+	}
+}
+
+func TestCallReportsMaxTokens(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{
+				"content":      map[string]any{"parts": []any{map[string]any{"text": "Once upon"}}},
+				"finishReason": "MAX_TOKENS",
+			}},
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{Model: "gemini-2.5-flash", UserPrompt: "story"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if result.StopReason != aiwf.StopReasonMaxTokens {
+		t.Fatalf("expected max_tokens, got %s", result.StopReason)
+	}
+}
+
+func TestCallJSONSchemaStream(t *testing.T) {
+	var query string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hel\"}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":2,\"candidatesTokenCount\":2,\"totalTokenCount\":4}}\n\n")
+	})
+
+	ch, _, err := client.CallJSONSchemaStream(context.Background(), aiwf.ModelCall{Model: "gemini-2.5-flash", UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("CallJSONSchemaStream: %v", err)
+	}
+
+	var text string
+	var last aiwf.StreamChunk
+	for chunk := range ch {
+		text += string(chunk.Data)
+		last = chunk
+	}
+	if query != "alt=sse" {
+		t.Fatalf("expected alt=sse, got %q", query)
+	}
+	if text != "Hello" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if !last.Done || last.Usage == nil || last.Usage.Total != 4 {
+		t.Fatalf("expected final chunk with usage, got %+v", last)
+	}
+}
+
+type memoryThreads struct {
+	messages  map[string][]aiwf.Message
+	appendErr error
+}
+
+func (m *memoryThreads) Start(ctx context.Context, assistant string, binding aiwf.ThreadBinding) (*aiwf.ThreadState, error) {
+	return &aiwf.ThreadState{ID: assistant}, nil
+}
+
+func (m *memoryThreads) Continue(ctx context.Context, state *aiwf.ThreadState, feedback string) error {
+	return nil
+}
+
+func (m *memoryThreads) Close(ctx context.Context, state *aiwf.ThreadState) error {
+	return nil
+}
+
+func (m *memoryThreads) History(ctx context.Context, threadID string) ([]aiwf.Message, error) {
+	return m.messages[threadID], nil
+}
+
+func (m *memoryThreads) Append(ctx context.Context, threadID string, messages ...aiwf.Message) error {
+	if m.appendErr != nil {
+		return m.appendErr
+	}
+	if m.messages == nil {
+		m.messages = make(map[string][]aiwf.Message)
+	}
+	m.messages[threadID] = append(m.messages[threadID], messages...)
+	return nil
+}
+
+func TestContinuedTurnIsRecorded(t *testing.T) {
+	var requests int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		text, reason := "Hello, ", "MAX_TOKENS"
+		if requests > 1 {
+			text, reason = "world!", "STOP"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []any{map[string]any{
+				"content":      map[string]any{"parts": []any{map[string]any{"text": text}}},
+				"finishReason": reason,
+			}},
+		})
+	})
+	threads := &memoryThreads{}
+	client.WithThreadManager(threads)
+
+	agent := &aiwf.AgentBase{
+		Config: aiwf.AgentConfig{Name: "writer", Model: "gemini-2.5-flash", OutputTypeName: "string", MaxTokens: 2},
+		Client: client,
+	}
+	if _, _, err := agent.CallModel(context.Background(), "greet", &aiwf.ThreadState{ID: "t1"}); err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+
+	history := threads.messages["t1"]
+	if len(history) != 2 || history[0].Content != `"greet"` || history[1].Content != "Hello, world!" {
+		t.Fatalf("expected assembled turn in thread, got %+v", history)
+	}
+}
+
+func TestCallJSONSchemaStreamReportsHistoryError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hi\"}]},\"finishReason\":\"STOP\"}]}\n\n")
+	})
+	client.WithThreadManager(&memoryThreads{appendErr: fmt.Errorf("store is down")})
+
+	ch, _, err := client.CallJSONSchemaStream(context.Background(), aiwf.ModelCall{Model: "gemini-2.5-flash", UserPrompt: "hi", ThreadID: "t1"})
+	if err != nil {
+		t.Fatalf("CallJSONSchemaStream: %v", err)
+	}
+
+	var last aiwf.StreamChunk
+	for chunk := range ch {
+		last = chunk
+	}
+	if !last.Done || last.Err == nil || !strings.Contains(last.Err.Error(), "store is down") {
+		t.Fatalf("expected history error in final chunk, got %+v", last)
+	}
+}
@@ -0,0 +1,24 @@
+package gemini
+
+import "github.com/andranikuz/aiwf/runtime/go/aiwf"
+
+// Регистрирует провайдера под именем use: gemini.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:           "gemini",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/gemini",
+		EnvKeys:        []string{"GEMINI_API_KEY"},
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityStreaming, aiwf.CapabilityThreads},
+		New:            newFromConfig,
+		IgnoredOptions: func(string) []string { return []string{"reasoning_effort"} },
+	})
+}
+
+// newFromConfig создаёт клиента Gemini из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	apiKey := cfg.APIKey("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, aiwf.ErrProviderNotConfigured
+	}
+	return NewClient(ClientConfig{BaseURL: cfg.BaseURL, APIKey: apiKey, HTTPClient: cfg.HTTPClient(), Timeout: cfg.Timeout})
+}
@@ -10,11 +10,15 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/andranikuz/aiwf/providers/internal/sse"
 	"github.com/andranikuz/aiwf/runtime/go/aiwf"
 )
 
 const (
 	defaultBaseURL = "https://api.x.ai/v1"
+
+	defaultMaxTokens = 2000
 )
 
 // ClientConfig определяет параметры доступа к Grok API (xAI).
@@ -72,48 +76,294 @@ func (c *Client) WithThreadManager(tm aiwf.ThreadManager) *Client {
 
 // CallJSONSchema выполняет запрос к Grok API.
 func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
-	req, err := c.newChatRequest(ctx, call)
+	result, err := c.Call(ctx, call)
 	if err != nil {
-		return nil, aiwf.Tokens{}, fmt.Errorf("grok: failed to create request: %w", err)
+		return nil, aiwf.Tokens{}, err
+	}
+	return result.Data, result.Usage, nil
+}
+
+// Call выполняет запрос к Grok API и сообщает причину остановки генерации.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	history, err := c.threadHistory(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newChatRequest(ctx, call, history, false)
+	if err != nil {
+		return nil, fmt.Errorf("grok: failed to create request: %w", err)
 	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, aiwf.Tokens{}, fmt.Errorf("grok: request failed: %w", err)
+		return nil, fmt.Errorf("grok: request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
 		buf, _ := io.ReadAll(resp.Body)
-		return nil, aiwf.Tokens{}, fmt.Errorf("grok: unexpected status %d: %s", resp.StatusCode, string(buf))
+		return nil, fmt.Errorf("grok: unexpected status %d: %s", resp.StatusCode, string(buf))
 	}
 
 	var parsed ChatCompletion
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, aiwf.Tokens{}, fmt.Errorf("grok: failed to decode response: %w", err)
+		return nil, fmt.Errorf("grok: failed to decode response: %w", err)
 	}
 
 	if len(parsed.Choices) == 0 {
-		return nil, aiwf.Tokens{}, errors.New("grok: empty response choices")
+		return nil, errors.New("grok: empty response choices")
 	}
 
-	content := parsed.Choices[0].Message.Content
+	choice := parsed.Choices[0]
 	usage := aiwf.Tokens{
 		Prompt:     parsed.Usage.PromptTokens,
 		Completion: parsed.Usage.CompletionTokens,
 		Total:      parsed.Usage.TotalTokens,
 	}
 
-	return []byte(content), usage, nil
+	data := []byte(choice.Message.Content)
+	if wrappedOutput(call) && mapFinishReason(choice.FinishReason) != aiwf.StopReasonMaxTokens {
+		if data, err = core.UnwrapValue(data); err != nil {
+			return nil, fmt.Errorf("grok: %w", err)
+		}
+	}
+
+	result := &aiwf.CallResult{
+		Data:          data,
+		Usage:         usage,
+		StopReason:    mapFinishReason(choice.FinishReason),
+		RawStopReason: choice.FinishReason,
+	}
+
+	if err := c.recordTurn(ctx, call, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// threadHistory возвращает реплики треда, если менеджер тредов их хранит.
+func (c *Client) threadHistory(ctx context.Context, call aiwf.ModelCall) ([]aiwf.Message, error) {
+	store, ok := c.threadMgr.(aiwf.ThreadHistory)
+	if !ok || call.ThreadID == "" {
+		return nil, nil
+	}
+	history, err := store.History(ctx, call.ThreadID)
+	if err != nil {
+		return nil, fmt.Errorf("grok: failed to load thread history: %w", err)
+	}
+	return history, nil
+}
+
+// recordTurn сохраняет завершённый ход в тред. Обрезанные ответы пропускаются:
+// рантайм допишет их продолжениями и сохранит итог через RecordTurn.
+func (c *Client) recordTurn(ctx context.Context, call aiwf.ModelCall, result *aiwf.CallResult) error {
+	if result.StopReason == aiwf.StopReasonMaxTokens {
+		return nil
+	}
+	return c.RecordTurn(ctx, call, result)
+}
+
+// RecordTurn сохраняет запрос и ответ в тред (aiwf.TurnRecorder). Вызовы
+// с явной историей — продолжения рантайма — не сохраняются.
+func (c *Client) RecordTurn(ctx context.Context, call aiwf.ModelCall, result *aiwf.CallResult) error {
+	store, ok := c.threadMgr.(aiwf.ThreadHistory)
+	if !ok || call.ThreadID == "" || len(call.History) > 0 {
+		return nil
+	}
+
+	userMessage, err := userContent(call)
+	if err != nil {
+		return err
+	}
+	if err := store.Append(ctx, call.ThreadID,
+		aiwf.Message{Role: "user", Content: userMessage},
+		aiwf.Message{Role: "assistant", Content: string(result.Data)},
+	); err != nil {
+		return fmt.Errorf("grok: failed to save thread history: %w", err)
+	}
+	return nil
+}
+
+// mapFinishReason переводит finish_reason Chat API в aiwf.StopReason.
+func mapFinishReason(reason string) aiwf.StopReason {
+	switch reason {
+	case "stop":
+		return aiwf.StopReasonEnd
+	case "length":
+		return aiwf.StopReasonMaxTokens
+	case "content_filter":
+		return aiwf.StopReasonContentFilter
+	case "tool_calls":
+		return aiwf.StopReasonToolUse
+	default:
+		return aiwf.StopReasonUnknown
+	}
 }
 
-// CallJSONSchemaStream не реализовано для Grok
+// CallJSONSchemaStream выполняет потоковый запрос к Chat API (SSE).
+// Расход токенов передаётся в финальном чанке.
 func (c *Client) CallJSONSchemaStream(ctx context.Context, call aiwf.ModelCall) (<-chan aiwf.StreamChunk, aiwf.Tokens, error) {
-	return nil, aiwf.Tokens{}, errors.New("grok: streaming not implemented")
+	history, err := c.threadHistory(ctx, call)
+	if err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
+
+	req, err := c.newChatRequest(ctx, call, history, true)
+	if err != nil {
+		return nil, aiwf.Tokens{}, fmt.Errorf("grok: failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, aiwf.Tokens{}, fmt.Errorf("grok: request failed: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, aiwf.Tokens{}, fmt.Errorf("grok: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	ch := make(chan aiwf.StreamChunk)
+	go c.readStream(ctx, call, resp.Body, ch)
+	return ch, aiwf.Tokens{}, nil
+}
+
+// readStream разбирает SSE-поток и пересылает дельты в канал.
+func (c *Client) readStream(ctx context.Context, call aiwf.ModelCall, body io.ReadCloser, ch chan<- aiwf.StreamChunk) {
+	defer close(ch)
+	defer body.Close()
+
+	var (
+		content      []byte
+		usage        aiwf.Tokens
+		finishReason string
+	)
+
+	reader := sse.NewReader(body)
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			usage = aiwf.Tokens{
+				Prompt:     chunk.Usage.PromptTokens,
+				Completion: chunk.Usage.CompletionTokens,
+				Total:      chunk.Usage.TotalTokens,
+			}
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			finishReason = choice.FinishReason
+		}
+		if choice.Delta.Content == "" {
+			continue
+		}
+		content = append(content, choice.Delta.Content...)
+
+		select {
+		case ch <- aiwf.StreamChunk{Data: []byte(choice.Delta.Content)}:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	// Дельты обёрнутого ответа уже отправлены как есть; в тред сохраняется само значение
+	if wrappedOutput(call) && mapFinishReason(finishReason) != aiwf.StopReasonMaxTokens {
+		if value, err := core.UnwrapValue(content); err == nil {
+			content = value
+		}
+	}
+	result := &aiwf.CallResult{Data: content, Usage: usage, StopReason: mapFinishReason(finishReason), RawStopReason: finishReason}
+	err := c.recordTurn(ctx, call, result)
+
+	select {
+	case ch <- aiwf.StreamChunk{Done: true, Usage: &usage, Err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// userContent собирает текст пользовательского сообщения из промпта и входных данных.
+func userContent(call aiwf.ModelCall) (string, error) {
+	content, err := aiwf.UserContent(call)
+	if err != nil {
+		return "", fmt.Errorf("grok: %w", err)
+	}
+	return content, nil
+}
+
+// buildResponseFormat описывает выходной тип как response_format json_schema.
+func buildResponseFormat(call aiwf.ModelCall) (*ResponseFormat, error) {
+	if call.OutputTypeName == "" || call.OutputTypeName == "string" || call.TypeMetadata == nil {
+		return nil, nil
+	}
+
+	schema, err := outputSchema(call)
+	if err != nil {
+		return nil, fmt.Errorf("grok: failed to build output schema: %w", err)
+	}
+	// Grok принимает только объектный корень схемы
+	if wrapped, ok := core.WrapValueSchema(schema); ok {
+		schema = wrapped
+	}
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("grok: failed to build output schema: %w", err)
+	}
+
+	return &ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &JSONSchemaFormat{
+			Name:   call.OutputTypeName,
+			Schema: raw,
+			Strict: true,
+		},
+	}, nil
+}
+
+// outputSchema возвращает схему выходного типа в диалекте Grok.
+func outputSchema(call aiwf.ModelCall) (map[string]any, error) {
+	raw, err := core.SchemaFromMetadata(call.TypeMetadata, core.DialectGrok)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// wrappedOutput сообщает, обёрнут ли выходной тип в объект с полем value.
+func wrappedOutput(call aiwf.ModelCall) bool {
+	if call.OutputTypeName == "" || call.OutputTypeName == "string" || call.TypeMetadata == nil {
+		return false
+	}
+	schema, err := outputSchema(call)
+	if err != nil {
+		return false
+	}
+	_, wrapped := core.WrapValueSchema(schema)
+	return wrapped
 }
 
 // newChatRequest создаёт HTTP запрос для Chat API.
-func (c *Client) newChatRequest(ctx context.Context, call aiwf.ModelCall) (*http.Request, error) {
+func (c *Client) newChatRequest(ctx context.Context, call aiwf.ModelCall, history []aiwf.Message, stream bool) (*http.Request, error) {
 	messages := []Message{
 		{
 			Role:    "system",
@@ -121,14 +371,13 @@ func (c *Client) newChatRequest(ctx context.Context, call aiwf.ModelCall) (*http
 		},
 	}
 
-	// Используем Payload как входные данные (уже типизированные)
-	userMessage := call.UserPrompt
-	if call.Payload != nil {
-		inputJSON, err := json.Marshal(call.Payload)
-		if err != nil {
-			return nil, err
-		}
-		userMessage = string(inputJSON)
+	for _, msg := range append(history, call.History...) {
+		messages = append(messages, Message{Role: msg.Role, Content: msg.Content})
+	}
+
+	userMessage, err := userContent(call)
+	if err != nil {
+		return nil, err
 	}
 
 	messages = append(messages, Message{
@@ -136,11 +385,32 @@ func (c *Client) newChatRequest(ctx context.Context, call aiwf.ModelCall) (*http
 		Content: userMessage,
 	})
 
+	responseFormat, err := buildResponseFormat(call)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTokens := call.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultMaxTokens
+	}
+
 	payload := ChatRequest{
-		Model:       call.Model,
-		Messages:    messages,
-		Temperature: 0.7,
-		MaxTokens:   2000,
+		Model:            call.Model,
+		Messages:         messages,
+		Temperature:      call.Temperature,
+		MaxTokens:        maxTokens,
+		TopP:             call.TopP,
+		Seed:             call.Seed,
+		Stop:             call.Stop,
+		PresencePenalty:  call.PresencePenalty,
+		FrequencyPenalty: call.FrequencyPenalty,
+		ReasoningEffort:  call.ReasoningEffort,
+		ResponseFormat:   responseFormat,
+	}
+	if stream {
+		payload.Stream = true
+		payload.StreamOptions = &StreamOptions{IncludeUsage: true}
 	}
 
 	body, err := json.Marshal(payload)
@@ -168,11 +438,38 @@ type Message struct {
 
 // ChatRequest - структура запроса к Grok Chat API
 type ChatRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature,omitempty"`
-	MaxTokens   int       `json:"max_tokens,omitempty"`
-	TopP        float64   `json:"top_p,omitempty"`
+	Model            string    `json:"model"`
+	Messages         []Message `json:"messages"`
+	Temperature      *float64  `json:"temperature,omitempty"`
+	MaxTokens        int       `json:"max_tokens,omitempty"`
+	TopP             *float64  `json:"top_p,omitempty"`
+	Seed             *int      `json:"seed,omitempty"`
+	Stop             []string  `json:"stop,omitempty"`
+	PresencePenalty  *float64  `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64  `json:"frequency_penalty,omitempty"`
+	ReasoningEffort  string    `json:"reasoning_effort,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// ResponseFormat - формат структурированного ответа
+type ResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaFormat - JSON Schema выходного типа
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+// StreamOptions - параметры потоковой генерации
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ChatCompletion - структура ответа от Grok API
@@ -197,4 +494,19 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
-}
\ No newline at end of file
+}
+
+// ChatCompletionChunk - событие потокового ответа
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Model   string        `json:"model"`
+	Choices []ChunkChoice `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+}
+
+// ChunkChoice - дельта выбора в потоковом ответе
+type ChunkChoice struct {
+	Index        int     `json:"index"`
+	Delta        Message `json:"delta"`
+	FinishReason string  `json:"finish_reason"`
+}
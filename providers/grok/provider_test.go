@@ -0,0 +1,276 @@
+package grok
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/aiwf/providers/openai"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "xai-test"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client
+}
+
+func TestCallSendsResponseFormatAndThreadHistory(t *testing.T) {
+	var requests []ChatRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, req)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": fmt.Sprintf(`{"n":%d}`, len(requests))},
+				"finish_reason": "stop",
+			}},
+		})
+	})
+
+	threads := openai.NewInMemoryThreadManager()
+	client.WithThreadManager(threads)
+	state, err := threads.Start(context.Background(), "counter", aiwf.ThreadBinding{})
+	if err != nil {
+		t.Fatalf("start thread: %v", err)
+	}
+
+	call := aiwf.ModelCall{
+		Model:          "grok-4",
+		UserPrompt:     "count",
+		ThreadID:       state.ID,
+		OutputTypeName: "Count",
+		TypeMetadata: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"n": map[string]any{"type": "integer", "minimum": 0}},
+			"required":   []any{"n"},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call(context.Background(), call); err != nil {
+			t.Fatalf("Call %d: %v", i, err)
+		}
+	}
+
+	format := requests[0].ResponseFormat
+	if format == nil || format.Type != "json_schema" || format.JSONSchema.Name != "Count" {
+		t.Fatalf("expected json_schema response_format, got %+v", format)
+	}
+
+	second := requests[1].Messages
+	if len(second) != 4 || second[1].Content != "count" || second[2].Content != `{"n":1}` {
+		t.Fatalf("expected previous turn in second request, got %+v", second)
+	}
+}
+
+func TestCallSendsPromptWithPayload(t *testing.T) {
+	var request ChatRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]any{"content": "ok"}, "finish_reason": "stop"}},
+		})
+	})
+
+	_, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:      "grok-4",
+		UserPrompt: "Справка: отпуск 28 дней",
+		Payload:    map[string]string{"question": "?"},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	content := request.Messages[len(request.Messages)-1].Content
+	if !strings.Contains(content, "отпуск 28 дней") || !strings.Contains(content, `{"question":"?"}`) {
+		t.Fatalf("expected prompt and payload in user message, got %q", content)
+	}
+}
+
+func TestCallWrapsUnionOutput(t *testing.T) {
+	var request ChatRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{
+				"message":       map[string]any{"content": `{"value":{"kind":"escalation","team":"billing"}}`},
+				"finish_reason": "stop",
+			}},
+		})
+	})
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:          "grok-4",
+		UserPrompt:     "ticket",
+		OutputTypeName: "Resolution",
+		TypeMetadata: map[string]any{"anyOf": []any{
+			map[string]any{"type": "object", "properties": map[string]any{"kind": map[string]any{"type": "string"}, "amount": map[string]any{"type": "number"}}},
+			map[string]any{"type": "object", "properties": map[string]any{"kind": map[string]any{"type": "string"}, "team": map[string]any{"type": "string"}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	var schema map[string]any
+	_ = json.Unmarshal(request.ResponseFormat.JSONSchema.Schema, &schema)
+	value, _ := schema["properties"].(map[string]any)["value"].(map[string]any)
+	if schema["type"] != "object" || value["anyOf"] == nil {
+		t.Fatalf("expected union wrapped in an object, got %v", schema)
+	}
+	if string(result.Data) != `{"kind":"escalation","team":"billing"}` {
+		t.Fatalf("expected unwrapped value, got %s", result.Data)
+	}
+}
+
+func TestCallSendsGenerationParams(t *testing.T) {
+	var raw map[string]any
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&raw)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]any{"content": "ok"}, "finish_reason": "stop"}},
+		})
+	})
+
+	call := aiwf.ModelCall{Model: "grok-4", UserPrompt: "hi", Seed: aiwf.Int(7), Stop: []string{"END"}}
+	aiwf.WithTemperature(0)(&call)
+	if _, err := client.Call(context.Background(), call); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	if temperature, ok := raw["temperature"]; !ok || temperature != 0.0 {
+		t.Fatalf("expected explicit zero temperature, got %v", raw["temperature"])
+	}
+	if raw["seed"] != 7.0 || fmt.Sprint(raw["stop"]) != "[END]" {
+		t.Fatalf("unexpected params: seed=%v stop=%v", raw["seed"], raw["stop"])
+	}
+	if _, ok := raw["top_p"]; ok {
+		t.Fatalf("unset top_p must be omitted, got %v", raw["top_p"])
+	}
+
+	raw = nil
+	if _, err := client.Call(context.Background(), aiwf.ModelCall{Model: "grok-4", UserPrompt: "hi"}); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if _, ok := raw["temperature"]; ok {
+		t.Fatalf("unset temperature must be omitted, got %v", raw["temperature"])
+	}
+}
+
+func TestCallJSONSchemaStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("expected stream request with usage, got %+v", req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	ch, _, err := client.CallJSONSchemaStream(context.Background(), aiwf.ModelCall{UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("CallJSONSchemaStream: %v", err)
+	}
+
+	var text string
+	var last aiwf.StreamChunk
+	for chunk := range ch {
+		text += string(chunk.Data)
+		last = chunk
+	}
+	if text != "Hello" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if !last.Done || last.Usage == nil || last.Usage.Total != 5 {
+		t.Fatalf("expected final chunk with usage, got %+v", last)
+	}
+}
+
+func TestContinuedTurnIsRecorded(t *testing.T) {
+	var requests int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		content, reason := "Hello, ", "length"
+		if requests > 1 {
+			content, reason = "world!", "stop"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]any{"content": content}, "finish_reason": reason}},
+		})
+	})
+
+	threads := openai.NewInMemoryThreadManager()
+	client.WithThreadManager(threads)
+	state, err := threads.Start(context.Background(), "writer", aiwf.ThreadBinding{})
+	if err != nil {
+		t.Fatalf("start thread: %v", err)
+	}
+
+	agent := &aiwf.AgentBase{
+		Config: aiwf.AgentConfig{Name: "writer", Model: "grok-4", OutputTypeName: "string", MaxTokens: 2},
+		Client: client,
+	}
+	if _, _, err := agent.CallModel(context.Background(), "greet", state); err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+
+	history, err := threads.History(context.Background(), state.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != `"greet"` || history[1].Content != "Hello, world!" {
+		t.Fatalf("expected assembled turn in thread, got %+v", history)
+	}
+}
+
+type failingHistory struct {
+	*openai.InMemoryThreadManager
+}
+
+func (failingHistory) Append(ctx context.Context, threadID string, messages ...aiwf.Message) error {
+	return fmt.Errorf("store is down")
+}
+
+func TestCallJSONSchemaStreamReportsHistoryError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	threads := failingHistory{openai.NewInMemoryThreadManager()}
+	client.WithThreadManager(threads)
+	state, err := threads.Start(context.Background(), "chat", aiwf.ThreadBinding{})
+	if err != nil {
+		t.Fatalf("start thread: %v", err)
+	}
+
+	ch, _, err := client.CallJSONSchemaStream(context.Background(), aiwf.ModelCall{UserPrompt: "hi", ThreadID: state.ID})
+	if err != nil {
+		t.Fatalf("CallJSONSchemaStream: %v", err)
+	}
+
+	var last aiwf.StreamChunk
+	for chunk := range ch {
+		last = chunk
+	}
+	if !last.Done || last.Err == nil || !strings.Contains(last.Err.Error(), "store is down") {
+		t.Fatalf("expected history error in final chunk, got %+v", last)
+	}
+}
@@ -0,0 +1,23 @@
+package grok
+
+import "github.com/andranikuz/aiwf/runtime/go/aiwf"
+
+// Регистрирует провайдера под именем use: grok.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "grok",
+		ImportPath:   "github.com/andranikuz/aiwf/providers/grok",
+		EnvKeys:      []string{"GROK_API_KEY"},
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityStreaming, aiwf.CapabilityThreads},
+		New:          newFromConfig,
+	})
+}
+
+// newFromConfig создаёт клиента Grok из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	apiKey := cfg.APIKey("GROK_API_KEY")
+	if apiKey == "" {
+		return nil, aiwf.ErrProviderNotConfigured
+	}
+	return NewClient(ClientConfig{BaseURL: cfg.BaseURL, APIKey: apiKey, HTTPClient: cfg.HTTPClient(), Timeout: cfg.Timeout})
+}
@@ -0,0 +1,78 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// doneMarker завершает поток в OpenAI-совместимых API.
+const doneMarker = "[DONE]"
+
+// Event — одно событие server-sent events.
+type Event struct {
+	Name string
+	Data string
+}
+
+// Reader читает события из тела ответа.
+type Reader struct {
+	scanner *bufio.Scanner
+	done    bool
+}
+
+// NewReader создаёт читателя событий.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Next возвращает следующее событие. По окончании потока или после [DONE]
+// возвращает io.EOF.
+func (r *Reader) Next() (Event, error) {
+	if r.done {
+		return Event{}, io.EOF
+	}
+
+	var event Event
+	var data []string
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if len(data) == 0 && event.Name == "" {
+				continue
+			}
+			return r.emit(event, data)
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	if len(data) > 0 {
+		return r.emit(event, data)
+	}
+	r.done = true
+	return Event{}, io.EOF
+}
+
+func (r *Reader) emit(event Event, data []string) (Event, error) {
+	event.Data = strings.Join(data, "\n")
+	if event.Data == doneMarker {
+		r.done = true
+		return Event{}, io.EOF
+	}
+	return event, nil
+}
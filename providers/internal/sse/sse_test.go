@@ -0,0 +1,24 @@
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReaderNext(t *testing.T) {
+	stream := ": keep-alive\n\nevent: delta\ndata: {\"a\":1}\n\ndata: line1\ndata: line2\n\ndata: [DONE]\n\ndata: ignored\n\n"
+	r := NewReader(strings.NewReader(stream))
+
+	ev, err := r.Next()
+	if err != nil || ev.Name != "delta" || ev.Data != `{"a":1}` {
+		t.Fatalf("unexpected first event: %+v, %v", ev, err)
+	}
+	ev, err = r.Next()
+	if err != nil || ev.Data != "line1\nline2" {
+		t.Fatalf("unexpected multiline event: %+v, %v", ev, err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("expected EOF after [DONE], got %v", err)
+	}
+}
@@ -2,6 +2,11 @@ package local
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/andranikuz/aiwf/providers/openai"
 	"github.com/andranikuz/aiwf/runtime/go/aiwf"
@@ -11,23 +16,48 @@ import (
 type ClientConfig struct {
 	Endpoint string
 	APIKey   string
+
+	// Mode выбирает API сервера; по умолчанию openai.ModeChatCompletions,
+	// так как /responses реализуют немногие локальные серверы.
+	Mode openai.Mode
+	// ResponseFormat задаёт способ запроса JSON; по умолчанию json_schema с откатом на json_object.
+	ResponseFormat openai.ResponseFormat
+	// DiscoverModels включает проверку модели через GET /models: если запрошенной
+	// модели нет на сервере, вызов возвращает ошибку со списком доступных.
+	DiscoverModels bool
+	// HTTPClient и Timeout передаются OpenAI-клиенту.
+	HTTPClient *http.Client
+	Timeout    time.Duration
 }
 
 // Client оборачивает openai.Client для повторного использования логики JSON Schema.
 type Client struct {
 	upstream *openai.Client
+	discover bool
+
+	mu     sync.Mutex
+	models []string
 }
 
 // NewClient создаёт обёртку над OpenAI-клиентом с пользовательским endpoint.
 func NewClient(cfg ClientConfig) (*Client, error) {
+	mode := cfg.Mode
+	if mode == "" {
+		mode = openai.ModeChatCompletions
+	}
+
 	upstream, err := openai.NewClient(openai.ClientConfig{
-		BaseURL: cfg.Endpoint,
-		APIKey:  fallbackKey(cfg.APIKey),
+		BaseURL:        cfg.Endpoint,
+		APIKey:         fallbackKey(cfg.APIKey),
+		Mode:           mode,
+		ResponseFormat: cfg.ResponseFormat,
+		HTTPClient:     cfg.HTTPClient,
+		Timeout:        cfg.Timeout,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Client{upstream: upstream}, nil
+	return &Client{upstream: upstream, discover: cfg.DiscoverModels}, nil
 }
 
 func fallbackKey(key string) string {
@@ -37,12 +67,65 @@ func fallbackKey(key string) string {
 	return key
 }
 
+// Models возвращает модели, доступные на сервере. Успешный ответ кешируется.
+func (c *Client) Models(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.models != nil {
+		return c.models, nil
+	}
+	models, err := c.upstream.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.models = models
+	return models, nil
+}
+
+// checkModel проверяет, что запрошенная модель есть на сервере.
+func (c *Client) checkModel(ctx context.Context, model string) error {
+	if !c.discover {
+		return nil
+	}
+
+	models, err := c.Models(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		if m == model {
+			return nil
+		}
+	}
+	return fmt.Errorf("local: model %q is not available (server has: %s)", model, strings.Join(models, ", "))
+}
+
 // CallJSONSchema делегирует вызов локальному OpenAI-совместимому серверу.
 func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
+	if err := c.checkModel(ctx, call.Model); err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
 	return c.upstream.CallJSONSchema(ctx, call)
 }
 
+// Call делегирует вызов с причиной остановки генерации.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	if err := c.checkModel(ctx, call.Model); err != nil {
+		return nil, err
+	}
+	return c.upstream.Call(ctx, call)
+}
+
 // CallJSONSchemaStream делегирует потоковый вызов (пока тоже заглушка).
 func (c *Client) CallJSONSchemaStream(ctx context.Context, call aiwf.ModelCall) (<-chan aiwf.StreamChunk, aiwf.Tokens, error) {
 	return c.upstream.CallJSONSchemaStream(ctx, call)
 }
+
+// Embed запрашивает эмбеддинги у локального сервера (POST /embeddings).
+func (c *Client) Embed(ctx context.Context, call aiwf.EmbeddingCall) (*aiwf.EmbeddingResult, error) {
+	if err := c.checkModel(ctx, call.Model); err != nil {
+		return nil, err
+	}
+	return c.upstream.Embed(ctx, call)
+}
@@ -5,33 +5,44 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/andranikuz/aiwf/providers/openai"
 	"github.com/andranikuz/aiwf/runtime/go/aiwf"
 )
 
+func chatResponse(content string) map[string]any {
+	return map[string]any{
+		"choices": []any{
+			map[string]any{
+				"message":       map[string]any{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+			},
+		},
+		"usage": map[string]any{},
+	}
+}
+
+var valueCall = aiwf.ModelCall{
+	Model:          "dummy",
+	OutputTypeName: "Value",
+	TypeMetadata:   map[string]any{"type": "object", "properties": map[string]any{"value": map[string]any{"type": "string"}}},
+	UserPrompt:     "ping",
+}
+
 func TestLocalClientDelegates(t *testing.T) {
 	var recorded struct {
 		path string
 		auth string
+		body map[string]any
 	}
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		recorded.path = r.URL.Path
 		recorded.auth = r.Header.Get("Authorization")
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"output": []any{
-				map[string]any{
-					"content": []any{
-						map[string]any{
-							"type": "output_text",
-							"text": `{"value":"ok"}`,
-						},
-					},
-				},
-			},
-			"usage": map[string]any{},
-		})
+		_ = json.NewDecoder(r.Body).Decode(&recorded.body)
+		_ = json.NewEncoder(w).Encode(chatResponse(`{"value":"ok"}`))
 	}))
 	defer srv.Close()
 
@@ -40,34 +51,33 @@ func TestLocalClientDelegates(t *testing.T) {
 		t.Fatalf("new client: %v", err)
 	}
 
-	raw, _, err := client.CallJSONSchema(context.Background(), aiwf.ModelCall{
-		Model:           "dummy",
-		OutputSchemaRef: "schema",
-		OutputSchema:    json.RawMessage(`{"type":"object"}`),
-		UserPrompt:      "ping",
-	})
+	raw, _, err := client.CallJSONSchema(context.Background(), valueCall)
 	if err != nil {
 		t.Fatalf("CallJSONSchema: %v", err)
 	}
 
-	if recorded.path != "/responses" {
-		t.Fatalf("expected /responses path, got %s", recorded.path)
+	if recorded.path != "/chat/completions" {
+		t.Fatalf("expected /chat/completions path, got %s", recorded.path)
 	}
 
 	if recorded.auth != "Bearer token" {
 		t.Fatalf("expected auth header, got %s", recorded.auth)
 	}
 
+	format, _ := recorded.body["response_format"].(map[string]any)
+	if format["type"] != "json_schema" {
+		t.Fatalf("expected json_schema response_format, got %v", recorded.body["response_format"])
+	}
+
 	if string(raw) != "{\"value\":\"ok\"}" {
 		t.Fatalf("unexpected raw output: %s", string(raw))
 	}
 }
 
-func TestFallbackKey(t *testing.T) {
+func TestLocalClientResponsesMode(t *testing.T) {
+	var path string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("Authorization") != "Bearer local-override" {
-			t.Fatalf("expected fallback auth header, got %s", r.Header.Get("Authorization"))
-		}
+		path = r.URL.Path
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"output": []any{
 				map[string]any{
@@ -84,16 +94,135 @@ func TestFallbackKey(t *testing.T) {
 	}))
 	defer srv.Close()
 
+	client, err := NewClient(ClientConfig{Endpoint: srv.URL, Mode: openai.ModeResponses})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, _, err := client.CallJSONSchema(context.Background(), valueCall); err != nil {
+		t.Fatalf("CallJSONSchema: %v", err)
+	}
+	if path != "/responses" {
+		t.Fatalf("expected /responses path, got %s", path)
+	}
+}
+
+func TestFallbackKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer local-override" {
+			t.Fatalf("expected fallback auth header, got %s", r.Header.Get("Authorization"))
+		}
+		_ = json.NewEncoder(w).Encode(chatResponse(`{"value":"ok"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{Endpoint: srv.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, _, err := client.CallJSONSchema(context.Background(), valueCall); err != nil {
+		t.Fatalf("CallJSONSchema: %v", err)
+	}
+}
+
+func TestJSONObjectFallbackAndFenceStripping(t *testing.T) {
+	var formats []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		format := body["response_format"].(map[string]any)["type"].(string)
+		formats = append(formats, format)
+		if format == "json_schema" {
+			http.Error(w, `{"error":"response_format json_schema is not supported"}`, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(chatResponse("Sure!\n```json\n{\"value\":\"ok\"}\n```"))
+	}))
+	defer srv.Close()
+
 	client, err := NewClient(ClientConfig{Endpoint: srv.URL})
 	if err != nil {
 		t.Fatalf("new client: %v", err)
 	}
 
-	if _, _, err := client.CallJSONSchema(context.Background(), aiwf.ModelCall{
-		OutputSchemaRef: "schema",
-		OutputSchema:    json.RawMessage(`{"type":"object"}`),
-		UserPrompt:      "ping",
-	}); err != nil {
+	for i := 0; i < 2; i++ {
+		raw, _, err := client.CallJSONSchema(context.Background(), valueCall)
+		if err != nil {
+			t.Fatalf("CallJSONSchema: %v", err)
+		}
+		if string(raw) != `{"value":"ok"}` {
+			t.Fatalf("unexpected output: %s", raw)
+		}
+	}
+
+	if len(formats) != 3 || formats[0] != "json_schema" || formats[1] != "json_object" || formats[2] != "json_object" {
+		t.Fatalf("expected a single json_schema attempt followed by json_object, got %v", formats)
+	}
+}
+
+func TestDiscoverModels(t *testing.T) {
+	var model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": []any{map[string]any{"id": "qwen2.5-7b-instruct"}},
+			})
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		model, _ = body["model"].(string)
+		_ = json.NewEncoder(w).Encode(chatResponse(`{"value":"ok"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{Endpoint: srv.URL, DiscoverModels: true})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	// Отсутствующая модель не подменяется другой: вызов до сервера не доходит
+	_, _, err = client.CallJSONSchema(context.Background(), valueCall)
+	if err == nil || !strings.Contains(err.Error(), `model "dummy" is not available`) || !strings.Contains(err.Error(), "qwen2.5-7b-instruct") {
+		t.Fatalf("expected model not available error, got %v", err)
+	}
+	if model != "" {
+		t.Fatalf("request must not be sent, got model %q", model)
+	}
+
+	call := valueCall
+	call.Model = "qwen2.5-7b-instruct"
+	if _, _, err := client.CallJSONSchema(context.Background(), call); err != nil {
+		t.Fatalf("CallJSONSchema: %v", err)
+	}
+	if model != "qwen2.5-7b-instruct" {
+		t.Fatalf("expected requested model, got %q", model)
+	}
+}
+
+func TestRegistryConfig(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "completed",
+			"output": []any{map[string]any{"content": []any{map[string]any{"type": "output_text", "text": `{"value":"ok"}`}}}},
+		})
+	}))
+	defer srv.Close()
+
+	if _, err := aiwf.NewProvider("local", aiwf.ProviderConfig{Getenv: func(string) string { return "" }}); err != aiwf.ErrProviderNotConfigured {
+		t.Fatalf("expected ErrProviderNotConfigured without base URL, got %v", err)
+	}
+
+	client, err := aiwf.NewProvider("local", aiwf.ProviderConfig{BaseURL: srv.URL, Mode: "responses"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if _, _, err := client.CallJSONSchema(context.Background(), valueCall); err != nil {
 		t.Fatalf("CallJSONSchema: %v", err)
 	}
+	if path != "/responses" {
+		t.Fatalf("mode from config must select /responses, got %s", path)
+	}
 }
@@ -0,0 +1,38 @@
+package local
+
+import (
+	"github.com/andranikuz/aiwf/providers/openai"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+// Регистрирует провайдера под именем use: local.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "local",
+		ImportPath:   "github.com/andranikuz/aiwf/providers/local",
+		EnvKeys:      []string{"LOCAL_LLM_BASE_URL"},
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityEmbeddings},
+		New:          newFromConfig,
+		// По умолчанию используется Chat Completions, где поддержаны все параметры
+		IgnoredOptions: func(mode string) []string { return openai.IgnoredOptions(openai.Mode(mode)) },
+	})
+}
+
+// newFromConfig создаёт клиента локального сервера из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	// Адрес сервера обязателен; ключ большинству локальных серверов не нужен
+	endpoint := cfg.BaseURL
+	if endpoint == "" {
+		endpoint = cfg.Env("LOCAL_LLM_BASE_URL")
+	}
+	if endpoint == "" {
+		return nil, aiwf.ErrProviderNotConfigured
+	}
+	return NewClient(ClientConfig{
+		Endpoint:   endpoint,
+		APIKey:     cfg.APIKey("LOCAL_LLM_API_KEY"),
+		Mode:       openai.Mode(cfg.Mode),
+		HTTPClient: cfg.HTTPClient(),
+		Timeout:    cfg.Timeout,
+	})
+}
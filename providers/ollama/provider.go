@@ -0,0 +1,449 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+const (
+	defaultBaseURL = "http://localhost:11434"
+)
+
+// ClientConfig определяет параметры доступа к локальному серверу Ollama.
+type ClientConfig struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Timeout    time.Duration
+
+	// NumCtx задаёт размер контекстного окна (options.num_ctx); 0 — значение модели.
+	NumCtx int
+	// KeepAlive определяет, сколько модель остаётся в памяти после запроса (например, "10m").
+	KeepAlive string
+	// PullMissing скачивает отсутствующую модель через /api/pull вместо ошибки.
+	PullMissing bool
+}
+
+// Client реализует aiwf.ModelClient для нативного API Ollama (/api/chat).
+type Client struct {
+	baseURL     string
+	http        *http.Client
+	numCtx      int
+	keepAlive   string
+	pullMissing bool
+
+	mu        sync.Mutex
+	available map[string]bool
+	checks    map[string]*modelCheck
+}
+
+// NewClient создаёт клиента для Ollama.
+func NewClient(cfg ClientConfig) (*Client, error) {
+	base := cfg.BaseURL
+	if base == "" {
+		base = defaultBaseURL
+	}
+	// OLLAMA_HOST часто задают без схемы: 127.0.0.1:11434
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		// Локальная генерация и загрузка модели в память бывают долгими
+		timeout = 5 * time.Minute
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: timeout}
+	} else if httpClient.Timeout == 0 {
+		httpClient.Timeout = timeout
+	}
+
+	return &Client{
+		baseURL:     strings.TrimRight(base, "/"),
+		http:        httpClient,
+		numCtx:      cfg.NumCtx,
+		keepAlive:   cfg.KeepAlive,
+		pullMissing: cfg.PullMissing,
+		available:   make(map[string]bool),
+		checks:      make(map[string]*modelCheck),
+	}, nil
+}
+
+// CallJSONSchema выполняет запрос к Ollama.
+func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
+	result, err := c.Call(ctx, call)
+	if err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
+	return result.Data, result.Usage, nil
+}
+
+// Call выполняет /api/chat и сообщает причину остановки генерации.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	if err := c.EnsureModel(ctx, call.Model); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newChatRequest(ctx, call, false)
+	if err != nil {
+		return nil, fmt.Errorf("ollama: failed to create request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ollama: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	var parsed ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("ollama: failed to decode response: %w", err)
+	}
+
+	return &aiwf.CallResult{
+		Data:          []byte(parsed.Message.Content),
+		Usage:         parsed.tokens(),
+		StopReason:    mapDoneReason(parsed.DoneReason),
+		RawStopReason: parsed.DoneReason,
+	}, nil
+}
+
+// CallJSONSchemaStream выполняет потоковый /api/chat (NDJSON).
+// Расход токенов передаётся в финальном чанке.
+func (c *Client) CallJSONSchemaStream(ctx context.Context, call aiwf.ModelCall) (<-chan aiwf.StreamChunk, aiwf.Tokens, error) {
+	if err := c.EnsureModel(ctx, call.Model); err != nil {
+		return nil, aiwf.Tokens{}, err
+	}
+
+	req, err := c.newChatRequest(ctx, call, true)
+	if err != nil {
+		return nil, aiwf.Tokens{}, fmt.Errorf("ollama: failed to create request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, aiwf.Tokens{}, fmt.Errorf("ollama: request failed: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, aiwf.Tokens{}, fmt.Errorf("ollama: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	ch := make(chan aiwf.StreamChunk)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var chunk ChatResponse
+			if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+				continue
+			}
+
+			out := aiwf.StreamChunk{Data: []byte(chunk.Message.Content)}
+			if chunk.Done {
+				usage := chunk.tokens()
+				out.Done = true
+				out.Usage = &usage
+			} else if chunk.Message.Content == "" {
+				continue
+			}
+
+			select {
+			case ch <- out:
+			case <-ctx.Done():
+				return
+			}
+			if chunk.Done {
+				return
+			}
+		}
+	}()
+
+	return ch, aiwf.Tokens{}, nil
+}
+
+// Models возвращает имена моделей, скачанных на сервер (/api/tags).
+func (c *Client) Models(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama: server is not reachable at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ollama: list models: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	var parsed TagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("ollama: list models: %w", err)
+	}
+
+	names := make([]string, 0, len(parsed.Models))
+	for _, m := range parsed.Models {
+		names = append(names, m.Name)
+	}
+	return names, nil
+}
+
+// EnsureModel проверяет, что модель скачана на сервер. Если модели нет и
+// включён PullMissing, она скачивается; иначе возвращается понятная ошибка.
+// Успешная проверка кешируется; одновременные проверки одной модели
+// выполняются одним запросом.
+func (c *Client) EnsureModel(ctx context.Context, model string) error {
+	if model == "" {
+		return errors.New("ollama: model is required")
+	}
+
+	c.mu.Lock()
+	if c.available[model] {
+		c.mu.Unlock()
+		return nil
+	}
+	if check, ok := c.checks[model]; ok {
+		c.mu.Unlock()
+		select {
+		case <-check.done:
+			return check.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	check := &modelCheck{done: make(chan struct{})}
+	c.checks[model] = check
+	c.mu.Unlock()
+
+	check.err = c.checkModel(ctx, model)
+
+	c.mu.Lock()
+	delete(c.checks, model)
+	if check.err == nil {
+		c.available[model] = true
+	}
+	c.mu.Unlock()
+	close(check.done)
+
+	return check.err
+}
+
+// modelCheck — выполняющаяся проверка модели, которую ждут остальные вызовы.
+type modelCheck struct {
+	done chan struct{}
+	err  error
+}
+
+// checkModel запрашивает список моделей и при необходимости скачивает модель.
+func (c *Client) checkModel(ctx context.Context, model string) error {
+	models, err := c.Models(ctx)
+	if err != nil {
+		return err
+	}
+	if hasModel(models, model) {
+		return nil
+	}
+
+	if !c.pullMissing {
+		return fmt.Errorf("ollama: model %q is not available locally; run `ollama pull %s` (available: %s)",
+			model, model, strings.Join(models, ", "))
+	}
+	return c.pull(ctx, model)
+}
+
+// pull скачивает модель через /api/pull.
+func (c *Client) pull(ctx context.Context, model string) error {
+	body, err := json.Marshal(map[string]any{"model": model, "stream": false})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama: pull %s: %w", model, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		buf, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama: pull %s: unexpected status %d: %s", model, resp.StatusCode, string(buf))
+	}
+
+	var status struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err == nil && status.Error != "" {
+		return fmt.Errorf("ollama: pull %s: %s", model, status.Error)
+	}
+	return nil
+}
+
+// hasModel сравнивает имена с учётом тега по умолчанию :latest.
+func hasModel(models []string, model string) bool {
+	for _, name := range models {
+		if name == model || name == model+":latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// mapDoneReason переводит done_reason Ollama в aiwf.StopReason.
+func mapDoneReason(reason string) aiwf.StopReason {
+	switch reason {
+	case "stop":
+		return aiwf.StopReasonEnd
+	case "length":
+		return aiwf.StopReasonMaxTokens
+	default:
+		return aiwf.StopReasonUnknown
+	}
+}
+
+// newChatRequest создаёт HTTP запрос для /api/chat.
+func (c *Client) newChatRequest(ctx context.Context, call aiwf.ModelCall, stream bool) (*http.Request, error) {
+	var messages []Message
+	if call.SystemPrompt != "" {
+		messages = append(messages, Message{Role: "system", Content: call.SystemPrompt})
+	}
+	for _, msg := range call.History {
+		messages = append(messages, Message{Role: msg.Role, Content: msg.Content})
+	}
+
+	// Промпт с найденными знаниями и типизированные входные данные идут одним сообщением
+	userMessage, err := aiwf.UserContent(call)
+	if err != nil {
+		return nil, fmt.Errorf("ollama: %w", err)
+	}
+	messages = append(messages, Message{Role: "user", Content: userMessage})
+
+	payload := ChatRequest{
+		Model:     call.Model,
+		Messages:  messages,
+		Stream:    stream,
+		KeepAlive: c.keepAlive,
+		Options: &Options{
+			NumCtx:           c.numCtx,
+			NumPredict:       call.MaxTokens,
+			Temperature:      call.Temperature,
+			TopP:             call.TopP,
+			Seed:             call.Seed,
+			Stop:             call.Stop,
+			PresencePenalty:  call.PresencePenalty,
+			FrequencyPenalty: call.FrequencyPenalty,
+		},
+	}
+
+	if call.OutputTypeName != "" && call.OutputTypeName != "string" {
+		if call.TypeMetadata != nil {
+			schema, err := core.SchemaFromMetadata(call.TypeMetadata, core.DialectDraft2020)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build output schema: %w", err)
+			}
+			payload.Format = schema
+		} else {
+			payload.Format = json.RawMessage(`"json"`)
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// Message представляет сообщение в диалоге
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest - структура запроса к /api/chat
+type ChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []Message       `json:"messages"`
+	Stream    bool            `json:"stream"`
+	Format    json.RawMessage `json:"format,omitempty"`
+	Options   *Options        `json:"options,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+}
+
+// Options - параметры генерации
+type Options struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	NumPredict       int      `json:"num_predict,omitempty"`
+	NumCtx           int      `json:"num_ctx,omitempty"`
+}
+
+// ChatResponse - ответ /api/chat (или один чанк потокового ответа)
+type ChatResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+}
+
+func (r ChatResponse) tokens() aiwf.Tokens {
+	return aiwf.Tokens{
+		Prompt:     r.PromptEvalCount,
+		Completion: r.EvalCount,
+		Total:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+// TagsResponse - ответ /api/tags
+type TagsResponse struct {
+	Models []struct {
+		Name  string `json:"name"`
+		Model string `json:"model"`
+	} `json:"models"`
+}
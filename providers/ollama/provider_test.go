@@ -0,0 +1,274 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func tagsHandler(models ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		var list []any
+		for _, m := range models {
+			list = append(list, map[string]any{"name": m, "model": m})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": list})
+	}
+}
+
+func TestCallSendsFormatAndOptions(t *testing.T) {
+	tags := tagsHandler("llama3.1:latest")
+	var request ChatRequest
+	var tagRequests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			tagRequests++
+			tags(w)
+		case "/api/chat":
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"message":           map[string]any{"role": "assistant", "content": `{"answer":"42"}`},
+				"done":              true,
+				"done_reason":       "stop",
+				"prompt_eval_count": 12,
+				"eval_count":        4,
+			})
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, NumCtx: 8192, KeepAlive: "10m"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	call := aiwf.ModelCall{
+		Model:          "llama3.1",
+		SystemPrompt:   "Answer briefly",
+		Payload:        map[string]string{"question": "?"},
+		MaxTokens:      256,
+		OutputTypeName: "Answer",
+		TypeMetadata: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"answer": map[string]any{"type": "string"}},
+			"required":   []any{"answer"},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		result, err := client.Call(context.Background(), call)
+		if err != nil {
+			t.Fatalf("Call: %v", err)
+		}
+		if string(result.Data) != `{"answer":"42"}` || result.Usage.Total != 16 || result.StopReason != aiwf.StopReasonEnd {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	}
+
+	if tagRequests != 1 {
+		t.Fatalf("expected model availability to be cached, got %d /api/tags calls", tagRequests)
+	}
+	if request.Options == nil || request.Options.NumCtx != 8192 || request.Options.NumPredict != 256 {
+		t.Fatalf("unexpected options: %+v", request.Options)
+	}
+	if request.KeepAlive != "10m" || request.Stream {
+		t.Fatalf("unexpected request: %+v", request)
+	}
+
+	var format map[string]any
+	if err := json.Unmarshal(request.Format, &format); err != nil || format["type"] != "object" {
+		t.Fatalf("expected JSON schema format, got %s", request.Format)
+	}
+}
+
+func TestCallSendsPromptWithPayload(t *testing.T) {
+	var request ChatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			tagsHandler("llama3.1:latest")(w)
+		case "/api/chat":
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"message": map[string]any{"content": "ok"}, "done": true, "done_reason": "stop",
+			})
+		}
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: srv.URL})
+	_, err := client.Call(context.Background(), aiwf.ModelCall{
+		Model:      "llama3.1",
+		UserPrompt: "Справка: отпуск 28 дней",
+		Payload:    map[string]string{"question": "?"},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	content := request.Messages[len(request.Messages)-1].Content
+	if !strings.Contains(content, "отпуск 28 дней") || !strings.Contains(content, `{"question":"?"}`) {
+		t.Fatalf("expected prompt and payload in user message, got %q", content)
+	}
+}
+
+func TestMissingModel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		tagsHandler("qwen2.5:7b")(w)
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: srv.URL})
+	_, err := client.Call(context.Background(), aiwf.ModelCall{Model: "llama3.1", UserPrompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "ollama pull llama3.1") || !strings.Contains(err.Error(), "qwen2.5:7b") {
+		t.Fatalf("expected clear missing model error, got %v", err)
+	}
+}
+
+func TestPullMissingModel(t *testing.T) {
+	var pulled string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			tagsHandler()(w)
+		case "/api/pull":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			pulled, _ = body["model"].(string)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "success"})
+		case "/api/chat":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"message": map[string]any{"content": "hi"}, "done": true, "done_reason": "stop",
+			})
+		}
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: srv.URL, PullMissing: true})
+	if _, err := client.Call(context.Background(), aiwf.ModelCall{Model: "llama3.1", UserPrompt: "hi"}); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if pulled != "llama3.1" {
+		t.Fatalf("expected model to be pulled, got %q", pulled)
+	}
+}
+
+func TestCallJSONSchemaStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			tagsHandler("llama3.1:latest")(w)
+			return
+		}
+		fmt.Fprintln(w, `{"message":{"content":"Hel"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"content":"lo"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"content":""},"done":true,"done_reason":"stop","prompt_eval_count":3,"eval_count":2}`)
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: srv.URL})
+	ch, _, err := client.CallJSONSchemaStream(context.Background(), aiwf.ModelCall{Model: "llama3.1", UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("CallJSONSchemaStream: %v", err)
+	}
+
+	var text string
+	var last aiwf.StreamChunk
+	for chunk := range ch {
+		text += string(chunk.Data)
+		last = chunk
+	}
+	if text != "Hello" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if !last.Done || last.Usage == nil || last.Usage.Total != 5 {
+		t.Fatalf("expected final chunk with usage, got %+v", last)
+	}
+}
+
+func TestEnsureModelPullsOnce(t *testing.T) {
+	var pulls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			tagsHandler("qwen2.5:7b")(w)
+		case "/api/pull":
+			pulls.Add(1)
+			<-release
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "success"})
+		}
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: srv.URL, PullMissing: true})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.EnsureModel(context.Background(), "llama3.1")
+		}()
+	}
+
+	// Пока модель скачивается, проверка другой модели не ждёт блокировки
+	done := make(chan error, 1)
+	go func() { done <- client.EnsureModel(context.Background(), "qwen2.5:7b") }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("EnsureModel: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("check of another model is blocked by the pull")
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("EnsureModel: %v", err)
+		}
+	}
+	if n := pulls.Load(); n != 1 {
+		t.Fatalf("expected a single pull, got %d", n)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	mc, err := newFromConfig(aiwf.ProviderConfig{
+		NumCtx:      8192,
+		PullMissing: true,
+		Getenv:      func(key string) string { return map[string]string{"OLLAMA_KEEP_ALIVE": "5m"}[key] },
+	})
+	if err != nil {
+		t.Fatalf("newFromConfig: %v", err)
+	}
+	client := mc.(*Client)
+	if client.numCtx != 8192 || !client.pullMissing || client.keepAlive != "5m" {
+		t.Fatalf("unexpected client settings: numCtx=%d pullMissing=%v keepAlive=%q", client.numCtx, client.pullMissing, client.keepAlive)
+	}
+
+	mc, _ = newFromConfig(aiwf.ProviderConfig{KeepAlive: "1h", Getenv: func(string) string { return "5m" }})
+	if keepAlive := mc.(*Client).keepAlive; keepAlive != "1h" {
+		t.Fatalf("keep_alive from spec must win over env, got %q", keepAlive)
+	}
+}
@@ -0,0 +1,35 @@
+package ollama
+
+import "github.com/andranikuz/aiwf/runtime/go/aiwf"
+
+// Регистрирует провайдера под именем use: ollama.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:           "ollama",
+		ImportPath:     "github.com/andranikuz/aiwf/providers/ollama",
+		Capabilities:   []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityStreaming},
+		New:            newFromConfig,
+		IgnoredOptions: func(string) []string { return []string{"reasoning_effort"} },
+	})
+}
+
+// newFromConfig создаёт клиента Ollama из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	// Ollama работает локально и не требует ключа; адрес по умолчанию — localhost:11434
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = cfg.Env("OLLAMA_HOST")
+	}
+	keepAlive := cfg.KeepAlive
+	if keepAlive == "" {
+		keepAlive = cfg.Env("OLLAMA_KEEP_ALIVE")
+	}
+	return NewClient(ClientConfig{
+		BaseURL:     baseURL,
+		HTTPClient:  cfg.HTTPClient(),
+		Timeout:     cfg.Timeout,
+		NumCtx:      cfg.NumCtx,
+		KeepAlive:   keepAlive,
+		PullMissing: cfg.PullMissing,
+	})
+}
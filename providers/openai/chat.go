@@ -0,0 +1,315 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andranikuz/aiwf/generator/core"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+const (
+	chatCompletionsPath = "/chat/completions"
+	modelsPath          = "/models"
+)
+
+// callChat выполняет запрос к Chat Completions API.
+func (c *Client) callChat(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	format := c.responseFormat
+	if c.schemaRejected.Load() {
+		format = ResponseFormatJSONObject
+	}
+
+	status, header, body, err := c.doChat(ctx, call, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сервер не поддерживает json_schema — повторяем с json_object и запоминаем это
+	if format == ResponseFormatJSONSchema && !isTextOutput(call) && (status == http.StatusBadRequest || status == http.StatusUnprocessableEntity) {
+		c.schemaRejected.Store(true)
+		status, header, body, err = c.doChat(ctx, call, ResponseFormatJSONObject)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if status >= 300 {
+		return nil, fmt.Errorf("openai: unexpected status %d: %s", status, string(body))
+	}
+
+	var parsed chatCompletion
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("openai: failed to decode response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, errors.New("openai: empty response choices")
+	}
+
+	choice := parsed.Choices[0]
+	stopReason := mapFinishReason(choice.FinishReason)
+
+	data := choice.Message.Content
+	if !isTextOutput(call) && stopReason != aiwf.StopReasonMaxTokens {
+		extracted, err := extractJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		data = extracted
+		if wrappedOutput(call) {
+			value, err := core.UnwrapValue([]byte(data))
+			if err != nil {
+				return nil, fmt.Errorf("openai: %w", err)
+			}
+			data = string(value)
+		}
+	}
+
+	return &aiwf.CallResult{
+		Data:          []byte(data),
+		Usage:         parsed.Usage.tokens(),
+		StopReason:    stopReason,
+		RawStopReason: choice.FinishReason,
+		RequestID:     requestID(header, parsed.ID),
+		Model:         parsed.Model,
+	}, nil
+}
+
+// doChat отправляет запрос и возвращает статус, заголовки и тело ответа.
+func (c *Client) doChat(ctx context.Context, call aiwf.ModelCall, format ResponseFormat) (int, http.Header, []byte, error) {
+	payload, err := c.buildChatRequest(call, format)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(call.Model, chatCompletionsPath), bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("openai: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("openai: failed to read response: %w", err)
+	}
+	return resp.StatusCode, resp.Header, buf, nil
+}
+
+func (c *Client) buildChatRequest(call aiwf.ModelCall, format ResponseFormat) (*chatRequest, error) {
+	systemPrompt := call.SystemPrompt
+
+	var responseFormat *chatResponseFormat
+	if !isTextOutput(call) {
+		section, err := c.buildJSONSchemaFormat(call)
+		if err != nil {
+			return nil, err
+		}
+
+		if format == ResponseFormatJSONObject {
+			// json_object не принимает схему, поэтому описываем её в системном промпте
+			responseFormat = &chatResponseFormat{Type: "json_object"}
+			instruction := "Respond only with a JSON value that matches this JSON Schema:\n" + string(section.Format.Schema)
+			systemPrompt = strings.TrimSpace(systemPrompt + "\n\n" + instruction)
+		} else {
+			responseFormat = &chatResponseFormat{
+				Type: "json_schema",
+				JSONSchema: &chatJSONSchema{
+					Name:   section.Format.Name,
+					Schema: section.Format.Schema,
+					Strict: true,
+				},
+			}
+		}
+	}
+
+	var messages []chatMessage
+	if systemPrompt != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: systemPrompt})
+	}
+	for _, msg := range call.History {
+		messages = append(messages, chatMessage{Role: msg.Role, Content: msg.Content})
+	}
+
+	userTexts, err := userMessages(call)
+	if err != nil {
+		return nil, err
+	}
+	for _, text := range userTexts {
+		messages = append(messages, chatMessage{Role: "user", Content: text})
+	}
+
+	if len(messages) == 0 {
+		return nil, errors.New("openai: empty input")
+	}
+
+	return &chatRequest{
+		Model:            call.Model,
+		Messages:         messages,
+		MaxTokens:        call.MaxTokens,
+		Temperature:      call.Temperature,
+		TopP:             call.TopP,
+		Seed:             call.Seed,
+		Stop:             call.Stop,
+		PresencePenalty:  call.PresencePenalty,
+		FrequencyPenalty: call.FrequencyPenalty,
+		ReasoningEffort:  call.ReasoningEffort,
+		ResponseFormat:   responseFormat,
+		PromptCacheKey:   promptCacheKey(call),
+	}, nil
+}
+
+// ListModels возвращает идентификаторы моделей, доступных на сервере (GET /models).
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint("", modelsPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai: list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai: list models: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	var parsed struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("openai: list models: %w", err)
+	}
+
+	models := make([]string, 0, len(parsed.Data))
+	for _, m := range parsed.Data {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
+
+// isTextOutput сообщает, ожидается ли от модели обычный текст.
+func isTextOutput(call aiwf.ModelCall) bool {
+	return call.OutputTypeName == "" || call.OutputTypeName == "string"
+}
+
+// mapFinishReason переводит finish_reason Chat Completions API в aiwf.StopReason.
+func mapFinishReason(reason string) aiwf.StopReason {
+	switch reason {
+	case "stop":
+		return aiwf.StopReasonEnd
+	case "length":
+		return aiwf.StopReasonMaxTokens
+	case "content_filter":
+		return aiwf.StopReasonContentFilter
+	case "tool_calls", "function_call":
+		return aiwf.StopReasonToolUse
+	default:
+		return aiwf.StopReasonUnknown
+	}
+}
+
+// extractJSON достаёт JSON из ответа сервера, который проигнорировал схему:
+// снимает markdown-ограждения и отбрасывает текст вокруг JSON.
+func extractJSON(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return text, nil
+	}
+
+	if start := strings.Index(text, "```"); start >= 0 {
+		rest := text[start+3:]
+		if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+			rest = rest[nl+1:]
+		}
+		if end := strings.Index(rest, "```"); end >= 0 {
+			if fenced := strings.TrimSpace(rest[:end]); json.Valid([]byte(fenced)) {
+				return fenced, nil
+			}
+		}
+	}
+
+	start := strings.IndexAny(text, "{[")
+	end := strings.LastIndexAny(text, "}]")
+	if start >= 0 && end > start {
+		if candidate := text[start : end+1]; json.Valid([]byte(candidate)) {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("openai: response is not valid JSON: %q", truncateForError(text))
+}
+
+func truncateForError(text string) string {
+	const limit = 200
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "..."
+}
+
+type chatRequest struct {
+	Model            string              `json:"model"`
+	Messages         []chatMessage       `json:"messages"`
+	MaxTokens        int                 `json:"max_tokens,omitempty"`
+	Temperature      *float64            `json:"temperature,omitempty"`
+	TopP             *float64            `json:"top_p,omitempty"`
+	Seed             *int                `json:"seed,omitempty"`
+	Stop             []string            `json:"stop,omitempty"`
+	PresencePenalty  *float64            `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64            `json:"frequency_penalty,omitempty"`
+	ReasoningEffort  string              `json:"reasoning_effort,omitempty"`
+	ResponseFormat   *chatResponseFormat `json:"response_format,omitempty"`
+	PromptCacheKey   string              `json:"prompt_cache_key,omitempty"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *chatJSONSchema `json:"json_schema,omitempty"`
+}
+
+type chatJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   usagePayload `json:"usage"`
+}
+
+type chatChoice struct {
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
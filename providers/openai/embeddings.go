@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+const embeddingsPath = "/embeddings"
+
+// Embed запрашивает эмбеддинги через POST /embeddings.
+func (c *Client) Embed(ctx context.Context, call aiwf.EmbeddingCall) (*aiwf.EmbeddingResult, error) {
+	if len(call.Input) == 0 {
+		return nil, errors.New("openai: empty embedding input")
+	}
+
+	body, err := json.Marshal(embeddingRequest{
+		Model:          call.Model,
+		Input:          call.Input,
+		Dimensions:     call.Dimensions,
+		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(call.Model, embeddingsPath), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai: embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("openai: failed to read embeddings response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("openai: embeddings: unexpected status %d: %s", resp.StatusCode, string(buf))
+	}
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(buf, &parsed); err != nil {
+		return nil, fmt.Errorf("openai: decode embeddings response: %w", err)
+	}
+
+	// Векторы раскладываются по index: порядок data не гарантирован
+	vectors := make([][]float32, len(call.Input))
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("openai: embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("openai: no embedding for input %d", i)
+		}
+	}
+
+	usage := parsed.Usage.tokens()
+	if usage.Total == 0 {
+		usage.Total = usage.Prompt
+	}
+	return &aiwf.EmbeddingResult{
+		Vectors:   vectors,
+		Usage:     usage,
+		RequestID: requestID(resp.Header, ""),
+		Model:     parsed.Model,
+	}, nil
+}
+
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Model string       `json:"model"`
+	Usage usagePayload `json:"usage"`
+}
@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+func TestEmbed(t *testing.T) {
+	var body embeddingRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != embeddingsPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("x-request-id", "req_emb")
+		// data намеренно в обратном порядке: векторы раскладываются по index
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"model": "text-embedding-3-small",
+			"data": []any{
+				map[string]any{"index": 1, "embedding": []float32{0.3, 0.4}},
+				map[string]any{"index": 0, "embedding": []float32{0.1, 0.2}},
+			},
+			"usage": map[string]any{"prompt_tokens": 6, "total_tokens": 6},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	result, err := client.Embed(context.Background(), aiwf.EmbeddingCall{
+		Model:      "text-embedding-3-small",
+		Input:      []string{"first", "second"},
+		Dimensions: 2,
+	})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+
+	if body.Dimensions != 2 || len(body.Input) != 2 || body.EncodingFormat != "float" {
+		t.Fatalf("unexpected request: %+v", body)
+	}
+	if len(result.Vectors) != 2 || result.Vectors[0][0] != 0.1 || result.Vectors[1][0] != 0.3 {
+		t.Fatalf("unexpected vectors: %v", result.Vectors)
+	}
+	if result.Usage.Prompt != 6 || result.Usage.Total != 6 || result.RequestID != "req_emb" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
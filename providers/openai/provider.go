@@ -3,6 +3,8 @@ package openai
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,9 +13,11 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode"
 
+	"github.com/andranikuz/aiwf/generator/core"
 	"github.com/andranikuz/aiwf/runtime/go/aiwf"
 )
 
@@ -22,21 +26,64 @@ const (
 	responsesPath  = "/responses"
 )
 
+// Mode определяет, через какой API клиент обращается к модели.
+type Mode string
+
+const (
+	// ModeResponses — OpenAI Responses API (/responses), режим по умолчанию.
+	ModeResponses Mode = "responses"
+	// ModeChatCompletions — Chat Completions API (/chat/completions), который
+	// реализуют большинство OpenAI-совместимых серверов.
+	ModeChatCompletions Mode = "chat_completions"
+)
+
+// ResponseFormat определяет способ запроса структурированного ответа в режиме Chat Completions.
+type ResponseFormat string
+
+const (
+	// ResponseFormatJSONSchema передаёт схему в response_format; при отказе сервера
+	// клиент переключается на json_object.
+	ResponseFormatJSONSchema ResponseFormat = "json_schema"
+	// ResponseFormatJSONObject запрашивает произвольный JSON, схема передаётся в системном промпте.
+	ResponseFormatJSONObject ResponseFormat = "json_object"
+)
+
 // ClientConfig определяет параметры доступа к OpenAI.
 type ClientConfig struct {
 	BaseURL    string
 	APIKey     string
 	HTTPClient *http.Client
 	Timeout    time.Duration
+
+	// Mode выбирает API; по умолчанию ModeResponses.
+	Mode Mode
+	// ResponseFormat используется в режиме ModeChatCompletions; по умолчанию ResponseFormatJSONSchema.
+	ResponseFormat ResponseFormat
+
+	// AuthHeader задаёт заголовок с ключом. По умолчанию "Authorization" со схемой Bearer;
+	// для других заголовков (например, "api-key" в Azure) ключ передаётся как есть.
+	AuthHeader string
+	// Headers добавляются к каждому запросу.
+	Headers map[string]string
+	// URLBuilder строит адрес запроса по модели и пути API (например, "/chat/completions").
+	// По умолчанию BaseURL + path.
+	URLBuilder func(model, path string) string
 }
 
-// Client реализует aiwf.ModelClient для OpenAI Responses API.
+// Client реализует aiwf.ModelClient для OpenAI Responses API и Chat Completions API.
 type Client struct {
 	baseURL        string
 	apiKey         string
 	http           *http.Client
 	converter      *SchemaConverter
 	threadManager  aiwf.ThreadManager
+	mode           Mode
+	responseFormat ResponseFormat
+	authHeader     string
+	headers        map[string]string
+	urlBuilder     func(model, path string) string
+	// schemaRejected выставляется, если сервер отклонил response_format json_schema.
+	schemaRejected atomic.Bool
 }
 
 // NewClient создаёт клиента с базовыми значениями.
@@ -62,11 +109,29 @@ func NewClient(cfg ClientConfig) (*Client, error) {
 		httpClient.Timeout = timeout
 	}
 
+	mode := cfg.Mode
+	if mode == "" {
+		mode = ModeResponses
+	}
+	if mode != ModeResponses && mode != ModeChatCompletions {
+		return nil, fmt.Errorf("openai: unknown mode %q", mode)
+	}
+
+	format := cfg.ResponseFormat
+	if format == "" {
+		format = ResponseFormatJSONSchema
+	}
+
 	return &Client{
-		baseURL:   base,
-		apiKey:    cfg.APIKey,
-		http:      httpClient,
-		converter: NewSchemaConverter(),
+		baseURL:        strings.TrimRight(base, "/"),
+		apiKey:         cfg.APIKey,
+		http:           httpClient,
+		converter:      NewSchemaConverter(),
+		mode:           mode,
+		responseFormat: format,
+		authHeader:     cfg.AuthHeader,
+		headers:        cfg.Headers,
+		urlBuilder:     cfg.URLBuilder,
 	}, nil
 }
 
@@ -78,51 +143,71 @@ func (c *Client) WithThreadManager(tm aiwf.ThreadManager) *Client {
 
 // CallJSONSchema выполняет синхронный запрос к Responses API.
 func (c *Client) CallJSONSchema(ctx context.Context, call aiwf.ModelCall) ([]byte, aiwf.Tokens, error) {
-	req, err := c.newRequest(ctx, call)
+	result, err := c.Call(ctx, call)
 	if err != nil {
 		return nil, aiwf.Tokens{}, err
 	}
+	return result.Data, result.Usage, nil
+}
+
+// Call выполняет запрос к модели и сообщает причину остановки генерации.
+func (c *Client) Call(ctx context.Context, call aiwf.ModelCall) (*aiwf.CallResult, error) {
+	if c.mode == ModeChatCompletions {
+		return c.callChat(ctx, call)
+	}
+
+	req, err := c.newRequest(ctx, call)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, aiwf.Tokens{}, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
 		buf, _ := io.ReadAll(resp.Body)
 		log.Printf("openai: status %d body=%s", resp.StatusCode, string(buf))
-		return nil, aiwf.Tokens{}, fmt.Errorf("openai: unexpected status %d", resp.StatusCode)
+		return nil, fmt.Errorf("openai: unexpected status %d", resp.StatusCode)
 	}
 
 	var parsed responsePayload
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, aiwf.Tokens{}, err
+		return nil, err
 	}
 
-	structuredText, err := extractStructuredText(parsed.Output)
+	stopReason, rawReason, err := parsed.stopReason()
 	if err != nil {
-		return nil, aiwf.Tokens{}, err
+		return nil, err
 	}
-	log.Printf("openai: output json=%s", structuredText)
 
-	// Return raw JSON bytes
-	raw := []byte(structuredText)
+	structuredText, err := extractStructuredText(parsed.Output, isTextOutput(call))
+	if err != nil {
+		// Обрезанный ответ может не содержать текста (например, все токены ушли на reasoning)
+		if stopReason != aiwf.StopReasonMaxTokens {
+			return nil, err
+		}
+		structuredText = ""
+	}
+	log.Printf("openai: output json=%s", structuredText)
 
-	usage := aiwf.Tokens{
-		Prompt:     parsed.Usage.PromptTokens,
-		Completion: parsed.Usage.CompletionTokens,
-		Total:      parsed.Usage.TotalTokens,
-	}
-	if parsed.Usage.InputTokens != 0 || parsed.Usage.OutputTokens != 0 {
-		usage.Prompt = parsed.Usage.InputTokens
-		usage.Completion = parsed.Usage.OutputTokens
-		if usage.Total == 0 {
-			usage.Total = usage.Prompt + usage.Completion
+	data := []byte(structuredText)
+	if wrappedOutput(call) && stopReason != aiwf.StopReasonMaxTokens {
+		if data, err = core.UnwrapValue(data); err != nil {
+			return nil, fmt.Errorf("openai: %w", err)
 		}
 	}
 
-	return raw, usage, nil
+	return &aiwf.CallResult{
+		Data:          data,
+		Usage:         parsed.Usage.tokens(),
+		StopReason:    stopReason,
+		RawStopReason: rawReason,
+		RequestID:     requestID(resp.Header, parsed.ID),
+		Model:         parsed.Model,
+	}, nil
 }
 
 // CallJSONSchemaStream возвращает заглушку каналов для будущей поддержки стриминга.
@@ -148,7 +233,12 @@ func (c *Client) newRequest(ctx context.Context, call aiwf.ModelCall) (*http.Req
 		Input:           inputMessages,
 		MaxOutputTokens: call.MaxTokens,
 		Temperature:     call.Temperature,
+		TopP:            call.TopP,
 		Text:            format,
+		PromptCacheKey:  promptCacheKey(call),
+	}
+	if call.ReasoningEffort != "" {
+		payload.Reasoning = &reasoningConfig{Effort: call.ReasoningEffort}
 	}
 	if meta := buildMetadata(call); len(meta) > 0 {
 		payload.Metadata = meta
@@ -160,25 +250,63 @@ func (c *Client) newRequest(ctx context.Context, call aiwf.ModelCall) (*http.Req
 	}
 	log.Printf("openai: req=%s", string(body))
 
-	url := c.baseURL + responsesPath
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(call.Model, responsesPath), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.setHeaders(req)
 	req.Header.Set("Content-Type", "application/json")
 
 	return req, nil
 }
 
+// endpoint возвращает адрес запроса к API.
+func (c *Client) endpoint(model, path string) string {
+	if c.urlBuilder != nil {
+		return c.urlBuilder(model, path)
+	}
+	return c.baseURL + path
+}
+
+// setHeaders выставляет заголовок авторизации и дополнительные заголовки.
+func (c *Client) setHeaders(req *http.Request) {
+	if c.authHeader == "" || strings.EqualFold(c.authHeader, "Authorization") {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	} else {
+		req.Header.Set(c.authHeader, c.apiKey)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// responsesIgnoredOptions — параметры генерации, которых нет в Responses API.
+var responsesIgnoredOptions = []string{"seed", "stop", "presence_penalty", "frequency_penalty"}
+
+// IgnoredOptions возвращает параметры генерации, которые клиент не передаёт в режиме mode.
+func IgnoredOptions(mode Mode) []string {
+	if mode == ModeResponses {
+		return responsesIgnoredOptions
+	}
+	return nil
+}
 
 type requestPayload struct {
 	Model           string         `json:"model"`
 	Input           any            `json:"input"`
-	MaxOutputTokens int            `json:"max_output_tokens,omitempty"`
-	Temperature     float64        `json:"temperature,omitempty"`
-	Text            textSection    `json:"text"`
-	Metadata        map[string]any `json:"metadata,omitempty"`
+	MaxOutputTokens int              `json:"max_output_tokens,omitempty"`
+	Temperature     *float64         `json:"temperature,omitempty"`
+	TopP            *float64         `json:"top_p,omitempty"`
+	Reasoning       *reasoningConfig `json:"reasoning,omitempty"`
+	Text            textSection      `json:"text"`
+	Metadata        map[string]any   `json:"metadata,omitempty"`
+	PromptCacheKey  string           `json:"prompt_cache_key,omitempty"`
+}
+
+// reasoningConfig задаёт усилие рассуждения для reasoning-моделей.
+// Responses API не принимает seed, stop и штрафы, поэтому они не передаются.
+type reasoningConfig struct {
+	Effort string `json:"effort"`
 }
 
 type textSection struct {
@@ -203,8 +331,50 @@ type contentBlock struct {
 }
 
 type responsePayload struct {
-	Output []responseMessage `json:"output"`
-	Usage  usagePayload      `json:"usage"`
+	ID                string             `json:"id"`
+	Model             string             `json:"model"`
+	Status            string             `json:"status"`
+	IncompleteDetails *incompleteDetails `json:"incomplete_details"`
+	Error             *responseError     `json:"error"`
+	Output            []responseMessage  `json:"output"`
+	Usage             usagePayload       `json:"usage"`
+}
+
+type incompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
+type responseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// stopReason переводит status/incomplete_details Responses API в aiwf.StopReason.
+// Статусы failed и cancelled возвращаются как ошибка.
+func (p responsePayload) stopReason() (aiwf.StopReason, string, error) {
+	switch p.Status {
+	case "completed":
+		return aiwf.StopReasonEnd, p.Status, nil
+	case "failed", "cancelled":
+		if p.Error != nil && p.Error.Message != "" {
+			return "", p.Status, fmt.Errorf("openai: response %s: %s", p.Status, p.Error.Message)
+		}
+		return "", p.Status, fmt.Errorf("openai: response %s", p.Status)
+	case "incomplete":
+	default:
+		return aiwf.StopReasonUnknown, p.Status, nil
+	}
+	if p.IncompleteDetails == nil {
+		return aiwf.StopReasonUnknown, p.Status, nil
+	}
+	switch p.IncompleteDetails.Reason {
+	case "max_output_tokens":
+		return aiwf.StopReasonMaxTokens, p.IncompleteDetails.Reason, nil
+	case "content_filter":
+		return aiwf.StopReasonContentFilter, p.IncompleteDetails.Reason, nil
+	default:
+		return aiwf.StopReasonUnknown, p.IncompleteDetails.Reason, nil
+	}
 }
 
 type responseMessage struct {
@@ -216,14 +386,100 @@ type responseContent struct {
 	Text string `json:"text"`
 }
 
+// usagePayload покрывает оба формата usage: Chat Completions (prompt/completion)
+// и Responses API (input/output).
 type usagePayload struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
 	InputTokens      int `json:"input_tokens"`
 	OutputTokens     int `json:"output_tokens"`
+
+	PromptTokensDetails     *tokenDetails `json:"prompt_tokens_details"`
+	CompletionTokensDetails *tokenDetails `json:"completion_tokens_details"`
+	InputTokensDetails      *tokenDetails `json:"input_tokens_details"`
+	OutputTokensDetails     *tokenDetails `json:"output_tokens_details"`
 }
 
+type tokenDetails struct {
+	CachedTokens    int `json:"cached_tokens"`
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// tokens переводит usage в aiwf.Tokens.
+func (u usagePayload) tokens() aiwf.Tokens {
+	usage := aiwf.Tokens{
+		Prompt:     u.PromptTokens,
+		Completion: u.CompletionTokens,
+		Total:      u.TotalTokens,
+	}
+	if u.InputTokens != 0 || u.OutputTokens != 0 {
+		usage.Prompt = u.InputTokens
+		usage.Completion = u.OutputTokens
+	}
+	if usage.Total == 0 {
+		usage.Total = usage.Prompt + usage.Completion
+	}
+
+	for _, d := range []*tokenDetails{u.PromptTokensDetails, u.InputTokensDetails} {
+		if d != nil {
+			usage.CachedPrompt += d.CachedTokens
+		}
+	}
+	for _, d := range []*tokenDetails{u.CompletionTokensDetails, u.OutputTokensDetails} {
+		if d != nil {
+			usage.Reasoning += d.ReasoningTokens
+		}
+	}
+	return usage
+}
+
+// requestID возвращает идентификатор запроса из заголовка x-request-id,
+// а при его отсутствии — идентификатор ответа.
+func requestID(header http.Header, fallback string) string {
+	if id := header.Get("x-request-id"); id != "" {
+		return id
+	}
+	return fallback
+}
+
+// userMessages возвращает тексты пользовательских сообщений. Обычно промпт и
+// payload объединяются в одно сообщение; при кэшировании payload уходит в
+// отдельное последнее сообщение, чтобы неизменная часть оставалась префиксом.
+func userMessages(call aiwf.ModelCall) ([]string, error) {
+	var parts []string
+	if prompt := strings.TrimSpace(call.UserPrompt); prompt != "" {
+		parts = append(parts, prompt)
+	}
+	if call.Payload != nil {
+		data, err := json.Marshal(call.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("openai: marshal payload: %w", err)
+		}
+		parts = append(parts, string(data))
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	if call.PromptCache {
+		return parts, nil
+	}
+	return []string{strings.Join(parts, "\n\n")}, nil
+}
+
+// promptCacheKey возвращает ключ кэша промпта: вызовы одного ассистента с
+// одинаковым системным промптом попадают на один и тот же кэш.
+func promptCacheKey(call aiwf.ModelCall) string {
+	if !call.PromptCache {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(call.Model + "\x00" + call.SystemPrompt))
+	return "aiwf-" + hex.EncodeToString(sum[:8])
+}
+
+// buildInputMessages собирает вход Responses API. Порядок сообщений — системный
+// промпт, история, пользовательский ввод — сохраняет общий префикс вызовов,
+// который OpenAI кэширует автоматически.
 func buildInputMessages(call aiwf.ModelCall) ([]inputMessage, error) {
 	var messages []inputMessage
 
@@ -236,23 +492,28 @@ func buildInputMessages(call aiwf.ModelCall) ([]inputMessage, error) {
 		})
 	}
 
-	var userParts []string
-	if call.UserPrompt != "" {
-		userParts = append(userParts, call.UserPrompt)
-	}
-	if call.Payload != nil {
-		data, err := json.Marshal(call.Payload)
-		if err != nil {
-			return nil, fmt.Errorf("openai: marshal payload: %w", err)
+	for _, msg := range call.History {
+		blockType := "input_text"
+		if msg.Role == "assistant" {
+			blockType = "output_text"
 		}
-		userParts = append(userParts, string(data))
+		messages = append(messages, inputMessage{
+			Role: msg.Role,
+			Content: []contentBlock{
+				{Type: blockType, Text: msg.Content},
+			},
+		})
 	}
-	userText := strings.TrimSpace(strings.Join(userParts, "\n\n"))
-	if userText != "" {
+
+	userTexts, err := userMessages(call)
+	if err != nil {
+		return nil, err
+	}
+	for _, text := range userTexts {
 		messages = append(messages, inputMessage{
 			Role: "user",
 			Content: []contentBlock{
-				{Type: "input_text", Text: userText},
+				{Type: "input_text", Text: text},
 			},
 		})
 	}
@@ -279,6 +540,11 @@ func (c *Client) buildJSONSchemaFormat(call aiwf.ModelCall) (textSection, error)
 		if err != nil {
 			return textSection{}, fmt.Errorf("openai: failed to convert type metadata: %w", err)
 		}
+		// Strict json_schema принимает только объектный корень
+		schema, err = wrapSchema(schema)
+		if err != nil {
+			return textSection{}, fmt.Errorf("openai: failed to wrap output schema: %w", err)
+		}
 	} else {
 		// If no metadata provided, create a minimal schema
 		log.Printf("openai: buildJSONSchemaFormat - WARNING: TypeMetadata is nil, using minimal schema")
@@ -305,6 +571,37 @@ func (c *Client) buildJSONSchemaFormat(call aiwf.ModelCall) (textSection, error)
 	}, nil
 }
 
+// wrapSchema оборачивает схему с необъектным корнем в объект с полем value.
+func wrapSchema(schema json.RawMessage) (json.RawMessage, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, err
+	}
+	wrapped, ok := core.WrapValueSchema(root)
+	if !ok {
+		return schema, nil
+	}
+	return json.Marshal(wrapped)
+}
+
+// wrappedOutput сообщает, обёрнут ли выходной тип вызова в объект с полем value;
+// такой ответ разворачивается перед возвратом.
+func wrappedOutput(call aiwf.ModelCall) bool {
+	if isTextOutput(call) || call.TypeMetadata == nil {
+		return false
+	}
+	schema, err := core.SchemaFromMetadata(call.TypeMetadata, core.DialectOpenAIStrict)
+	if err != nil {
+		return false
+	}
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return false
+	}
+	_, wrapped := core.WrapValueSchema(root)
+	return wrapped
+}
+
 func schemaFormatName(ref string) string {
 	ref = strings.TrimSpace(ref)
 	if ref == "" {
@@ -361,7 +658,9 @@ func buildMetadata(call aiwf.ModelCall) map[string]any {
 	return meta
 }
 
-func extractStructuredText(messages []responseMessage) (string, error) {
+// extractStructuredText собирает текст ответа. Строковый выход склеивается как есть:
+// пробелы на стыках важны при продолжении обрезанного ответа.
+func extractStructuredText(messages []responseMessage, raw bool) (string, error) {
 	if len(messages) == 0 {
 		return "", errors.New("openai: empty output")
 	}
@@ -369,6 +668,10 @@ func extractStructuredText(messages []responseMessage) (string, error) {
 	var sb strings.Builder
 	for _, message := range messages {
 		for _, block := range message.Content {
+			if raw {
+				sb.WriteString(block.Text)
+				continue
+			}
 			text := strings.TrimSpace(block.Text)
 			if text == "" {
 				continue
@@ -77,7 +77,7 @@ func TestCallJSONSchemaSuccess(t *testing.T) {
 			},
 		},
 		MaxTokens:   128,
-		Temperature: 0.7,
+		Temperature: aiwf.Float64(0.7),
 	}
 
 	raw, usage, err := client.CallJSONSchema(context.Background(), call)
@@ -190,3 +190,245 @@ func TestStreamingNotImplemented(t *testing.T) {
 		t.Fatal("expected closed channel")
 	}
 }
+
+func TestCallReportsTruncation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status":             "incomplete",
+			"incomplete_details": map[string]any{"reason": "max_output_tokens"},
+			"output": []any{
+				map[string]any{
+					"content": []any{
+						map[string]any{"type": "output_text", "text": `{"answer":`},
+					},
+				},
+			},
+			"usage": map[string]any{"input_tokens": 3, "output_tokens": 1},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "secret", HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{
+		OutputTypeName: "answer",
+		UserPrompt:     "ping",
+		History:        []aiwf.Message{{Role: "assistant", Content: "earlier"}},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if result.StopReason != aiwf.StopReasonMaxTokens {
+		t.Fatalf("expected max_tokens stop reason, got %q", result.StopReason)
+	}
+	if result.RawStopReason != "max_output_tokens" {
+		t.Fatalf("unexpected raw stop reason: %q", result.RawStopReason)
+	}
+	if string(result.Data) != `{"answer":` {
+		t.Fatalf("unexpected data: %s", result.Data)
+	}
+}
+
+func TestCallReportsUsageDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_123")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":     "resp_1",
+			"model":  "o4-mini-2025-04-16",
+			"status": "completed",
+			"output": []any{map[string]any{"content": []any{map[string]any{"type": "output_text", "text": "hi"}}}},
+			"usage": map[string]any{
+				"input_tokens":          100,
+				"output_tokens":         40,
+				"total_tokens":          140,
+				"input_tokens_details":  map[string]any{"cached_tokens": 64},
+				"output_tokens_details": map[string]any{"reasoning_tokens": 32},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	result, err := client.Call(context.Background(), aiwf.ModelCall{Model: "o4-mini", UserPrompt: "ping", OutputTypeName: "answer"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	want := aiwf.Tokens{Prompt: 100, Completion: 40, Total: 140, CachedPrompt: 64, Reasoning: 32}
+	if result.Usage != want {
+		t.Fatalf("unexpected usage: %+v", result.Usage)
+	}
+	if result.RequestID != "req_123" || result.Model != "o4-mini-2025-04-16" {
+		t.Fatalf("unexpected request id/model: %q %q", result.RequestID, result.Model)
+	}
+}
+
+func TestPromptCacheKeepsStablePrefix(t *testing.T) {
+	call := aiwf.ModelCall{
+		Model:        "gpt-4o",
+		SystemPrompt: "You are a classifier.",
+		UserPrompt:   "Classify the ticket.",
+		Payload:      map[string]any{"text": "printer is on fire"},
+		History:      []aiwf.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}},
+		PromptCache:  true,
+	}
+
+	messages, err := buildInputMessages(call)
+	if err != nil {
+		t.Fatalf("buildInputMessages: %v", err)
+	}
+	var roles []string
+	for _, m := range messages {
+		roles = append(roles, m.Role)
+	}
+	if got := strings.Join(roles, ","); got != "system,user,assistant,user,user" {
+		t.Fatalf("unexpected message order: %s", got)
+	}
+	if text := messages[3].Content[0].Text; text != "Classify the ticket." {
+		t.Fatalf("expected static prompt before payload, got %q", text)
+	}
+	if text := messages[4].Content[0].Text; text != `{"text":"printer is on fire"}` {
+		t.Fatalf("expected payload last, got %q", text)
+	}
+
+	key := promptCacheKey(call)
+	call.Payload = map[string]any{"text": "other"}
+	if key == "" || promptCacheKey(call) != key {
+		t.Fatalf("cache key must depend only on model and system prompt: %q", key)
+	}
+	call.PromptCache = false
+	if promptCacheKey(call) != "" {
+		t.Fatal("cache key must be empty when caching is disabled")
+	}
+}
+
+func TestCallKeepsTextWhitespace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status":             "incomplete",
+			"incomplete_details": map[string]any{"reason": "max_output_tokens"},
+			"output": []any{map[string]any{"content": []any{
+				map[string]any{"type": "output_text", "text": "first word "},
+				map[string]any{"type": "output_text", "text": "and more "},
+			}}},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	result, err := client.Call(context.Background(), aiwf.ModelCall{UserPrompt: "ping", OutputTypeName: "string"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if string(result.Data) != "first word and more " {
+		t.Fatalf("text output must keep whitespace, got %q", result.Data)
+	}
+}
+
+func TestCallReportsFailedResponse(t *testing.T) {
+	for status, want := range map[string]string{
+		"failed":    "openai: response failed: server overloaded",
+		"cancelled": "openai: response cancelled",
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := map[string]any{"status": status, "output": []any{}}
+			if status == "failed" {
+				body["error"] = map[string]any{"code": "server_error", "message": "server overloaded"}
+			}
+			_ = json.NewEncoder(w).Encode(body)
+		}))
+
+		client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "secret"})
+		if err != nil {
+			t.Fatalf("new client: %v", err)
+		}
+		_, err = client.Call(context.Background(), aiwf.ModelCall{UserPrompt: "ping", OutputTypeName: "answer"})
+		srv.Close()
+		if err == nil || err.Error() != want {
+			t.Fatalf("%s: expected error %q, got %v", status, want, err)
+		}
+	}
+}
+
+func TestIgnoredOptionsByMode(t *testing.T) {
+	info, ok := aiwf.LookupProvider("openai")
+	if !ok {
+		t.Fatal("openai provider not registered")
+	}
+	if got := strings.Join(info.IgnoredOptions(""), ","); got != "seed,stop,presence_penalty,frequency_penalty" {
+		t.Fatalf("responses mode must report dropped options, got %s", got)
+	}
+	if got := info.IgnoredOptions(string(ModeChatCompletions)); len(got) != 0 {
+		t.Fatalf("chat completions mode sends all options, got %v", got)
+	}
+}
+
+// unionMetadata — схема выходного типа oneOf($Refund, $Escalation) без объектного корня.
+var unionMetadata = map[string]any{
+	"anyOf": []any{
+		map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"kind": map[string]any{"type": "string", "enum": []any{"refund"}}, "amount": map[string]any{"type": "number"}},
+			"required":   []any{"kind", "amount"},
+		},
+		map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"kind": map[string]any{"type": "string", "enum": []any{"escalation"}}, "team": map[string]any{"type": "string"}},
+			"required":   []any{"kind", "team"},
+		},
+	},
+}
+
+func TestCallWrapsUnionOutput(t *testing.T) {
+	for _, mode := range []Mode{ModeResponses, ModeChatCompletions} {
+		var schema map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			answer := `{"value":{"kind":"refund","amount":5}}`
+			if mode == ModeChatCompletions {
+				schema = payload["response_format"].(map[string]any)["json_schema"].(map[string]any)["schema"].(map[string]any)
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"choices": []any{map[string]any{"message": map[string]any{"content": answer}, "finish_reason": "stop"}},
+				})
+				return
+			}
+			schema = payload["text"].(map[string]any)["format"].(map[string]any)["schema"].(map[string]any)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "completed",
+				"output": []any{map[string]any{"content": []any{map[string]any{"type": "output_text", "text": answer}}}},
+			})
+		}))
+
+		client, err := NewClient(ClientConfig{BaseURL: srv.URL, APIKey: "secret", Mode: mode})
+		if err != nil {
+			t.Fatalf("new client: %v", err)
+		}
+		result, err := client.Call(context.Background(), aiwf.ModelCall{
+			UserPrompt:     "ping",
+			OutputTypeName: "Resolution",
+			TypeMetadata:   unionMetadata,
+		})
+		srv.Close()
+		if err != nil {
+			t.Fatalf("%s: Call: %v", mode, err)
+		}
+
+		value, _ := schema["properties"].(map[string]any)["value"].(map[string]any)
+		if schema["type"] != "object" || value["anyOf"] == nil {
+			t.Fatalf("%s: expected union wrapped in an object, got %v", mode, schema)
+		}
+		if string(result.Data) != `{"kind":"refund","amount":5}` {
+			t.Fatalf("%s: expected unwrapped value, got %s", mode, result.Data)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package openai
+
+import "github.com/andranikuz/aiwf/runtime/go/aiwf"
+
+// Регистрирует провайдера под именем use: openai.
+func init() {
+	aiwf.RegisterProvider(aiwf.ProviderInfo{
+		Name:         "openai",
+		ImportPath:   "github.com/andranikuz/aiwf/providers/openai",
+		EnvKeys:      []string{"OPENAI_API_KEY"},
+		Capabilities: []aiwf.Capability{aiwf.CapabilitySchema, aiwf.CapabilityThreads, aiwf.CapabilityPromptCache, aiwf.CapabilityEmbeddings},
+		New:          newFromConfig,
+		IgnoredOptions: func(mode string) []string {
+			if mode == "" {
+				return IgnoredOptions(ModeResponses)
+			}
+			return IgnoredOptions(Mode(mode))
+		},
+	})
+}
+
+// newFromConfig создаёт клиента OpenAI из настроек providers: и переменных окружения.
+func newFromConfig(cfg aiwf.ProviderConfig) (aiwf.ModelClient, error) {
+	apiKey := cfg.APIKey("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, aiwf.ErrProviderNotConfigured
+	}
+	headers := cfg.Headers
+	if cfg.Org != "" {
+		headers = make(map[string]string, len(cfg.Headers)+1)
+		for k, v := range cfg.Headers {
+			headers[k] = v
+		}
+		headers["OpenAI-Organization"] = cfg.Org
+	}
+	return NewClient(ClientConfig{BaseURL: cfg.BaseURL, APIKey: apiKey, Timeout: cfg.Timeout, Headers: headers, Mode: Mode(cfg.Mode)})
+}
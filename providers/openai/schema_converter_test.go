@@ -182,4 +182,40 @@ func TestSchemaConverterEnum(t *testing.T) {
 	if len(enum) != 3 {
 		t.Errorf("expected 3 enum values, got %d", len(enum))
 	}
-}
\ No newline at end of file
+}
+func TestSchemaConverterRecursiveTypes(t *testing.T) {
+	converter := NewSchemaConverter()
+
+	types := map[string]*core.TypeDef{
+		"Comment": {
+			Kind: core.KindObject,
+			Properties: map[string]*core.TypeDef{
+				"text":    {Kind: core.KindString},
+				"parent":  {Kind: core.KindRef, Ref: "$Comment", Optional: true},
+				"replies": {Kind: core.KindArray, Items: &core.TypeDef{Kind: core.KindRef, Ref: "$Comment"}},
+			},
+		},
+	}
+
+	schema, err := converter.ConvertTypesMap(types, "Comment")
+	if err != nil {
+		t.Fatalf("ConvertTypesMap: %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(schema, &result); err != nil {
+		t.Fatalf("unmarshal schema: %v", err)
+	}
+
+	props := result["properties"].(map[string]any)
+	items := props["replies"].(map[string]any)["items"].(map[string]any)
+	if items["$ref"] != "#" {
+		t.Errorf("expected replies to reference the root, got %v", items)
+	}
+	// Необязательное поле в strict-режиме становится nullable
+	parent := props["parent"].(map[string]any)
+	variants, ok := parent["anyOf"].([]any)
+	if !ok || len(variants) != 2 || variants[0].(map[string]any)["$ref"] != "#" {
+		t.Errorf("expected nullable reference to the root, got %v", parent)
+	}
+}
@@ -19,6 +19,7 @@ type InMemoryThreadManager struct {
 type ThreadData struct {
 	ID       string
 	Messages []string
+	Turns    []aiwf.Message // реплики диалога для провайдеров без серверных тредов
 	Metadata map[string]any
 }
 
@@ -85,6 +86,33 @@ func (m *InMemoryThreadManager) Close(ctx context.Context, state *aiwf.ThreadSta
 	return nil
 }
 
+// History возвращает сохранённые реплики треда
+func (m *InMemoryThreadManager) History(ctx context.Context, threadID string) ([]aiwf.Message, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	thread, ok := m.threads[threadID]
+	if !ok {
+		return nil, fmt.Errorf("thread %s not found", threadID)
+	}
+
+	return append([]aiwf.Message(nil), thread.Turns...), nil
+}
+
+// Append добавляет реплики в тред
+func (m *InMemoryThreadManager) Append(ctx context.Context, threadID string, messages ...aiwf.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	thread, ok := m.threads[threadID]
+	if !ok {
+		return fmt.Errorf("thread %s not found", threadID)
+	}
+
+	thread.Turns = append(thread.Turns, messages...)
+	return nil
+}
+
 // GetThread возвращает информацию о треде (для отладки)
 func (m *InMemoryThreadManager) GetThread(threadID string) (*ThreadData, error) {
 	m.mu.RLock()
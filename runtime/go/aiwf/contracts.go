@@ -6,10 +6,14 @@ import (
 )
 
 // Tokens описывает затраты токенов, отражая раздел Contracts в sdk.md.
+// CachedPrompt и CacheWrite входят в Prompt, Reasoning — в Completion.
 type Tokens struct {
-	Prompt     int
-	Completion int
-	Total      int
+	Prompt       int
+	Completion   int
+	Total        int
+	CachedPrompt int // входные токены, прочитанные из кэша промпта
+	CacheWrite   int // входные токены, записанные в кэш промпта
+	Reasoning    int // токены рассуждения reasoning-моделей
 }
 
 // Trace фиксирует наблюдаемость выполнения шага, совпадая с ожиданиями SDK.
@@ -19,19 +23,95 @@ type Trace struct {
 	Attempts   int
 	Duration   time.Duration
 	ArtifactID string
+	StopReason StopReason  // причина остановки последнего вызова
+	Calls      []CallTrace // все вызовы модели внутри шага (продолжения, повторы)
+	Citations  []Citation  // фрагменты знаний, подставленные в промпт
+}
+
+// CacheHitRate возвращает долю входных токенов шага, прочитанных из кэша промпта.
+func (t *Trace) CacheHitRate() float64 {
+	if t == nil || t.Usage.Prompt == 0 {
+		return 0
+	}
+	return float64(t.Usage.CachedPrompt) / float64(t.Usage.Prompt)
+}
+
+// CallTrace описывает отдельный вызов модели внутри шага.
+type CallTrace struct {
+	Attempt    int
+	Kind       CallKind
+	MaxTokens  int
+	StopReason StopReason
+	Usage      Tokens
+	Duration   time.Duration
+	RequestID  string // идентификатор запроса у провайдера
+	Model      string // модель, фактически обработавшая запрос
+}
+
+// CallKind описывает назначение вызова модели внутри шага.
+type CallKind string
+
+const (
+	CallKindInitial   CallKind = "initial"   // первый вызов
+	CallKindContinue  CallKind = "continue"  // продолжение обрезанного текста
+	CallKindRetry     CallKind = "retry"     // повтор с увеличенным лимитом токенов
+	CallKindEmbedding CallKind = "embedding" // запрос эмбеддингов
+)
+
+// StopReason описывает причину завершения генерации в терминах рантайма.
+type StopReason string
+
+const (
+	StopReasonUnknown       StopReason = ""
+	StopReasonEnd           StopReason = "end"            // модель завершила ответ
+	StopReasonMaxTokens     StopReason = "max_tokens"     // ответ обрезан лимитом токенов
+	StopReasonStopSequence  StopReason = "stop_sequence"  // сработала стоп-последовательность
+	StopReasonToolUse       StopReason = "tool_use"       // модель вызвала инструмент
+	StopReasonContentFilter StopReason = "content_filter" // ответ заблокирован фильтром
+)
+
+// Message описывает реплику диалога, передаваемую провайдеру.
+type Message struct {
+	Role    string // user или assistant
+	Content string
+}
+
+// CallResult — расширенный результат вызова модели.
+type CallResult struct {
+	Data          []byte
+	Usage         Tokens
+	StopReason    StopReason
+	RawStopReason string // исходное значение stop_reason/finish_reason провайдера
+	RequestID     string // идентификатор запроса у провайдера (для обращений в поддержку)
+	Model         string // модель, фактически обработавшая запрос
 }
 
 // ModelCall описывает запрос к LLM.
 type ModelCall struct {
+	Provider       string // имя провайдера из спецификации; используется Router
 	Model          string
 	SystemPrompt   string
 	UserPrompt     string
 	MaxTokens      int
-	Temperature    float64
 	Stream         bool
 	Payload        any // Входные данные (уже типизированные)
 	ThreadID       string
 	ThreadMetadata map[string]any
+	History        []Message // предыдущие реплики, идут перед текущим запросом
+
+	// Параметры генерации; nil и пустые значения не передаются провайдеру.
+	// Провайдер пропускает параметры, которые его API не поддерживает.
+	Temperature      *float64
+	TopP             *float64
+	Seed             *int
+	Stop             []string
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	ReasoningEffort  string // minimal, low, medium или high
+
+	// PromptCache разрешает провайдеру кэшировать стабильный префикс запроса
+	// (системный промпт, схему вывода, историю).
+	PromptCache bool
 
 	// Метаданные типов для провайдера
 	InputTypeName  string // Имя входного типа
@@ -45,6 +125,8 @@ type StreamChunk struct {
 	Done       bool
 	Partial    any
 	Timestamps map[string]any
+	Usage      *Tokens // заполняется в финальном чанке, если провайдер сообщает расход токенов
+	Err        error   // заполняется в финальном чанке, если поток завершился ошибкой
 }
 
 // ModelClient оборачивает вызовы модели, возвращая строго типизированные результаты.
@@ -53,6 +135,19 @@ type ModelClient interface {
 	CallJSONSchemaStream(ctx context.Context, call ModelCall) (<-chan StreamChunk, Tokens, error)
 }
 
+// ResultClient — опциональное расширение ModelClient, сообщающее причину остановки.
+// AgentBase использует его, чтобы обнаруживать обрезку ответа по max_tokens.
+type ResultClient interface {
+	Call(ctx context.Context, call ModelCall) (*CallResult, error)
+}
+
+// TurnRecorder — опциональное расширение ModelClient для провайдеров, которые
+// сами ведут историю треда. Рантайм вызывает его, чтобы сохранить ход,
+// собранный из продолжений обрезанного ответа: сами продолжения провайдер не пишет.
+type TurnRecorder interface {
+	RecordTurn(ctx context.Context, call ModelCall, result *CallResult) error
+}
+
 // Workflow описывает типизированный раннер воркфлоу.
 type Workflow[I any, O any] interface {
 	Run(ctx context.Context, input I) (O, *Trace, error)
@@ -92,6 +187,13 @@ type ThreadManager interface {
 	Close(ctx context.Context, state *ThreadState) error
 }
 
+// ThreadHistory — опциональное расширение ThreadManager, хранящее реплики треда.
+// Провайдеры без серверных тредов используют его, чтобы передавать модели предыдущие ходы.
+type ThreadHistory interface {
+	History(ctx context.Context, threadID string) ([]Message, error)
+	Append(ctx context.Context, threadID string, messages ...Message) error
+}
+
 // TypeProvider предоставляет метаданные типов для провайдеров.
 // SDK реализует этот интерфейс для экспорта информации о типах.
 type TypeProvider interface {
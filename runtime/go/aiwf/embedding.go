@@ -0,0 +1,74 @@
+package aiwf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrEmbeddingsNotSupported возвращается, если клиент не реализует EmbeddingClient.
+var ErrEmbeddingsNotSupported = errors.New("aiwf: provider does not support embeddings")
+
+// EmbeddingCall описывает запрос эмбеддингов.
+type EmbeddingCall struct {
+	Provider   string // имя провайдера из спецификации; используется Router
+	Model      string
+	Input      []string
+	Dimensions int // размерность вектора; 0 — значение модели по умолчанию
+}
+
+// EmbeddingResult содержит векторы в порядке входных текстов.
+type EmbeddingResult struct {
+	Vectors   [][]float32
+	Usage     Tokens
+	RequestID string // идентификатор запроса у провайдера
+	Model     string // модель, фактически обработавшая запрос
+}
+
+// EmbeddingClient — опциональное расширение ModelClient для провайдеров с API эмбеддингов.
+type EmbeddingClient interface {
+	Embed(ctx context.Context, call EmbeddingCall) (*EmbeddingResult, error)
+}
+
+// CallEmbedding запрашивает эмбеддинги текстов у клиента агента.
+func (a *AgentBase) CallEmbedding(ctx context.Context, texts []string) ([][]float32, *Trace, error) {
+	ec, ok := a.Client.(EmbeddingClient)
+	if !ok {
+		return nil, nil, ErrEmbeddingsNotSupported
+	}
+	if len(texts) == 0 {
+		return nil, &Trace{StepName: a.Config.Name}, nil
+	}
+
+	start := time.Now()
+	result, err := ec.Embed(ctx, EmbeddingCall{
+		Provider:   a.Config.Provider,
+		Model:      a.Config.Model,
+		Input:      texts,
+		Dimensions: a.Config.Dimensions,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("embedding call failed: %w", err)
+	}
+	if len(result.Vectors) != len(texts) {
+		return nil, nil, fmt.Errorf("embedding call failed: got %d vectors for %d inputs", len(result.Vectors), len(texts))
+	}
+
+	duration := time.Since(start)
+	trace := &Trace{
+		StepName: a.Config.Name,
+		Usage:    result.Usage,
+		Attempts: 1,
+		Duration: duration,
+		Calls: []CallTrace{{
+			Attempt:   1,
+			Kind:      CallKindEmbedding,
+			Usage:     result.Usage,
+			Duration:  duration,
+			RequestID: result.RequestID,
+			Model:     result.Model,
+		}},
+	}
+	return result.Vectors, trace, nil
+}
@@ -0,0 +1,61 @@
+package aiwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type embeddingClient struct {
+	fakeClient
+	calls []EmbeddingCall
+}
+
+func (c *embeddingClient) Embed(ctx context.Context, call EmbeddingCall) (*EmbeddingResult, error) {
+	c.calls = append(c.calls, call)
+	vectors := make([][]float32, len(call.Input))
+	for i := range call.Input {
+		vectors[i] = []float32{float32(i), 1}
+	}
+	return &EmbeddingResult{
+		Vectors:   vectors,
+		Usage:     Tokens{Prompt: 8, Total: 8},
+		RequestID: "req_1",
+		Model:     "embed-1",
+	}, nil
+}
+
+func TestCallEmbedding(t *testing.T) {
+	client := &embeddingClient{}
+	router := NewRouter().Route("vectors", client)
+	agent := &AgentBase{
+		Config: AgentConfig{Name: "search", Provider: "vectors", Model: "embed-1", Dimensions: 2},
+		Client: router,
+	}
+
+	vectors, trace, err := agent.CallEmbedding(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("CallEmbedding: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 1 {
+		t.Fatalf("unexpected vectors: %v", vectors)
+	}
+	if call := client.calls[0]; call.Model != "embed-1" || call.Dimensions != 2 || len(call.Input) != 2 {
+		t.Fatalf("unexpected call: %+v", call)
+	}
+	if trace.Usage.Prompt != 8 || len(trace.Calls) != 1 || trace.Calls[0].Kind != CallKindEmbedding || trace.Calls[0].RequestID != "req_1" {
+		t.Fatalf("unexpected trace: %+v", trace)
+	}
+}
+
+func TestCallEmbeddingNotSupported(t *testing.T) {
+	agent := &AgentBase{Config: AgentConfig{Name: "search"}, Client: fakeClient{}}
+	if _, _, err := agent.CallEmbedding(context.Background(), []string{"a"}); !errors.Is(err, ErrEmbeddingsNotSupported) {
+		t.Fatalf("expected ErrEmbeddingsNotSupported, got %v", err)
+	}
+
+	router := NewRouter().Route("chat", fakeClient{})
+	if _, err := router.Embed(context.Background(), EmbeddingCall{Provider: "chat", Input: []string{"a"}}); !errors.Is(err, ErrEmbeddingsNotSupported) {
+		t.Fatalf("expected ErrEmbeddingsNotSupported from router, got %v", err)
+	}
+}
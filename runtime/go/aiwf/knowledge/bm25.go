@@ -0,0 +1,81 @@
+package knowledge
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Параметры BM25 (Okapi).
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// bm25 — инвертированный индекс для ранжирования фрагментов по BM25.
+type bm25 struct {
+	postings map[string][]posting // терм → фрагменты с частотой терма
+	lengths  []int                // длина фрагмента в термах
+	avgLen   float64
+}
+
+type posting struct {
+	doc  int
+	freq int
+}
+
+func newBM25(texts []string) *bm25 {
+	idx := &bm25{postings: make(map[string][]posting), lengths: make([]int, len(texts))}
+	total := 0
+	for i, text := range texts {
+		terms := tokenize(text)
+		idx.lengths[i] = len(terms)
+		total += len(terms)
+
+		freq := make(map[string]int)
+		for _, t := range terms {
+			freq[t]++
+		}
+		for t, f := range freq {
+			idx.postings[t] = append(idx.postings[t], posting{doc: i, freq: f})
+		}
+	}
+	if len(texts) > 0 {
+		idx.avgLen = float64(total) / float64(len(texts))
+	}
+	return idx
+}
+
+// scores возвращает оценки фрагментов, содержащих хотя бы один терм запроса.
+func (idx *bm25) scores(query string) map[int]float64 {
+	n := float64(len(idx.lengths))
+	scores := make(map[int]float64)
+
+	seen := make(map[string]bool)
+	for _, term := range tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.freq)
+			norm := 1 - bm25B + bm25B*float64(idx.lengths[p.doc])/idx.avgLen
+			scores[p.doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return scores
+}
+
+// tokenize приводит текст к нижнему регистру и делит его на слова из букв и цифр.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,145 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Chunk — фрагмент документа, единица поиска.
+type Chunk struct {
+	Path   string    `json:"path"`
+	Index  int       `json:"index"` // номер фрагмента в файле
+	Text   string    `json:"text"`
+	Vector []float32 `json:"vector,omitempty"`
+}
+
+// document — прочитанный файл источника.
+type document struct {
+	path string
+	text []string // части документа: целый текст или элементы JSON-массива
+}
+
+// loadDocuments читает файлы источника, подходящие под Include, в детерминированном порядке.
+// Относительные пути считаются от dir; в путях документов dir не указывается.
+func loadDocuments(cfg Config, dir string) ([]document, error) {
+	var docs []document
+	for _, root := range cfg.Paths {
+		base := ""
+		if dir != "" && !filepath.IsAbs(root) {
+			base = dir
+			root = filepath.Join(dir, root)
+		}
+		var files []string
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if matchAny(cfg.Include, d.Name()) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+
+		for _, path := range files {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			name := path
+			if base != "" {
+				if rel, err := filepath.Rel(base, path); err == nil {
+					name = rel
+				}
+			}
+			docs = append(docs, document{path: filepath.ToSlash(name), text: splitDocument(path, data)})
+		}
+	}
+	return docs, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitDocument делит JSON-массив на элементы, остальные файлы возвращает целиком.
+func splitDocument(path string, data []byte) []string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err == nil {
+			parts := make([]string, 0, len(items))
+			for _, item := range items {
+				parts = append(parts, string(item))
+			}
+			return parts
+		}
+	}
+	return []string{string(data)}
+}
+
+// chunkText делит текст на фрагменты не длиннее size рун, стараясь резать по абзацам.
+// Соседние фрагменты перекрываются на overlap рун; overlap должен быть меньше size.
+func chunkText(text string, size, overlap int) []string {
+	var chunks []string
+	var current []rune
+	fresh := false // в current есть текст помимо перекрытия
+
+	flush := func() {
+		if s := strings.TrimSpace(string(current)); fresh && s != "" {
+			chunks = append(chunks, s)
+		}
+		if overlap > 0 && len(current) > overlap {
+			current = append([]rune(nil), current[len(current)-overlap:]...)
+		} else {
+			current = current[:0]
+		}
+		fresh = false
+	}
+
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		runes := []rune(strings.TrimSpace(para))
+		if len(runes) == 0 {
+			continue
+		}
+		if fresh && len(current)+2+len(runes) > size {
+			flush()
+		}
+		if len(current) > 0 {
+			current = append(current, '\n', '\n')
+		}
+		// Абзац длиннее фрагмента режется по size
+		for len(current)+len(runes) > size {
+			n := size - len(current)
+			if n <= 0 {
+				current = current[:0]
+				n = size
+			}
+			current = append(current, runes[:n]...)
+			runes = runes[n:]
+			fresh = true
+			flush()
+		}
+		current = append(current, runes...)
+		fresh = true
+	}
+	flush()
+	return chunks
+}
@@ -0,0 +1,334 @@
+// Package knowledge строит локальный поисковый индекс по каталогам с markdown,
+// текстом и JSON и подставляет найденные фрагменты в промпт агента (aiwf.Retriever).
+//
+// Ранжирование — BM25; если задана функция эмбеддингов, результаты BM25 и
+// косинусной близости объединяются через reciprocal rank fusion.
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+)
+
+// Значения по умолчанию для незаданных полей Config.
+const (
+	DefaultChunkSize    = 1000 // рун
+	DefaultChunkOverlap = 100  // рун
+	DefaultTopK         = 4
+)
+
+// DefaultInclude — шаблоны имён файлов, индексируемых по умолчанию.
+var DefaultInclude = []string{"*.md", "*.markdown", "*.txt", "*.json"}
+
+// indexVersion меняется при несовместимом изменении формата сохранённого индекса.
+const indexVersion = 1
+
+// embedBatchSize ограничивает число фрагментов в одном запросе эмбеддингов.
+const embedBatchSize = 64
+
+// rrfK — сглаживающая константа reciprocal rank fusion.
+const rrfK = 60
+
+// Config описывает источник знаний (запись раздела knowledge:).
+type Config struct {
+	Name         string
+	Paths        []string // каталоги с документами
+	Include      []string // шаблоны имён файлов; пусто — DefaultInclude
+	ChunkSize    int      // максимальная длина фрагмента в рунах
+	ChunkOverlap *int     // перекрытие соседних фрагментов в рунах; nil — DefaultChunkOverlap, 0 — без перекрытия
+	TopK         int      // число фрагментов, подставляемых в промпт
+}
+
+func (c Config) normalized() (Config, error) {
+	if len(c.Paths) == 0 {
+		return c, fmt.Errorf("knowledge %s: at least one path is required", c.Name)
+	}
+	if len(c.Include) == 0 {
+		c.Include = DefaultInclude
+	}
+	if c.ChunkSize <= 0 {
+		c.ChunkSize = DefaultChunkSize
+	}
+	switch {
+	case c.ChunkOverlap == nil:
+		overlap := DefaultChunkOverlap
+		if overlap >= c.ChunkSize {
+			overlap = c.ChunkSize / 10
+		}
+		c.ChunkOverlap = aiwf.Int(overlap)
+	case *c.ChunkOverlap < 0:
+		return c, fmt.Errorf("knowledge %s: chunk overlap must not be negative", c.Name)
+	case *c.ChunkOverlap >= c.ChunkSize:
+		return c, fmt.Errorf("knowledge %s: chunk overlap %d must be less than chunk size %d", c.Name, *c.ChunkOverlap, c.ChunkSize)
+	}
+	if c.TopK <= 0 {
+		c.TopK = DefaultTopK
+	}
+	return c, nil
+}
+
+// EmbedFunc возвращает эмбеддинги текстов в порядке входа.
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// Options задаёт необязательные зависимости индекса.
+type Options struct {
+	// Store сохраняет построенный индекс; при неизменных документах он
+	// загружается из хранилища без повторной нарезки и векторизации.
+	Store aiwf.ArtifactStore
+	// Embed включает векторный поиск в дополнение к BM25.
+	Embed EmbedFunc
+	// EmbeddingModel входит в ключ сохранённого индекса: смена модели его сбрасывает.
+	EmbeddingModel string
+	// Dir — каталог, от которого считаются относительные Config.Paths;
+	// пусто — рабочий каталог процесса.
+	Dir string
+}
+
+// Index — поисковый индекс источника знаний.
+type Index struct {
+	cfg    Config
+	chunks []Chunk
+	bm25   *bm25
+	embed  EmbedFunc
+}
+
+var _ aiwf.Retriever = (*Index)(nil)
+
+// storedIndex — формат индекса в ArtifactStore.
+type storedIndex struct {
+	Version int     `json:"version"`
+	Chunks  []Chunk `json:"chunks"`
+}
+
+// Build читает документы источника и строит индекс либо загружает его из Options.Store.
+func Build(ctx context.Context, cfg Config, opts Options) (*Index, error) {
+	cfg, err := cfg.normalized()
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := loadDocuments(cfg, opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge %s: %w", cfg.Name, err)
+	}
+
+	var key string
+	if opts.Store != nil {
+		key = opts.Store.Key("knowledge", cfg.Name, "", fingerprint(cfg, opts, docs))
+		chunks, ok, err := loadChunks(ctx, opts.Store, key)
+		if err != nil {
+			return nil, fmt.Errorf("knowledge %s: load index: %w", cfg.Name, err)
+		}
+		if ok {
+			return newIndex(cfg, chunks, opts.Embed), nil
+		}
+	}
+
+	var chunks []Chunk
+	for _, doc := range docs {
+		n := 0
+		for _, part := range doc.text {
+			for _, text := range chunkText(part, cfg.ChunkSize, *cfg.ChunkOverlap) {
+				chunks = append(chunks, Chunk{Path: doc.path, Index: n, Text: text})
+				n++
+			}
+		}
+	}
+
+	if opts.Embed != nil {
+		if err := embedChunks(ctx, opts.Embed, chunks); err != nil {
+			return nil, fmt.Errorf("knowledge %s: %w", cfg.Name, err)
+		}
+	}
+
+	if opts.Store != nil {
+		data, err := json.Marshal(storedIndex{Version: indexVersion, Chunks: chunks})
+		if err != nil {
+			return nil, err
+		}
+		if err := opts.Store.Put(ctx, key, data); err != nil {
+			return nil, fmt.Errorf("knowledge %s: save index: %w", cfg.Name, err)
+		}
+	}
+	return newIndex(cfg, chunks, opts.Embed), nil
+}
+
+func newIndex(cfg Config, chunks []Chunk, embed EmbedFunc) *Index {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	return &Index{cfg: cfg, chunks: chunks, bm25: newBM25(texts), embed: embed}
+}
+
+// fingerprint вычисляет ключ индекса по настройкам нарезки, модели эмбеддингов и содержимому документов.
+func fingerprint(cfg Config, opts Options, docs []document) string {
+	h := sha256.New()
+	model := ""
+	if opts.Embed != nil {
+		model = opts.EmbeddingModel + "\x00embed"
+	}
+	fmt.Fprintf(h, "v%d\x00%d\x00%d\x00%s\x00%s\x00", indexVersion, cfg.ChunkSize, *cfg.ChunkOverlap, strings.Join(cfg.Include, ","), model)
+	for _, doc := range docs {
+		fmt.Fprintf(h, "%s\x00%d\x00", doc.path, len(doc.text))
+		for _, part := range doc.text {
+			fmt.Fprintf(h, "%d\x00%s", len(part), part)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func loadChunks(ctx context.Context, store aiwf.ArtifactStore, key string) ([]Chunk, bool, error) {
+	data, ok, err := store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	var stored storedIndex
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != indexVersion {
+		return nil, false, nil
+	}
+	return stored.Chunks, true, nil
+}
+
+func embedChunks(ctx context.Context, embed EmbedFunc, chunks []Chunk) error {
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(chunks))
+		texts := make([]string, 0, end-start)
+		for _, c := range chunks[start:end] {
+			texts = append(texts, c.Text)
+		}
+		vectors, err := embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("embed chunks: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embed chunks: got %d vectors for %d chunks", len(vectors), len(texts))
+		}
+		for i, v := range vectors {
+			chunks[start+i].Vector = v
+		}
+	}
+	return nil
+}
+
+// Name возвращает имя источника.
+func (idx *Index) Name() string {
+	return idx.cfg.Name
+}
+
+// Len возвращает число фрагментов в индексе.
+func (idx *Index) Len() int {
+	return len(idx.chunks)
+}
+
+// Retrieve возвращает TopK фрагментов, наиболее подходящих к запросу.
+func (idx *Index) Retrieve(ctx context.Context, query string) ([]aiwf.Citation, error) {
+	return idx.Search(ctx, query, idx.cfg.TopK)
+}
+
+// Search возвращает до k фрагментов в порядке убывания релевантности.
+func (idx *Index) Search(ctx context.Context, query string, k int) ([]aiwf.Citation, error) {
+	if len(idx.chunks) == 0 || k <= 0 {
+		return nil, nil
+	}
+
+	scores := idx.bm25.scores(query)
+	if idx.embed != nil && idx.hasVectors() {
+		vectors, err := idx.embed(ctx, []string{query})
+		if err != nil {
+			return nil, fmt.Errorf("knowledge %s: embed query: %w", idx.cfg.Name, err)
+		}
+		if len(vectors) != 1 {
+			return nil, errors.New("knowledge: embedding of query is missing")
+		}
+		scores = fuse(scores, idx.similarities(vectors[0]))
+	}
+
+	ranked := rank(scores)
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	citations := make([]aiwf.Citation, 0, len(ranked))
+	for _, doc := range ranked {
+		c := idx.chunks[doc]
+		citations = append(citations, aiwf.Citation{
+			Source: idx.cfg.Name,
+			Path:   c.Path,
+			Chunk:  c.Index,
+			Text:   c.Text,
+			Score:  scores[doc],
+		})
+	}
+	return citations, nil
+}
+
+func (idx *Index) hasVectors() bool {
+	for _, c := range idx.chunks {
+		if c.Vector == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// similarities возвращает косинусную близость запроса ко всем фрагментам.
+func (idx *Index) similarities(query []float32) map[int]float64 {
+	sims := make(map[int]float64, len(idx.chunks))
+	for i, c := range idx.chunks {
+		sims[i] = cosine(query, c.Vector)
+	}
+	return sims
+}
+
+// fuse объединяет два ранжирования через reciprocal rank fusion.
+func fuse(lexical, semantic map[int]float64) map[int]float64 {
+	fused := make(map[int]float64)
+	for _, scores := range []map[int]float64{lexical, semantic} {
+		for r, doc := range rank(scores) {
+			fused[doc] += 1 / float64(rrfK+r+1)
+		}
+	}
+	return fused
+}
+
+// rank сортирует фрагменты с положительной оценкой по убыванию; при равенстве — по порядку в индексе.
+func rank(scores map[int]float64) []int {
+	docs := make([]int, 0, len(scores))
+	for doc, s := range scores {
+		if s > 0 {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if scores[docs[i]] != scores[docs[j]] {
+			return scores[docs[i]] > scores[docs[j]]
+		}
+		return docs[i] < docs[j]
+	})
+	return docs
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
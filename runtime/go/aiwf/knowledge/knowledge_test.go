@@ -0,0 +1,164 @@
+package knowledge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/aiwf/runtime/go/aiwf"
+	"github.com/andranikuz/aiwf/runtime/go/aiwf/store"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestChunkText(t *testing.T) {
+	text := strings.Repeat("a", 30) + "\n\n" + strings.Repeat("b", 30) + "\n\n" + strings.Repeat("c", 70)
+	chunks := chunkText(text, 40, 5)
+
+	if len(chunks) != 5 {
+		t.Fatalf("expected 5 chunks, got %d: %q", len(chunks), chunks)
+	}
+	for _, c := range chunks {
+		if n := len([]rune(c)); n > 40 {
+			t.Fatalf("chunk longer than size: %d", n)
+		}
+	}
+	if !strings.HasPrefix(chunks[1], "aaaaa") {
+		t.Fatalf("expected overlap with previous chunk, got %q", chunks[1])
+	}
+	if got := chunkText("  \n\n ", 40, 5); len(got) != 0 {
+		t.Fatalf("expected no chunks for blank text, got %q", got)
+	}
+}
+
+func TestSearchBM25(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"billing.md":   "# Billing\n\nRefunds are issued within 14 days of a cancelled subscription.",
+		"shipping.txt": "Orders ship from the Berlin warehouse within two business days.",
+		"faq.json":     `[{"q": "How do I reset my password?", "a": "Use the reset link on the login page."}, {"q": "Do you ship abroad?", "a": "Yes, worldwide shipping is available."}]`,
+		"notes.csv":    "refunds,ignored",
+	})
+
+	idx, err := Build(context.Background(), Config{Name: "docs", Paths: []string{dir}, TopK: 2}, Options{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if idx.Len() != 4 {
+		t.Fatalf("expected 4 chunks (csv skipped, json array split), got %d", idx.Len())
+	}
+
+	citations, err := idx.Retrieve(context.Background(), "refunds for a cancelled subscription")
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(citations) == 0 || !strings.HasSuffix(citations[0].Path, "billing.md") || citations[0].Source != "docs" {
+		t.Fatalf("expected billing.md first, got %+v", citations)
+	}
+
+	citations, _ = idx.Retrieve(context.Background(), "password reset")
+	if len(citations) != 1 || citations[0].Chunk != 0 || !strings.Contains(citations[0].Text, "password") {
+		t.Fatalf("expected password FAQ entry, got %+v", citations)
+	}
+}
+
+func TestBuildUsesStore(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.md": "alpha beta", "b.md": "gamma delta"})
+	fs, err := store.NewFSStore(store.Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embedCalls := 0
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		embedCalls++
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			if strings.Contains(text, "gamma") || strings.Contains(text, "letters") {
+				vectors[i] = []float32{0, 1}
+			} else {
+				vectors[i] = []float32{1, 0}
+			}
+		}
+		return vectors, nil
+	}
+	cfg := Config{Name: "docs", Paths: []string{dir}, TopK: 1}
+	opts := Options{Store: fs, Embed: embed, EmbeddingModel: "embed-1"}
+
+	if _, err := Build(context.Background(), cfg, opts); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	idx, err := Build(context.Background(), cfg, opts)
+	if err != nil {
+		t.Fatalf("Build from store: %v", err)
+	}
+	if embedCalls != 1 {
+		t.Fatalf("expected chunks to be embedded once, got %d calls", embedCalls)
+	}
+
+	// Запрос без общих термов находится только по вектору
+	citations, err := idx.Retrieve(context.Background(), "greek letters")
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(citations) != 1 || !strings.HasSuffix(citations[0].Path, "b.md") {
+		t.Fatalf("expected semantic match b.md, got %+v", citations)
+	}
+}
+
+func TestBuildRequiresPaths(t *testing.T) {
+	if _, err := Build(context.Background(), Config{Name: "docs"}, Options{}); err == nil {
+		t.Fatal("expected error without paths")
+	}
+	if _, err := Build(context.Background(), Config{Name: "docs", Paths: []string{"/nonexistent/aiwf"}}, Options{}); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
+
+func TestBuildResolvesPathsAgainstDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"docs/refunds.md": "Refunds are issued within 14 days."})
+
+	idx, err := Build(context.Background(), Config{Name: "docs", Paths: []string{"./docs"}}, Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	citations, err := idx.Retrieve(context.Background(), "refunds")
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(citations) != 1 || citations[0].Path != "docs/refunds.md" {
+		t.Fatalf("expected path relative to dir, got %+v", citations)
+	}
+}
+
+func TestConfigChunkOverlap(t *testing.T) {
+	cfg, err := Config{Name: "docs", Paths: []string{"."}, ChunkSize: 500}.normalized()
+	if err != nil || *cfg.ChunkOverlap != DefaultChunkOverlap {
+		t.Fatalf("expected default overlap, got %v, %v", cfg.ChunkOverlap, err)
+	}
+	cfg, err = Config{Name: "docs", Paths: []string{"."}, ChunkSize: 50}.normalized()
+	if err != nil || *cfg.ChunkOverlap != 5 {
+		t.Fatalf("default overlap must fit a small chunk, got %v, %v", cfg.ChunkOverlap, err)
+	}
+	cfg, err = Config{Name: "docs", Paths: []string{"."}, ChunkOverlap: aiwf.Int(0)}.normalized()
+	if err != nil || *cfg.ChunkOverlap != 0 {
+		t.Fatalf("chunk_overlap: 0 must disable overlap, got %v, %v", cfg.ChunkOverlap, err)
+	}
+	if _, err := (Config{Name: "docs", Paths: []string{"."}, ChunkSize: 100, ChunkOverlap: aiwf.Int(100)}).normalized(); err == nil {
+		t.Fatal("expected error for overlap not less than chunk size")
+	}
+}
@@ -0,0 +1,66 @@
+package aiwf
+
+// CallOption переопределяет параметры отдельного вызова агента:
+//
+//	agent.Run(ctx, input, aiwf.WithModel("gpt-4o"), aiwf.WithTemperature(0))
+type CallOption func(*ModelCall)
+
+// WithModel задаёт модель вызова.
+func WithModel(model string) CallOption {
+	return func(c *ModelCall) { c.Model = model }
+}
+
+// WithMaxTokens задаёт лимит токенов ответа.
+func WithMaxTokens(n int) CallOption {
+	return func(c *ModelCall) { c.MaxTokens = n }
+}
+
+// WithTemperature задаёт температуру; 0 передаётся провайдеру как есть.
+func WithTemperature(t float64) CallOption {
+	return func(c *ModelCall) { c.Temperature = Float64(t) }
+}
+
+// WithTopP задаёт nucleus sampling.
+func WithTopP(p float64) CallOption {
+	return func(c *ModelCall) { c.TopP = Float64(p) }
+}
+
+// WithSeed задаёт seed для воспроизводимой генерации.
+func WithSeed(seed int) CallOption {
+	return func(c *ModelCall) { c.Seed = Int(seed) }
+}
+
+// WithStop задаёт стоп-последовательности.
+func WithStop(stop ...string) CallOption {
+	return func(c *ModelCall) { c.Stop = stop }
+}
+
+// WithPresencePenalty задаёт штраф за присутствие токена.
+func WithPresencePenalty(p float64) CallOption {
+	return func(c *ModelCall) { c.PresencePenalty = Float64(p) }
+}
+
+// WithFrequencyPenalty задаёт штраф за частоту токена.
+func WithFrequencyPenalty(p float64) CallOption {
+	return func(c *ModelCall) { c.FrequencyPenalty = Float64(p) }
+}
+
+// WithReasoningEffort задаёт усилие рассуждения (minimal, low, medium, high).
+func WithReasoningEffort(effort string) CallOption {
+	return func(c *ModelCall) { c.ReasoningEffort = effort }
+}
+
+// WithPromptCache включает или выключает кэширование промпта для вызова.
+func WithPromptCache(enabled bool) CallOption {
+	return func(c *ModelCall) { c.PromptCache = enabled }
+}
+
+// Float64 возвращает указатель на значение; удобно для полей AgentConfig.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// Int возвращает указатель на значение.
+func Int(v int) *int {
+	return &v
+}
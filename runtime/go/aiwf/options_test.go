@@ -0,0 +1,63 @@
+package aiwf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCallOptionsOverrideAgentConfig(t *testing.T) {
+	client := &scriptedClient{results: []*CallResult{{Data: []byte(`"ok"`), StopReason: StopReasonEnd}}}
+	agent := &AgentBase{
+		Config: AgentConfig{
+			Name:        "writer",
+			Model:       "gpt-4o",
+			MaxTokens:   100,
+			Temperature: Float64(0.7),
+			TopP:        Float64(0.9),
+		},
+		Client: client,
+	}
+
+	if _, _, err := agent.CallModel(context.Background(), "in", nil, WithModel("gpt-4o-mini"), WithTemperature(0), WithStop("END")); err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+
+	call := client.calls[0]
+	if call.Model != "gpt-4o-mini" || call.MaxTokens != 100 {
+		t.Fatalf("unexpected call: %+v", call)
+	}
+	if call.Temperature == nil || *call.Temperature != 0 {
+		t.Fatalf("expected temperature override to 0, got %v", call.Temperature)
+	}
+	if call.TopP == nil || *call.TopP != 0.9 || len(call.Stop) != 1 {
+		t.Fatalf("expected config params to be kept, got top_p=%v stop=%v", call.TopP, call.Stop)
+	}
+}
+
+func TestPromptCacheOptionAndHitRate(t *testing.T) {
+	client := &scriptedClient{results: []*CallResult{{
+		Data:       []byte(`"ok"`),
+		StopReason: StopReasonEnd,
+		Usage:      Tokens{Prompt: 1000, CachedPrompt: 750, Completion: 10, Total: 1010},
+	}}}
+	agent := &AgentBase{Config: AgentConfig{Name: "writer", Model: "gpt-4o", PromptCache: true}, Client: client}
+
+	_, trace, err := agent.CallModel(context.Background(), "in", nil)
+	if err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+	if !client.calls[0].PromptCache {
+		t.Fatal("expected prompt cache from agent config")
+	}
+	if rate := trace.CacheHitRate(); rate != 0.75 {
+		t.Fatalf("unexpected cache hit rate: %v", rate)
+	}
+
+	client.results = append(client.results, client.results[0])
+	if _, _, err := agent.CallModel(context.Background(), "in", nil, WithPromptCache(false)); err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+	if client.calls[1].PromptCache {
+		t.Fatal("expected WithPromptCache(false) to disable caching")
+	}
+}
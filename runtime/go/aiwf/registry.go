@@ -0,0 +1,176 @@
+package aiwf
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Capability описывает возможность провайдера, которую может требовать ассистент.
+type Capability string
+
+const (
+	CapabilitySchema      Capability = "schema"       // структурированный вывод по JSON Schema
+	CapabilityStreaming   Capability = "streaming"    // потоковая генерация
+	CapabilityTools       Capability = "tools"        // вызов инструментов
+	CapabilityThreads     Capability = "threads"      // продолжение диалога в треде
+	CapabilityPromptCache Capability = "prompt_cache" // кэширование префикса промпта
+	CapabilityEmbeddings  Capability = "embeddings"   // API эмбеддингов
+)
+
+// ErrProviderNotConfigured возвращается фабрикой, если в окружении нет нужных переменных.
+var ErrProviderNotConfigured = errors.New("aiwf: provider is not configured")
+
+// ProviderConfig — настройки провайдера из раздела providers: спецификации.
+// Пустые поля означают значения провайдера по умолчанию.
+type ProviderConfig struct {
+	BaseURL   string
+	APIKeyEnv string // переменная окружения с ключом вместо стандартной
+	Timeout   time.Duration
+	Headers   map[string]string
+	Org       string // организация (OpenAI-Organization)
+	Mode      string // API OpenAI-совместимого провайдера: responses или chat_completions
+
+	// Параметры Ollama
+	NumCtx      int    // размер контекстного окна; 0 — значение модели
+	KeepAlive   string // сколько модель остаётся в памяти, например 10m
+	PullMissing bool   // скачивать отсутствующую модель
+
+	// Параметры Azure OpenAI
+	Deployments map[string]string // модель → имя деплоймента
+
+	// Getenv читает переменные окружения; nil — os.Getenv.
+	Getenv func(string) string
+}
+
+// Env возвращает значение переменной окружения.
+func (c ProviderConfig) Env(key string) string {
+	if c.Getenv != nil {
+		return c.Getenv(key)
+	}
+	return os.Getenv(key)
+}
+
+// APIKey возвращает ключ из APIKeyEnv, а если она не задана — из defaultEnv.
+func (c ProviderConfig) APIKey(defaultEnv string) string {
+	if c.APIKeyEnv != "" {
+		return c.Env(c.APIKeyEnv)
+	}
+	return c.Env(defaultEnv)
+}
+
+// defaultProviderTimeout используется HTTPClient, если Timeout не задан.
+const defaultProviderTimeout = 60 * time.Second
+
+// HTTPClient возвращает HTTP-клиент, добавляющий Headers к каждому запросу.
+// Без заголовков возвращает nil, и провайдер использует собственный клиент.
+func (c ProviderConfig) HTTPClient() *http.Client {
+	if len(c.Headers) == 0 {
+		return nil
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultProviderTimeout
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &headerTransport{headers: c.Headers, base: http.DefaultTransport},
+	}
+}
+
+// headerTransport добавляет заголовки к запросам.
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// ProviderFactory создаёт клиента провайдера по настройкам.
+type ProviderFactory func(cfg ProviderConfig) (ModelClient, error)
+
+// ProviderInfo описывает провайдера, доступного через use: в YAML.
+type ProviderInfo struct {
+	Name         string       // значение use:
+	ImportPath   string       // Go-пакет, регистрирующий провайдера (импортируется сгенерированным кодом)
+	EnvKeys      []string     // переменные окружения, необходимые фабрике по умолчанию
+	Capabilities []Capability // поддерживаемые возможности
+	New          ProviderFactory
+
+	// IgnoredOptions возвращает параметры генерации (ключи YAML), которые провайдер
+	// не передаёт модели в режиме mode (ProviderConfig.Mode); nil — передаются все.
+	IgnoredOptions func(mode string) []string
+}
+
+// Supports сообщает, поддерживает ли провайдер возможность.
+func (p ProviderInfo) Supports(c Capability) bool {
+	for _, have := range p.Capabilities {
+		if have == c {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]ProviderInfo)
+)
+
+// RegisterProvider регистрирует провайдера. Обычно вызывается из init() пакета провайдера.
+// Паникует при пустом имени, отсутствии фабрики или повторной регистрации.
+func RegisterProvider(info ProviderInfo) {
+	if info.Name == "" {
+		panic("aiwf: RegisterProvider with empty name")
+	}
+	if info.New == nil {
+		panic(fmt.Sprintf("aiwf: RegisterProvider %q with nil factory", info.Name))
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if _, dup := providers[info.Name]; dup {
+		panic(fmt.Sprintf("aiwf: RegisterProvider called twice for %q", info.Name))
+	}
+	providers[info.Name] = info
+}
+
+// LookupProvider возвращает зарегистрированного провайдера по имени.
+func LookupProvider(name string) (ProviderInfo, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	info, ok := providers[name]
+	return info, ok
+}
+
+// Providers возвращает зарегистрированных провайдеров, отсортированных по имени.
+func Providers() []ProviderInfo {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	list := make([]ProviderInfo, 0, len(providers))
+	for _, info := range providers {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// NewProvider создаёт клиента зарегистрированного провайдера.
+func NewProvider(name string, cfg ProviderConfig) (ModelClient, error) {
+	info, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("aiwf: unknown provider %q", name)
+	}
+	return info.New(cfg)
+}
@@ -0,0 +1,77 @@
+package aiwf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	client := &scriptedClient{}
+	RegisterProvider(ProviderInfo{
+		Name:         "registry-test",
+		EnvKeys:      []string{"REGISTRY_TEST_KEY"},
+		Capabilities: []Capability{CapabilitySchema, CapabilityStreaming},
+		New: func(cfg ProviderConfig) (ModelClient, error) {
+			if cfg.APIKey("REGISTRY_TEST_KEY") == "" {
+				return nil, ErrProviderNotConfigured
+			}
+			return client, nil
+		},
+	})
+
+	info, ok := LookupProvider("registry-test")
+	if !ok {
+		t.Fatal("provider not registered")
+	}
+	if !info.Supports(CapabilityStreaming) || info.Supports(CapabilityThreads) {
+		t.Fatalf("unexpected capabilities: %v", info.Capabilities)
+	}
+
+	env := map[string]string{"CUSTOM_KEY": "key"}
+	getenv := func(key string) string { return env[key] }
+
+	if _, err := NewProvider("registry-test", ProviderConfig{Getenv: getenv}); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Fatalf("expected ErrProviderNotConfigured, got %v", err)
+	}
+	got, err := NewProvider("registry-test", ProviderConfig{APIKeyEnv: "CUSTOM_KEY", Getenv: getenv})
+	if err != nil || got != client {
+		t.Fatalf("NewProvider: %v %v", got, err)
+	}
+	if _, err := NewProvider("missing", ProviderConfig{}); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate registration")
+		}
+	}()
+	RegisterProvider(info)
+}
+
+func TestRouter(t *testing.T) {
+	first := &scriptedClient{results: []*CallResult{{Data: []byte(`"first"`)}, {Data: []byte(`"first"`)}}}
+	second := &scriptedClient{results: []*CallResult{{Data: []byte(`"second"`)}}}
+	router := NewRouter().Route("eu-openai", first).Route("grok", second)
+
+	for provider, want := range map[string]string{"grok": `"second"`, "eu-openai": `"first"`, "": `"first"`} {
+		res, err := router.Call(context.Background(), ModelCall{Provider: provider})
+		if err != nil {
+			t.Fatalf("Call(%q): %v", provider, err)
+		}
+		if string(res.Data) != want {
+			t.Fatalf("Call(%q) routed to %s, want %s", provider, res.Data, want)
+		}
+	}
+
+	// Незаданный провайдер не подменяется первым клиентом
+	_, err := router.Call(context.Background(), ModelCall{Provider: "anthropic"})
+	if !errors.Is(err, ErrProviderNotConfigured) || !strings.Contains(err.Error(), `"anthropic"`) {
+		t.Fatalf("expected not configured error naming the provider, got %v", err)
+	}
+	if _, err := NewRouter().Call(context.Background(), ModelCall{}); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Fatalf("expected error from empty router, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package aiwf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Citation — фрагмент знаний, подставленный в промпт.
+type Citation struct {
+	Source string  `json:"source"` // имя раздела knowledge:
+	Path   string  `json:"path"`   // файл-источник
+	Chunk  int     `json:"chunk"`  // номер фрагмента в файле
+	Text   string  `json:"text"`
+	Score  float64 `json:"score"`
+}
+
+// Retriever ищет фрагменты знаний, относящиеся к запросу.
+type Retriever interface {
+	Retrieve(ctx context.Context, query string) ([]Citation, error)
+}
+
+// retrieve собирает фрагменты из всех источников агента в порядке источников.
+func (a *AgentBase) retrieve(ctx context.Context, call ModelCall) ([]Citation, error) {
+	query, err := UserContent(call)
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return nil, nil
+	}
+
+	var citations []Citation
+	for _, r := range a.Knowledge {
+		found, err := r.Retrieve(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		citations = append(citations, found...)
+	}
+	return citations, nil
+}
+
+// knowledgePrompt дописывает найденные фрагменты перед пользовательским промптом.
+func knowledgePrompt(citations []Citation, userPrompt string) string {
+	var b strings.Builder
+	b.WriteString("Use the following context when it is relevant. Refer to sources by their number.\n")
+	for i, c := range citations {
+		fmt.Fprintf(&b, "\n[%d] %s\n%s\n", i+1, c.Path, strings.TrimSpace(c.Text))
+	}
+	if userPrompt != "" {
+		b.WriteString("\n")
+		b.WriteString(userPrompt)
+	}
+	return b.String()
+}
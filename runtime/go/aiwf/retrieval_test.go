@@ -0,0 +1,48 @@
+package aiwf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type staticRetriever struct {
+	citations []Citation
+	queries   []string
+}
+
+func (r *staticRetriever) Retrieve(ctx context.Context, query string) ([]Citation, error) {
+	r.queries = append(r.queries, query)
+	return r.citations, nil
+}
+
+func TestCallModelInjectsKnowledge(t *testing.T) {
+	client := &scriptedClient{results: []*CallResult{{Data: []byte(`"ok"`), StopReason: StopReasonEnd}}}
+	retriever := &staticRetriever{citations: []Citation{
+		{Source: "docs", Path: "docs/billing.md", Text: "Refunds take 14 days.", Score: 2.5},
+	}}
+	agent := &AgentBase{
+		Config:    AgentConfig{Name: "support", SystemPrompt: "Be helpful."},
+		Client:    client,
+		Knowledge: []Retriever{retriever},
+	}
+
+	_, trace, err := agent.CallModel(context.Background(), map[string]string{"question": "refund time?"}, nil)
+	if err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+
+	if len(retriever.queries) != 1 || !strings.Contains(retriever.queries[0], "refund time?") {
+		t.Fatalf("expected payload as query, got %q", retriever.queries)
+	}
+	call := client.calls[0]
+	if call.SystemPrompt != "Be helpful." {
+		t.Fatalf("system prompt must stay unchanged, got %q", call.SystemPrompt)
+	}
+	if !strings.Contains(call.UserPrompt, "[1] docs/billing.md\nRefunds take 14 days.") {
+		t.Fatalf("expected numbered citation in user prompt, got %q", call.UserPrompt)
+	}
+	if len(trace.Citations) != 1 || trace.Citations[0].Path != "docs/billing.md" {
+		t.Fatalf("expected citations in trace, got %+v", trace.Citations)
+	}
+}
@@ -0,0 +1,106 @@
+package aiwf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Router — ModelClient, направляющий вызовы провайдерам по ModelCall.Provider.
+// Вызов без провайдера уходит первому добавленному клиенту; вызов провайдера,
+// для которого нет клиента (например, не задан ключ), возвращает ошибку.
+type Router struct {
+	clients  map[string]ModelClient
+	fallback ModelClient
+}
+
+// NewRouter создаёт пустой маршрутизатор.
+func NewRouter() *Router {
+	return &Router{clients: make(map[string]ModelClient)}
+}
+
+// Route регистрирует клиента под именем провайдера.
+func (r *Router) Route(name string, client ModelClient) *Router {
+	r.clients[name] = client
+	if r.fallback == nil {
+		r.fallback = client
+	}
+	return r
+}
+
+// Len возвращает число зарегистрированных клиентов.
+func (r *Router) Len() int {
+	return len(r.clients)
+}
+
+func (r *Router) client(call ModelCall) (ModelClient, error) {
+	if call.Provider != "" {
+		client, ok := r.clients[call.Provider]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrProviderNotConfigured, call.Provider)
+		}
+		return client, nil
+	}
+	if r.fallback == nil {
+		return nil, fmt.Errorf("%w: no providers", ErrProviderNotConfigured)
+	}
+	return r.fallback, nil
+}
+
+// CallJSONSchema делегирует вызов провайдеру.
+func (r *Router) CallJSONSchema(ctx context.Context, call ModelCall) ([]byte, Tokens, error) {
+	client, err := r.client(call)
+	if err != nil {
+		return nil, Tokens{}, err
+	}
+	return client.CallJSONSchema(ctx, call)
+}
+
+// CallJSONSchemaStream делегирует потоковый вызов провайдеру.
+func (r *Router) CallJSONSchemaStream(ctx context.Context, call ModelCall) (<-chan StreamChunk, Tokens, error) {
+	client, err := r.client(call)
+	if err != nil {
+		return nil, Tokens{}, err
+	}
+	return client.CallJSONSchemaStream(ctx, call)
+}
+
+// Call делегирует вызов провайдеру, сохраняя причину остановки, если провайдер её сообщает.
+func (r *Router) Call(ctx context.Context, call ModelCall) (*CallResult, error) {
+	client, err := r.client(call)
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := client.(ResultClient); ok {
+		return rc.Call(ctx, call)
+	}
+	data, usage, err := client.CallJSONSchema(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	return &CallResult{Data: data, Usage: usage}, nil
+}
+
+// RecordTurn делегирует сохранение хода провайдеру, если он ведёт историю треда.
+func (r *Router) RecordTurn(ctx context.Context, call ModelCall, result *CallResult) error {
+	client, err := r.client(call)
+	if err != nil {
+		return err
+	}
+	if tr, ok := client.(TurnRecorder); ok {
+		return tr.RecordTurn(ctx, call, result)
+	}
+	return nil
+}
+
+// Embed делегирует запрос эмбеддингов провайдеру, если он их поддерживает.
+func (r *Router) Embed(ctx context.Context, call EmbeddingCall) (*EmbeddingResult, error) {
+	client, err := r.client(ModelCall{Provider: call.Provider})
+	if err != nil {
+		return nil, err
+	}
+	ec, ok := client.(EmbeddingClient)
+	if !ok {
+		return nil, ErrEmbeddingsNotSupported
+	}
+	return ec.Embed(ctx, call)
+}
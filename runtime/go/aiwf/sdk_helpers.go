@@ -4,24 +4,39 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // AgentConfig содержит конфигурацию агента
 type AgentConfig struct {
 	Name           string
+	Provider       string // имя провайдера для Router (запись providers: или use:)
 	Model          string
 	SystemPrompt   string
 	InputTypeName  string
 	OutputTypeName string
 	MaxTokens      int
-	Temperature    float64
+	Truncation     *TruncationPolicy // поведение при обрезке ответа; nil — политика по умолчанию
+
+	// Параметры генерации; nil — значение провайдера по умолчанию
+	Temperature      *float64
+	TopP             *float64
+	Seed             *int
+	Stop             []string
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	ReasoningEffort  string
+
+	PromptCache bool // кэширование стабильного префикса запроса
+	Dimensions  int  // размерность эмбеддингов для агентов kind: embedding
 }
 
 // AgentBase базовая реализация агента
 type AgentBase struct {
-	Config AgentConfig
-	Client ModelClient
-	Types  TypeProvider
+	Config    AgentConfig
+	Client    ModelClient
+	Types     TypeProvider
+	Knowledge []Retriever // источники знаний, фрагменты которых подставляются в промпт
 }
 
 // Name возвращает имя агента
@@ -39,8 +54,8 @@ func (a *AgentBase) SystemPrompt() string {
 	return a.Config.SystemPrompt
 }
 
-// CallModel вызывает модель с типизированными данными
-func (a *AgentBase) CallModel(ctx context.Context, input any, thread *ThreadState) (json.RawMessage, *Trace, error) {
+// CallModel вызывает модель с типизированными данными; opts переопределяют параметры вызова
+func (a *AgentBase) CallModel(ctx context.Context, input any, thread *ThreadState, opts ...CallOption) (json.RawMessage, *Trace, error) {
 	// Получаем метаданные типов если есть TypeProvider
 	var typeMetadata any
 
@@ -60,14 +75,40 @@ func (a *AgentBase) CallModel(ctx context.Context, input any, thread *ThreadStat
 	}
 
 	call := ModelCall{
+		Provider:       a.Config.Provider,
 		Model:          a.Config.Model,
 		SystemPrompt:   a.Config.SystemPrompt,
 		Payload:        input,
 		MaxTokens:      a.Config.MaxTokens,
-		Temperature:    a.Config.Temperature,
 		InputTypeName:  a.Config.InputTypeName,
 		OutputTypeName: a.Config.OutputTypeName,
 		TypeMetadata:   typeMetadata,
+
+		Temperature:      a.Config.Temperature,
+		TopP:             a.Config.TopP,
+		Seed:             a.Config.Seed,
+		Stop:             a.Config.Stop,
+		PresencePenalty:  a.Config.PresencePenalty,
+		FrequencyPenalty: a.Config.FrequencyPenalty,
+		ReasoningEffort:  a.Config.ReasoningEffort,
+		PromptCache:      a.Config.PromptCache,
+	}
+	for _, opt := range opts {
+		opt(&call)
+	}
+
+	// Подставляем найденные фрагменты знаний; системный промпт не меняется,
+	// чтобы не ломать кэширование префикса
+	var citations []Citation
+	if len(a.Knowledge) > 0 {
+		found, err := a.retrieve(ctx, call)
+		if err != nil {
+			return nil, nil, fmt.Errorf("knowledge retrieval failed: %w", err)
+		}
+		if len(found) > 0 {
+			citations = found
+			call.UserPrompt = knowledgePrompt(citations, call.UserPrompt)
+		}
 	}
 
 	// Добавляем информацию о треде если есть
@@ -77,18 +118,37 @@ func (a *AgentBase) CallModel(ctx context.Context, input any, thread *ThreadStat
 	}
 
 	// Вызываем модель
-	result, tokens, err := a.Client.CallJSONSchema(ctx, call)
+	start := time.Now()
+	trace := &Trace{StepName: a.Config.Name, Citations: citations}
+
+	result, err := invokeModel(ctx, a.Client, call, CallKindInitial, trace)
 	if err != nil {
 		return nil, nil, fmt.Errorf("model call failed: %w", err)
 	}
 
-	trace := &Trace{
-		StepName: a.Config.Name,
-		Usage:    tokens,
-		Attempts: 1,
+	// Ответ обрезан по max_tokens: строку продолжаем, JSON повторяем с большим лимитом
+	if result.StopReason == StopReasonMaxTokens {
+		policy := DefaultTruncationPolicy()
+		if a.Config.Truncation != nil {
+			policy = a.Config.Truncation.normalized()
+		}
+
+		if a.Config.OutputTypeName == "" || a.Config.OutputTypeName == "string" {
+			result, err = continueText(ctx, a.Client, call, result, policy, trace)
+			if err == nil {
+				err = recordContinuedTurn(ctx, a.Client, call, result)
+			}
+		} else {
+			result, err = retryWithHigherLimit(ctx, a.Client, call, result, policy, trace)
+		}
+		if err != nil {
+			trace.Duration = time.Since(start)
+			return nil, trace, fmt.Errorf("model call failed: %w", err)
+		}
 	}
+	trace.Duration = time.Since(start)
 
-	return result, trace, nil
+	return result.Data, trace, nil
 }
 
 // WorkflowContext контекст выполнения воркфлоу
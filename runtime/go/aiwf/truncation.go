@@ -0,0 +1,190 @@
+package aiwf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrOutputTruncated возвращается, если структурированный ответ остался обрезанным
+// даже после повторов с максимально допустимым лимитом токенов.
+var ErrOutputTruncated = errors.New("aiwf: model output truncated by max_tokens")
+
+const (
+	defaultMaxContinuations = 3
+	defaultMaxTokensCap     = 16000
+	defaultMaxTokens        = 2000
+
+	continuePrompt = "Continue exactly where you stopped. Do not repeat what you already wrote."
+)
+
+// TruncationPolicy задаёт поведение рантайма при обрезке ответа по max_tokens.
+type TruncationPolicy struct {
+	// MaxContinuations ограничивает число продолжений для строковых выходов.
+	MaxContinuations int
+	// MaxTokensCap — потолок лимита токенов при повторах для JSON-выходов.
+	MaxTokensCap int
+}
+
+// DefaultTruncationPolicy возвращает политику по умолчанию.
+func DefaultTruncationPolicy() TruncationPolicy {
+	return TruncationPolicy{
+		MaxContinuations: defaultMaxContinuations,
+		MaxTokensCap:     defaultMaxTokensCap,
+	}
+}
+
+func (p TruncationPolicy) normalized() TruncationPolicy {
+	if p.MaxContinuations < 0 {
+		p.MaxContinuations = 0
+	}
+	if p.MaxTokensCap <= 0 {
+		p.MaxTokensCap = defaultMaxTokensCap
+	}
+	return p
+}
+
+// invokeModel выполняет один вызов и фиксирует его в трейсе.
+func invokeModel(ctx context.Context, client ModelClient, call ModelCall, kind CallKind, trace *Trace) (*CallResult, error) {
+	start := time.Now()
+
+	var result *CallResult
+	if rc, ok := client.(ResultClient); ok {
+		res, err := rc.Call(ctx, call)
+		if err != nil {
+			return nil, err
+		}
+		result = res
+	} else {
+		data, tokens, err := client.CallJSONSchema(ctx, call)
+		if err != nil {
+			return nil, err
+		}
+		result = &CallResult{Data: data, Usage: tokens}
+	}
+
+	trace.Calls = append(trace.Calls, CallTrace{
+		Attempt:    len(trace.Calls) + 1,
+		Kind:       kind,
+		MaxTokens:  call.MaxTokens,
+		StopReason: result.StopReason,
+		Usage:      result.Usage,
+		Duration:   time.Since(start),
+		RequestID:  result.RequestID,
+		Model:      result.Model,
+	})
+	trace.Attempts = len(trace.Calls)
+	trace.StopReason = result.StopReason
+	trace.Usage = addTokens(trace.Usage, result.Usage)
+
+	return result, nil
+}
+
+// continueText дозапрашивает обрезанный строковый ответ, склеивая части.
+func continueText(ctx context.Context, client ModelClient, call ModelCall, first *CallResult, policy TruncationPolicy, trace *Trace) (*CallResult, error) {
+	userText, err := UserContent(call)
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	text.Write(first.Data)
+	last := first
+
+	for i := 0; i < policy.MaxContinuations && last.StopReason == StopReasonMaxTokens; i++ {
+		next := call
+		next.History = append(append([]Message(nil), call.History...),
+			Message{Role: "user", Content: userText},
+			Message{Role: "assistant", Content: text.String()},
+		)
+		next.Payload = nil
+		next.UserPrompt = continuePrompt
+
+		res, err := invokeModel(ctx, client, next, CallKindContinue, trace)
+		if err != nil {
+			return nil, err
+		}
+		text.Write(res.Data)
+		last = res
+	}
+
+	return &CallResult{
+		Data:          []byte(text.String()),
+		Usage:         trace.Usage,
+		StopReason:    last.StopReason,
+		RawStopReason: last.RawStopReason,
+		RequestID:     last.RequestID,
+		Model:         last.Model,
+	}, nil
+}
+
+// recordContinuedTurn сохраняет в тред исходный запрос и склеенный ответ,
+// если клиент ведёт историю сам.
+func recordContinuedTurn(ctx context.Context, client ModelClient, call ModelCall, result *CallResult) error {
+	tr, ok := client.(TurnRecorder)
+	if !ok || call.ThreadID == "" {
+		return nil
+	}
+	return tr.RecordTurn(ctx, call, result)
+}
+
+// retryWithHigherLimit повторяет JSON-вызов, удваивая лимит токенов до потолка.
+func retryWithHigherLimit(ctx context.Context, client ModelClient, call ModelCall, first *CallResult, policy TruncationPolicy, trace *Trace) (*CallResult, error) {
+	last := first
+	maxTokens := call.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	for last.StopReason == StopReasonMaxTokens {
+		if maxTokens >= policy.MaxTokensCap {
+			return nil, fmt.Errorf("%w (limit %d reached)", ErrOutputTruncated, maxTokens)
+		}
+		maxTokens *= 2
+		if maxTokens > policy.MaxTokensCap {
+			maxTokens = policy.MaxTokensCap
+		}
+
+		next := call
+		next.MaxTokens = maxTokens
+		res, err := invokeModel(ctx, client, next, CallKindRetry, trace)
+		if err != nil {
+			return nil, err
+		}
+		last = res
+	}
+
+	return last, nil
+}
+
+// UserContent собирает текст пользовательского сообщения: промпт (вместе с
+// найденными фрагментами знаний) и JSON входных данных через пустую строку.
+// Провайдеры используют его, чтобы не терять ни одну из частей.
+func UserContent(call ModelCall) (string, error) {
+	var parts []string
+	if call.UserPrompt != "" {
+		parts = append(parts, call.UserPrompt)
+	}
+	if call.Payload != nil {
+		data, err := json.Marshal(call.Payload)
+		if err != nil {
+			return "", fmt.Errorf("marshal payload: %w", err)
+		}
+		parts = append(parts, string(data))
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n\n")), nil
+}
+
+func addTokens(a, b Tokens) Tokens {
+	return Tokens{
+		Prompt:       a.Prompt + b.Prompt,
+		Completion:   a.Completion + b.Completion,
+		Total:        a.Total + b.Total,
+		CachedPrompt: a.CachedPrompt + b.CachedPrompt,
+		CacheWrite:   a.CacheWrite + b.CacheWrite,
+		Reasoning:    a.Reasoning + b.Reasoning,
+	}
+}
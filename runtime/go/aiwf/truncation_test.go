@@ -0,0 +1,152 @@
+package aiwf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type scriptedClient struct {
+	results []*CallResult
+	calls   []ModelCall
+}
+
+func (c *scriptedClient) CallJSONSchema(ctx context.Context, call ModelCall) ([]byte, Tokens, error) {
+	res, err := c.Call(ctx, call)
+	if err != nil {
+		return nil, Tokens{}, err
+	}
+	return res.Data, res.Usage, nil
+}
+
+func (c *scriptedClient) CallJSONSchemaStream(ctx context.Context, call ModelCall) (<-chan StreamChunk, Tokens, error) {
+	return nil, Tokens{}, errors.New("not implemented")
+}
+
+func (c *scriptedClient) Call(ctx context.Context, call ModelCall) (*CallResult, error) {
+	c.calls = append(c.calls, call)
+	if len(c.calls) > len(c.results) {
+		return nil, errors.New("unexpected call")
+	}
+	return c.results[len(c.calls)-1], nil
+}
+
+func TestCallModelContinuesTruncatedText(t *testing.T) {
+	client := &scriptedClient{results: []*CallResult{
+		{Data: []byte("Hello, "), StopReason: StopReasonMaxTokens, Usage: Tokens{Prompt: 5, Completion: 2, Total: 7}},
+		{Data: []byte("world!"), StopReason: StopReasonEnd, Usage: Tokens{Prompt: 9, Completion: 2, Total: 11}},
+	}}
+	agent := &AgentBase{
+		Config: AgentConfig{Name: "writer", OutputTypeName: "string", MaxTokens: 2},
+		Client: client,
+	}
+
+	out, trace, err := agent.CallModel(context.Background(), "greet", nil)
+	if err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+	if string(out) != "Hello, world!" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if trace.Attempts != 2 || len(trace.Calls) != 2 {
+		t.Fatalf("expected 2 calls in trace, got %+v", trace)
+	}
+	if trace.Calls[1].Kind != CallKindContinue {
+		t.Fatalf("expected continuation call, got %s", trace.Calls[1].Kind)
+	}
+	if trace.StopReason != StopReasonEnd {
+		t.Fatalf("unexpected stop reason: %s", trace.StopReason)
+	}
+	if trace.Usage.Total != 18 {
+		t.Fatalf("expected summed usage, got %+v", trace.Usage)
+	}
+
+	history := client.calls[1].History
+	if len(history) != 2 || history[0].Content != `"greet"` || history[1].Content != "Hello, " {
+		t.Fatalf("unexpected continuation history: %+v", history)
+	}
+	if client.calls[1].Payload != nil {
+		t.Fatalf("continuation must not resend payload")
+	}
+}
+
+type recordingClient struct {
+	scriptedClient
+	turns    []ModelCall
+	recorded []*CallResult
+}
+
+func (c *recordingClient) RecordTurn(ctx context.Context, call ModelCall, result *CallResult) error {
+	c.turns = append(c.turns, call)
+	c.recorded = append(c.recorded, result)
+	return nil
+}
+
+func TestCallModelRecordsContinuedTurn(t *testing.T) {
+	client := &recordingClient{scriptedClient: scriptedClient{results: []*CallResult{
+		{Data: []byte("Hello, "), StopReason: StopReasonMaxTokens},
+		{Data: []byte("world!"), StopReason: StopReasonEnd},
+	}}}
+	agent := &AgentBase{
+		Config: AgentConfig{Name: "writer", OutputTypeName: "string", MaxTokens: 2},
+		Client: NewRouter().Route("fake", client),
+	}
+
+	if _, _, err := agent.CallModel(context.Background(), "greet", &ThreadState{ID: "t1"}); err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+	if len(client.recorded) != 1 || string(client.recorded[0].Data) != "Hello, world!" {
+		t.Fatalf("expected assembled turn to be recorded once, got %+v", client.recorded)
+	}
+	if call := client.turns[0]; call.ThreadID != "t1" || len(call.History) != 0 || call.Payload != "greet" {
+		t.Fatalf("turn must be recorded with the original call, got %+v", call)
+	}
+}
+
+func TestCallModelRetriesTruncatedJSON(t *testing.T) {
+	client := &scriptedClient{results: []*CallResult{
+		{Data: []byte(`{"a":`), StopReason: StopReasonMaxTokens},
+		{Data: []byte(`{"a":1,`), StopReason: StopReasonMaxTokens},
+		{Data: []byte(`{"a":1,"b":2}`), StopReason: StopReasonEnd},
+	}}
+	agent := &AgentBase{
+		Config: AgentConfig{Name: "extractor", OutputTypeName: "Result", MaxTokens: 100},
+		Client: client,
+	}
+
+	out, trace, err := agent.CallModel(context.Background(), map[string]string{"q": "x"}, nil)
+	if err != nil {
+		t.Fatalf("CallModel: %v", err)
+	}
+	if string(out) != `{"a":1,"b":2}` {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if got := []int{client.calls[0].MaxTokens, client.calls[1].MaxTokens, client.calls[2].MaxTokens}; got[1] != 200 || got[2] != 400 {
+		t.Fatalf("expected doubling max tokens, got %v", got)
+	}
+	if trace.Calls[2].Kind != CallKindRetry {
+		t.Fatalf("expected retry call, got %s", trace.Calls[2].Kind)
+	}
+}
+
+func TestCallModelTruncatedJSONHitsCap(t *testing.T) {
+	truncated := &CallResult{Data: []byte(`{`), StopReason: StopReasonMaxTokens}
+	client := &scriptedClient{results: []*CallResult{truncated, truncated, truncated}}
+	agent := &AgentBase{
+		Config: AgentConfig{
+			Name:           "extractor",
+			OutputTypeName: "Result",
+			MaxTokens:      100,
+			Truncation:     &TruncationPolicy{MaxTokensCap: 400},
+		},
+		Client: client,
+	}
+
+	_, trace, err := agent.CallModel(context.Background(), nil, nil)
+	if !errors.Is(err, ErrOutputTruncated) {
+		t.Fatalf("expected ErrOutputTruncated, got %v", err)
+	}
+	if trace == nil || len(trace.Calls) != 3 {
+		t.Fatalf("expected trace with 3 calls, got %+v", trace)
+	}
+}
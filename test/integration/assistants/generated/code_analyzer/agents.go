@@ -31,7 +31,7 @@ func NewCodeAnalyzerAgent(client aiwf.ModelClient) *CodeAnalyzerAgent {
 				InputTypeName:  "CodeAnalysisRequest",
 				OutputTypeName: "AnalysisResult",
 				MaxTokens:      2000,
-				Temperature:    0.7,
+				Temperature:    aiwf.Float64(0.7),
 			},
 			Client: client,
 		},
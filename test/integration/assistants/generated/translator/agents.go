@@ -31,7 +31,7 @@ func NewTranslatorAgent(client aiwf.ModelClient) *TranslatorAgent {
 				InputTypeName:  "TranslationRequest",
 				OutputTypeName: "TranslationResult",
 				MaxTokens:      2000,
-				Temperature:    0.7,
+				Temperature:    aiwf.Float64(0.7),
 			},
 			Client: client,
 		},
@@ -32,7 +32,7 @@ func NewSupportBotAgent(client aiwf.ModelClient) *SupportBotAgent {
 				InputTypeName:  "SupportMessage",
 				OutputTypeName: "SupportResponse",
 				MaxTokens:      2000,
-				Temperature:    0.7,
+				Temperature:    aiwf.Float64(0.7),
 			},
 			Client: client,
 		},
@@ -31,7 +31,7 @@ func NewDataExtractorAgent(client aiwf.ModelClient) *DataExtractorAgent {
 				InputTypeName:  "UserRequest",
 				OutputTypeName: "ExtractedData",
 				MaxTokens:      2000,
-				Temperature:    0.7,
+				Temperature:    aiwf.Float64(0.7),
 			},
 			Client: client,
 		},